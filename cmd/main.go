@@ -12,9 +12,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	leader "github.com/denizumutdereli/stream-admin/internal/etcd"
+	"github.com/denizumutdereli/stream-admin/internal/factory"
+	administratorAuthRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
+	administratorLogsRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/logs"
 	"github.com/denizumutdereli/stream-admin/internal/router"
+	administratorLogsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
 	"github.com/denizumutdereli/stream-admin/internal/setup"
 	"github.com/denizumutdereli/stream-admin/internal/validation"
 	"github.com/gin-gonic/gin"
@@ -31,6 +36,9 @@ var (
 	port        string
 	grpcport    string
 	wsport      string
+
+	rotateSigningKey  bool
+	restoreLogArchive string
 )
 
 func main() {
@@ -49,9 +57,21 @@ func main() {
 	flag.StringVar(&port, "port", config.GoServicePort, "Port of the service")
 	flag.StringVar(&grpcport, "grpcport", config.GoGrpcPort, "GRPC Port of the service")
 	flag.StringVar(&wsport, "wsport", config.WsServerPort, "Port of the websocket server")
+	flag.BoolVar(&rotateSigningKey, "rotate-signing-key", false, "Activate a new access token signing key and exit")
+	flag.StringVar(&restoreLogArchive, "restore-log-archive", "", "Attach the admin logs archive of the given manifest and exit")
 
 	flag.Parse()
 
+	if rotateSigningKey {
+		rotateAccessTokenSigningKey(config)
+		return
+	}
+
+	if restoreLogArchive != "" {
+		restoreAdminLogArchive(config, restoreLogArchive)
+		return
+	}
+
 	if serviceName == "" {
 		logger.Fatal("Please provide a service name",
 			zap.Strings("AllowedServices", config.AllowedServices))
@@ -199,3 +219,55 @@ func main() {
 
 	logger.Info("Server exiting")
 }
+
+// rotateAccessTokenSigningKey activates a new signing key. Running instances
+// pick it up on their next key reload and keep accepting the previous key.
+func rotateAccessTokenSigningKey(config *config.Config) {
+	logger := config.Logger
+
+	database, err := factory.NewDatabaseFactory(config, logger).CreateCitusDB()
+	if err != nil {
+		logger.Fatal("Error connecting to the database", zap.Error(err))
+	}
+
+	keyRing, err := administratorAuthRepo.NewSigningKeyRing(context.Background(), database, config)
+	if err != nil {
+		logger.Fatal("Error loading the signing keys", zap.Error(err))
+	}
+
+	key, err := keyRing.Rotate()
+	if err != nil {
+		logger.Fatal("Error rotating the signing key", zap.Error(err))
+	}
+
+	logger.Info("Signing key rotated", zap.String("kid", key.KeyID), zap.String("alg", key.Algorithm))
+}
+
+// restoreAdminLogArchive attaches an archived month of admin logs again, the
+// manifest and its archive are expected in the same directory.
+func restoreAdminLogArchive(config *config.Config, manifestPath string) {
+	logger := config.Logger
+
+	servicePrefix, exists := config.PrefixService.GetServicePrefix("admin-action-monitoring")
+	if !exists {
+		logger.Fatal("No prefix found for service:", zap.String("serviceName", "admin-action-monitoring"))
+	}
+
+	database, err := factory.NewDatabaseFactory(config, logger).CreateCitusDB()
+	if err != nil {
+		logger.Fatal("Error connecting to the database", zap.Error(err))
+	}
+
+	repo, err := administratorLogsRepo.NewGORMAdminLogs(database, servicePrefix, config, builders.NewBuilder(config))
+	if err != nil {
+		logger.Fatal("Error loading the admin logs repository", zap.Error(err))
+	}
+
+	partition, manifest, err := administratorLogsService.NewLogRetention(repo, config).Restore(manifestPath)
+	if err != nil {
+		logger.Fatal("Error restoring the admin logs archive", zap.String("partition", partition), zap.Error(err))
+	}
+
+	logger.Info("Admin logs archive restored", zap.String("partition", partition), zap.Int64("rows", manifest.Rows),
+		zap.Time("from", manifest.From), zap.Time("to", manifest.To))
+}
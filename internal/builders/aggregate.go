@@ -0,0 +1,107 @@
+package builders
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+const (
+	AggregateGroupByQueryKey = "group_by"
+	AggregateMetricsQueryKey = "metrics"
+)
+
+var (
+	aggregateMetricPattern = regexp.MustCompile(`^([a-zA-Z]+)\(\s*([a-zA-Z_][a-zA-Z0-9_]*|\*)?\s*\)$`)
+
+	aggregateFuncs   = map[string]bool{"count": true, "sum": true, "avg": true, "min": true, "max": true}
+	aggregateBuckets = map[string]bool{"hour": true, "day": true}
+)
+
+// ParseGroupBy reads group_by as a comma separated list of column[:hour|day],
+// e.g. market,created_at:day. Columns are checked against the model by the
+// repository.
+func ParseGroupBy(groupBy string) ([]types.AggregateGroup, error) {
+	var groups []types.AggregateGroup
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(groupBy, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		column, bucket, _ := strings.Cut(part, ":")
+		column = strings.TrimSpace(column)
+		bucket = strings.ToLower(strings.TrimSpace(bucket))
+
+		if !sortColumnPattern.MatchString(column) {
+			return nil, fmt.Errorf("invalid group_by field '%s'", column)
+		}
+		if bucket != "" && !aggregateBuckets[bucket] {
+			return nil, fmt.Errorf("invalid bucket '%s' for field '%s', expected hour or day", bucket, column)
+		}
+
+		group := types.AggregateGroup{Column: column, Bucket: bucket}
+		if seen[group.Alias()] {
+			return nil, fmt.Errorf("group_by field '%s' is given more than once", part)
+		}
+		seen[group.Alias()] = true
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// ParseMetrics reads metrics as a comma separated list of count or
+// func(column) with func one of count, sum, avg, min, max. No metrics means a
+// plain row count.
+func ParseMetrics(metrics string) ([]types.AggregateMetric, error) {
+	var parsed []types.AggregateMetric
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(metrics, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		metric := types.AggregateMetric{Func: strings.ToLower(part)}
+		if metric.Func != "count" {
+			match := aggregateMetricPattern.FindStringSubmatch(part)
+			if match == nil {
+				return nil, fmt.Errorf("invalid metric '%s', expected func(field)", part)
+			}
+			metric = types.AggregateMetric{Func: strings.ToLower(match[1]), Column: match[2]}
+		}
+
+		if !aggregateFuncs[metric.Func] {
+			return nil, fmt.Errorf("unknown metric function '%s'", metric.Func)
+		}
+		if metric.Column == "*" {
+			if metric.Func != "count" {
+				return nil, fmt.Errorf("metric '%s' requires a field", part)
+			}
+			metric.Column = ""
+		}
+		if metric.Column == "" && metric.Func != "count" {
+			return nil, fmt.Errorf("metric '%s' requires a field", part)
+		}
+
+		if seen[metric.Alias()] {
+			return nil, fmt.Errorf("metric '%s' is given more than once", part)
+		}
+		seen[metric.Alias()] = true
+
+		parsed = append(parsed, metric)
+	}
+
+	if len(parsed) == 0 {
+		parsed = append(parsed, types.AggregateMetric{Func: "count"})
+	}
+
+	return parsed, nil
+}
@@ -2,29 +2,43 @@ package builders
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
+	"github.com/denizumutdereli/stream-admin/internal/export"
+	"github.com/denizumutdereli/stream-admin/internal/masking"
 	"github.com/denizumutdereli/stream-admin/internal/types"
-	"github.com/denizumutdereli/stream-admin/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator"
 )
 
+const (
+	ExportFormatQueryKey = "format"
+	ExportFieldsQueryKey = "fields"
+)
+
+var ErrMaskedFilter = errors.New("dsl_search filters on masked fields")
+
 type HandleBinding interface {
 	BindAndValidate() *handleBinding
 	BindQuery() *handleBinding
 	BindJson() *handleBinding
 	BindDSL() *handleBinding
 	BindPagination(paginationParams *types.PaginationParams) *handleBinding
+	BindExport(exportParams *types.ExportParams) *handleBinding
+	BindAggregate(aggregateParams *types.AggregateParams) *handleBinding
 	Validate() *handleBinding
 	GetError() error
-	GetErrorMessages() map[string]string
+	GetErrorMessages() map[string]interface{}
 }
 
 type handleBinding struct {
+	config           *config.Config
 	Context          *gin.Context
 	Error            error
-	ErrorMessages    map[string]string
+	ErrorMessages    map[string]interface{}
 	Modal            interface{}
 	DslQ             *[]types.QueryCondition
 	PaginationParams types.PaginationParams
@@ -32,7 +46,7 @@ type handleBinding struct {
 }
 
 func (b *builderService) NewHandleBinding(c *gin.Context, paramater interface{}, dDslQ *[]types.QueryCondition) HandleBinding {
-	return &handleBinding{Context: c, Modal: paramater, DslQ: dDslQ}
+	return &handleBinding{config: b.config, Context: c, Modal: paramater, DslQ: dDslQ}
 }
 
 func (b *handleBinding) BindAndValidate() *handleBinding {
@@ -76,12 +90,12 @@ func (b *handleBinding) BindJson() *handleBinding {
 func (b *handleBinding) BindDSL() *handleBinding {
 
 	if b.Error == nil {
-		dsl_search := b.Context.Query("dsl_search")
+		dsl_search := b.withSavedSearch(b.Context.Query("dsl_search"))
 		if dsl_search != "" {
 			dslQuery, parseErr := ParseDSLSearch(dsl_search, b.Modal)
 			if parseErr != nil {
-				b.Error = errors.New(parseErr.Error())
-			} else {
+				b.setDSLError(parseErr)
+			} else if !b.filtersMaskedFields(dslQuery) {
 				*b.DslQ = dslQuery
 			}
 		} else {
@@ -98,8 +112,8 @@ func (b *handleBinding) BindDSLField(field string) *handleBinding {
 		if dsl_search != "" {
 			dslQuery, parseErr := ParseDSLSearch(dsl_search, b.Modal)
 			if parseErr != nil {
-				b.Error = errors.New(parseErr.Error())
-			} else {
+				b.setDSLError(parseErr)
+			} else if !b.filtersMaskedFields(dslQuery) {
 				*b.DslQ = dslQuery
 			}
 		} else {
@@ -125,6 +139,90 @@ func (b *handleBinding) BindPagination(paginationParams *types.PaginationParams)
 	return b
 }
 
+// BindExport reads ?format and ?fields for a streamed export and applies the
+// configured row cap. Requested fields must be search fields of the model; the
+// id key is always allowed as exports iterate on it.
+func (b *handleBinding) BindExport(exportParams *types.ExportParams) *handleBinding {
+	if b.Error != nil {
+		return b
+	}
+
+	format := b.Context.Query(ExportFormatQueryKey)
+	if format == "" {
+		return b
+	}
+
+	parsedFormat, err := export.ParseFormat(format)
+	if err != nil {
+		b.ErrorMessages = map[string]interface{}{ExportFormatQueryKey: err.Error()}
+		b.Error = err
+		return b
+	}
+
+	exportParams.Format = string(parsedFormat)
+
+	if fields := b.Context.Query(ExportFieldsQueryKey); fields != "" {
+		searchFields := searchFieldTypes(b.Modal)
+		details := make(map[string]interface{})
+
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if _, ok := searchFields[field]; !ok && field != "id" {
+				details[field] = "unknown export field"
+				continue
+			}
+			exportParams.Fields = append(exportParams.Fields, field)
+		}
+
+		if len(details) > 0 {
+			b.ErrorMessages = details
+			b.Error = errors.New("fields contains unknown columns")
+			return b
+		}
+	}
+
+	if b.config != nil && b.config.ExportRules != nil {
+		exportParams.MaxRows = b.config.ExportRules.MaxRows
+		exportParams.BatchSize = b.config.ExportRules.BatchSize
+	}
+
+	return b
+}
+
+// BindAggregate reads ?group_by and ?metrics and applies the configured group
+// cap. Fields are checked against the model by the repository.
+func (b *handleBinding) BindAggregate(aggregateParams *types.AggregateParams) *handleBinding {
+	if b.Error != nil {
+		return b
+	}
+
+	groupBy, err := ParseGroupBy(b.Context.Query(AggregateGroupByQueryKey))
+	if err != nil {
+		b.ErrorMessages = map[string]interface{}{AggregateGroupByQueryKey: err.Error()}
+		b.Error = err
+		return b
+	}
+
+	metrics, err := ParseMetrics(b.Context.Query(AggregateMetricsQueryKey))
+	if err != nil {
+		b.ErrorMessages = map[string]interface{}{AggregateMetricsQueryKey: err.Error()}
+		b.Error = err
+		return b
+	}
+
+	aggregateParams.GroupBy = groupBy
+	aggregateParams.Metrics = metrics
+
+	if b.config != nil && b.config.AggregateRules != nil {
+		aggregateParams.MaxGroups = b.config.AggregateRules.MaxGroups
+	}
+
+	return b
+}
+
 func (b *handleBinding) Validate() *handleBinding {
 	if b.Error == nil {
 		validate := validator.New()
@@ -132,7 +230,7 @@ func (b *handleBinding) Validate() *handleBinding {
 
 		if err != nil {
 			if validationErrors, ok := err.(validator.ValidationErrors); ok {
-				b.ErrorMessages = make(map[string]string)
+				b.ErrorMessages = make(map[string]interface{})
 				for _, errField := range validationErrors {
 					b.ErrorMessages[errField.Field()] = errField.Translate(nil)
 				}
@@ -146,31 +244,64 @@ func (b *handleBinding) Validate() *handleBinding {
 	return b
 }
 
-func (b *handleBinding) GetError() error {
-	return b.Error
+// withSavedSearch narrows a saved search resolved by the saved search
+// middleware with the ad-hoc dsl_search of the request, if any.
+func (b *handleBinding) withSavedSearch(dslSearch string) string {
+	saved := b.Context.GetString(string(types.ContextSavedSearchKey))
+	if saved == "" {
+		return dslSearch
+	}
+
+	if dslSearch == "" {
+		return saved
+	}
+
+	return "(" + saved + ") and (" + dslSearch + ")"
 }
 
-func (b *handleBinding) GetErrorMessages() map[string]string {
-	return b.ErrorMessages
+// filtersMaskedFields rejects conditions on fields masked by the policy of the
+// request, matching them value by value would reveal what the mask hides.
+func (b *handleBinding) filtersMaskedFields(conditions []types.QueryCondition) bool {
+	details := masking.FromContext(b.Context, nil).HiddenDetails(ConditionFields(conditions)...)
+	if details == nil {
+		return false
+	}
+
+	b.ErrorMessages = make(map[string]interface{}, len(details))
+	for field, message := range details {
+		b.ErrorMessages[field] = message
+	}
+	b.Error = ErrMaskedFilter
+	return true
 }
 
-func ParseDSLSearch(dsl string, targetStruct interface{}) ([]types.QueryCondition, error) {
-	var queryConditions []types.QueryCondition
+func (b *handleBinding) setDSLError(err error) {
+	var validationErr *DSLValidationError
+	if errors.As(err, &validationErr) {
+		b.ErrorMessages = make(map[string]interface{}, len(validationErr.Details))
+		for field, messages := range validationErr.Details {
+			b.ErrorMessages[field] = messages
+		}
+	}
+	b.Error = err
+}
 
-	conditions := utils.CleanInput(dsl)
+func (b *handleBinding) GetError() error {
+	return b.Error
+}
 
-	for _, condition := range conditions {
-		parts := strings.Split(condition, "|")
+func (b *handleBinding) GetErrorMessages() map[string]interface{} {
+	return b.ErrorMessages
+}
 
-		if len(parts) != 3 {
-			return nil, errors.New("invalid condition format")
-		}
+func ParseDSLSearch(dslSearch string, targetStruct interface{}) ([]types.QueryCondition, error) {
+	queryConditions, err := dsl.ParseConditions(dslSearch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsl_search: %w", err)
+	}
 
-		queryConditions = append(queryConditions, types.QueryCondition{
-			Field:    strings.TrimSpace(parts[0]),
-			Operator: strings.TrimSpace(parts[1]),
-			Value:    strings.TrimSpace(parts[2]),
-		})
+	if err := ValidateDSLConditions(queryConditions, targetStruct); err != nil {
+		return nil, err
 	}
 
 	return queryConditions, nil
@@ -0,0 +1,267 @@
+package builders
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	nullTimeType  = reflect.TypeOf(sql.NullTime{})
+	dslFieldsType = reflect.TypeOf(dsl.DSLFields{})
+)
+
+// DSLValidationError lists every error of each field, a field can be used by
+// several conditions.
+type DSLValidationError struct {
+	Details map[string][]string
+}
+
+func (e *DSLValidationError) Error() string {
+	return "dsl_search contains unknown fields or invalid values"
+}
+
+// ValidateDSLConditions checks every condition field against the form tags of
+// the search struct and coerces its value to the field type.
+func ValidateDSLConditions(conditions []types.QueryCondition, targetStruct interface{}) error {
+	fields := searchFieldTypes(targetStruct)
+	if fields == nil {
+		return nil
+	}
+
+	details := make(map[string][]string)
+	validateConditions(conditions, fields, details)
+
+	if len(details) > 0 {
+		return &DSLValidationError{Details: details}
+	}
+
+	return nil
+}
+
+// ConditionFields returns the fields the conditions filter on, groups
+// included.
+func ConditionFields(conditions []types.QueryCondition) []string {
+	var fields []string
+	for _, condition := range conditions {
+		if len(condition.Group) > 0 {
+			fields = append(fields, ConditionFields(condition.Group)...)
+			continue
+		}
+		fields = append(fields, condition.Field)
+	}
+	return fields
+}
+
+func validateConditions(conditions []types.QueryCondition, fields map[string]reflect.Type, details map[string][]string) {
+	for i := range conditions {
+		condition := &conditions[i]
+
+		if len(condition.Group) > 0 {
+			validateConditions(condition.Group, fields, details)
+			continue
+		}
+
+		fieldType, ok := fields[condition.Field]
+		if !ok {
+			details[condition.Field] = append(details[condition.Field], "unknown field")
+			continue
+		}
+
+		if !dsl.IsKnownOperator(condition.Operator) {
+			details[condition.Field] = append(details[condition.Field], fmt.Sprintf("unknown operator '%s'", condition.Operator))
+			continue
+		}
+
+		if err := coerceCondition(condition, fieldType); err != nil {
+			details[condition.Field] = append(details[condition.Field], err.Error())
+		}
+	}
+}
+
+func coerceCondition(condition *types.QueryCondition, fieldType reflect.Type) error {
+	operator := condition.Operator
+
+	switch {
+	case dsl.IsNullOperator(operator):
+		return nil
+	case dsl.IsListOperator(operator), dsl.IsRangeOperator(operator):
+		if len(condition.Values) == 0 {
+			return fmt.Errorf("operator '%s' requires a list of values", operator)
+		}
+		if dsl.IsRangeOperator(operator) && len(condition.Values) != 2 {
+			return fmt.Errorf("operator '%s' requires exactly two values", operator)
+		}
+
+		typedValues := make([]interface{}, len(condition.Values))
+		for i, value := range condition.Values {
+			typedValue, err := coerceValue("=", value, fieldType)
+			if err != nil {
+				return err
+			}
+			if typedValue == nil {
+				typedValue = value
+			}
+			typedValues[i] = typedValue
+		}
+		condition.TypedValue = typedValues
+		return nil
+	case dsl.IsRelativeTimeOperator(operator):
+		bound, err := dsl.RelativeTime(operator, condition.Value, time.Now())
+		if err != nil {
+			return err
+		}
+		typedValue, err := timeBoundForField(bound, fieldType)
+		if err != nil {
+			return fmt.Errorf("operator '%s' %s", operator, err.Error())
+		}
+		condition.TypedValue = typedValue
+		return nil
+	}
+
+	typedValue, err := coerceValue(operator, condition.Value, fieldType)
+	if err != nil {
+		return err
+	}
+
+	condition.TypedValue = typedValue
+	return nil
+}
+
+// timeBoundForField matches the bound to how the column stores time: unix
+// seconds for bigint columns, timestamps for time fields, RFC3339 for text.
+func timeBoundForField(bound time.Time, fieldType reflect.Type) (interface{}, error) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return bound.Unix(), nil
+	case reflect.String:
+		return bound.UTC().Format(time.RFC3339), nil
+	case reflect.Struct:
+		if fieldType == timeType || fieldType.ConvertibleTo(nullTimeType) {
+			return bound, nil
+		}
+	}
+
+	return nil, fmt.Errorf("requires a time or unix timestamp field, got %s", fieldType.String())
+}
+
+func coerceValue(operator, value string, fieldType reflect.Type) (interface{}, error) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	dataType := fieldType.String()
+
+	if dsl.IsTextOperator(operator) {
+		if fieldType.Kind() != reflect.String {
+			return nil, fmt.Errorf("operator '%s' requires a text field, got %s", operator, dataType)
+		}
+		return nil, nil
+	}
+
+	if enum, ok := reflect.New(fieldType).Elem().Interface().(dsl.Enum); ok {
+		for _, allowed := range enum.EnumValues() {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid value '%s' for %s, allowed: %s", value, dataType, strings.Join(enum.EnumValues(), ", "))
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return nil, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for %s", value, dataType)
+		}
+		return parsed, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for %s", value, dataType)
+		}
+		return parsed, nil
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for %s", value, dataType)
+		}
+		return parsed, nil
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for %s", value, dataType)
+		}
+		return parsed, nil
+	case reflect.Struct:
+		if fieldType == timeType || fieldType.ConvertibleTo(nullTimeType) {
+			parsed, err := parseTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value '%s' for %s, expected RFC3339 or 2006-01-02", value, dataType)
+			}
+			return parsed, nil
+		}
+	}
+
+	return nil, fmt.Errorf("field type %s is not searchable", dataType)
+}
+
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Parse("2006-01-02", value)
+	}
+	return t, nil
+}
+
+func searchFieldTypes(targetStruct interface{}) map[string]reflect.Type {
+	if targetStruct == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(targetStruct)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Type)
+	collectSearchFields(t, fields)
+
+	return fields
+}
+
+func collectSearchFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if field.Type != dslFieldsType {
+				collectSearchFields(field.Type, fields)
+			}
+			continue
+		}
+
+		formTag := strings.Split(field.Tag.Get("form"), ",")[0]
+		if formTag == "" || formTag == "-" {
+			continue
+		}
+		fields[formTag] = field.Type
+	}
+}
@@ -0,0 +1,181 @@
+package builders
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+type testStatus string
+
+func (testStatus) EnumValues() []string {
+	return []string{"open", "closed"}
+}
+
+type testSearch struct {
+	dsl.DSLFields
+	Name      *string     `form:"name"`
+	Amount    *int64      `form:"amount"`
+	Price     *float64    `form:"price"`
+	Active    *bool       `form:"active"`
+	Status    *testStatus `form:"status"`
+	CreatedAt *int64      `form:"created_at"`
+	UpdatedAt *time.Time  `form:"updated_at"`
+}
+
+func TestValidateDSLConditionsCoercion(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition types.QueryCondition
+		want      interface{}
+	}{
+		{
+			name:      "integer",
+			condition: types.QueryCondition{Field: "amount", Operator: ">=", Value: "42"},
+			want:      int64(42),
+		},
+		{
+			name:      "float",
+			condition: types.QueryCondition{Field: "price", Operator: "<", Value: "9.5"},
+			want:      9.5,
+		},
+		{
+			name:      "bool",
+			condition: types.QueryCondition{Field: "active", Operator: "=", Value: "true"},
+			want:      true,
+		},
+		{
+			name:      "enum",
+			condition: types.QueryCondition{Field: "status", Operator: "=", Value: "open"},
+			want:      "open",
+		},
+		{
+			name:      "text keeps the raw value",
+			condition: types.QueryCondition{Field: "name", Operator: "contains", Value: "jane"},
+			want:      nil,
+		},
+		{
+			name:      "date",
+			condition: types.QueryCondition{Field: "updated_at", Operator: ">", Value: "2024-01-02"},
+			want:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "list",
+			condition: types.QueryCondition{Field: "amount", Operator: "in", Values: []string{"1", "2"}},
+			want:      []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:      "range",
+			condition: types.QueryCondition{Field: "price", Operator: "between", Values: []string{"1", "2.5"}},
+			want:      []interface{}{1.0, 2.5},
+		},
+		{
+			name:      "null check takes no value",
+			condition: types.QueryCondition{Field: "amount", Operator: "isnull"},
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions := []types.QueryCondition{tt.condition}
+			if err := ValidateDSLConditions(conditions, &testSearch{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(conditions[0].TypedValue, tt.want) {
+				t.Errorf("TypedValue = %#v, want %#v", conditions[0].TypedValue, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDSLConditionsRelativeTime(t *testing.T) {
+	conditions := []types.QueryCondition{{Field: "created_at", Operator: "last", Value: "7d"}}
+	if err := ValidateDSLConditions(conditions, &testSearch{}); err != nil {
+		t.Fatal(err)
+	}
+
+	bound, ok := conditions[0].TypedValue.(int64)
+	want := time.Now().Add(-7 * 24 * time.Hour).Unix()
+	if !ok || bound < want-5 || bound > want+5 {
+		t.Errorf("TypedValue = %#v, want about %d", conditions[0].TypedValue, want)
+	}
+}
+
+func TestValidateDSLConditionsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []types.QueryCondition
+		want       map[string][]string
+	}{
+		{
+			name: "every error of a field is kept",
+			conditions: []types.QueryCondition{
+				{Field: "amount", Operator: ">", Value: "ten", Logic: "or"},
+				{Field: "amount", Operator: "<", Value: "twenty"},
+			},
+			want: map[string][]string{"amount": {
+				"invalid value 'ten' for int64",
+				"invalid value 'twenty' for int64",
+			}},
+		},
+		{
+			name:       "unknown field",
+			conditions: []types.QueryCondition{{Field: "password", Operator: "=", Value: "x"}},
+			want:       map[string][]string{"password": {"unknown field"}},
+		},
+		{
+			name:       "unknown operator",
+			conditions: []types.QueryCondition{{Field: "amount", Operator: "like", Value: "1"}},
+			want:       map[string][]string{"amount": {"unknown operator 'like'"}},
+		},
+		{
+			name: "errors inside groups",
+			conditions: []types.QueryCondition{
+				{Field: "name", Operator: "=", Value: "jane"},
+				{Group: []types.QueryCondition{{Field: "active", Operator: "=", Value: "maybe"}}},
+			},
+			want: map[string][]string{"active": {"invalid value 'maybe' for bool"}},
+		},
+		{
+			name:       "text operator on a number",
+			conditions: []types.QueryCondition{{Field: "amount", Operator: "contains", Value: "1"}},
+			want:       map[string][]string{"amount": {"operator 'contains' requires a text field, got int64"}},
+		},
+		{
+			name:       "range with one value",
+			conditions: []types.QueryCondition{{Field: "price", Operator: "between", Values: []string{"1"}}},
+			want:       map[string][]string{"price": {"operator 'between' requires exactly two values"}},
+		},
+		{
+			name:       "value outside the enum",
+			conditions: []types.QueryCondition{{Field: "status", Operator: "=", Value: "pending"}},
+			want:       map[string][]string{"status": {"invalid value 'pending' for builders.testStatus, allowed: open, closed"}},
+		},
+		{
+			name:       "relative time on a bool field",
+			conditions: []types.QueryCondition{{Field: "active", Operator: "last", Value: "7d"}},
+			want:       map[string][]string{"active": {"operator 'last' requires a time or unix timestamp field, got bool"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDSLConditions(tt.conditions, &testSearch{})
+
+			var validationErr *DSLValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidateDSLConditions() = %v, want a DSLValidationError", err)
+			}
+
+			if !reflect.DeepEqual(validationErr.Details, tt.want) {
+				t.Errorf("Details = %v, want %v", validationErr.Details, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package builders
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+var sortColumnPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseSort reads sortBy as a comma separated list of column[:asc|desc], e.g.
+// created_at:desc,id:asc. Columns without a direction fall back to sortOrder.
+// Only the syntax is checked here; repositories check the columns against
+// their models.
+func ParseSort(sortBy, sortOrder string) ([]types.SortField, error) {
+	defaultOrder := strings.ToLower(strings.TrimSpace(sortOrder))
+	if defaultOrder == "" {
+		defaultOrder = "asc"
+	}
+	if defaultOrder != "asc" && defaultOrder != "desc" {
+		return nil, fmt.Errorf("invalid sortOrder '%s', expected asc or desc", sortOrder)
+	}
+
+	var sort []types.SortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(sortBy, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		column, order, hasOrder := strings.Cut(part, ":")
+		column = strings.TrimSpace(column)
+		order = strings.ToLower(strings.TrimSpace(order))
+		if !hasOrder {
+			order = defaultOrder
+		}
+
+		if !sortColumnPattern.MatchString(column) {
+			return nil, fmt.Errorf("invalid sort field '%s'", column)
+		}
+		if order != "asc" && order != "desc" {
+			return nil, fmt.Errorf("invalid sort order '%s' for field '%s', expected asc or desc", order, column)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("sort field '%s' is given more than once", column)
+		}
+		seen[column] = true
+
+		sort = append(sort, types.SortField{Column: column, Order: order})
+	}
+
+	return sort, nil
+}
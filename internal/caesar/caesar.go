@@ -23,6 +23,9 @@ type CaesarManager interface {
 	StoreOTP(phone, otp string, expiry time.Time) error
 	RetrieveOTP(phone string) (string, error)
 	Generate2FACaesar() (string, error)
+	ValidateTOTP(secret, code string, at time.Time, skew int) (int64, bool)
+	TOTPProvisioningURI(issuer, account, secret string) string
+	GenerateRecoveryCodes(count int) ([]string, error)
 }
 
 type caesarManager struct {
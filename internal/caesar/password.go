@@ -0,0 +1,56 @@
+package caesar
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const (
+	passwordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordLower   = "abcdefghijkmnopqrstuvwxyz"
+	passwordDigits  = "23456789"
+	passwordSymbols = "!@#$%^&*-_=+?"
+)
+
+// GeneratePassword returns a random password with at least one character of
+// every class, so it passes any strength policy of the given length.
+func GeneratePassword(length int) (string, error) {
+	classes := []string{passwordUpper, passwordLower, passwordDigits, passwordSymbols}
+	if length < len(classes) {
+		length = len(classes)
+	}
+
+	all := passwordUpper + passwordLower + passwordDigits + passwordSymbols
+	password := make([]byte, length)
+	for i := range password {
+		charset := all
+		if i < len(classes) {
+			charset = classes[i]
+		}
+
+		char, err := randomChar(charset)
+		if err != nil {
+			return "", err
+		}
+		password[i] = char
+	}
+
+	// move the guaranteed characters away from the front
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomChar(charset string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+	if err != nil {
+		return 0, err
+	}
+	return charset[n.Int64()], nil
+}
@@ -0,0 +1,11 @@
+package caesar
+
+import (
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCodePNG renders the content as a QR code PNG of about the given width,
+// with error correction level M which authenticator apps read reliably.
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
@@ -0,0 +1,57 @@
+package caesar
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of RFC 6238 appendix B, "12345678901234567890"
+// in base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes, 6 digit codes are their last 6 digits.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateTOTP(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		code, err := GenerateTOTP(rfc6238Secret, TOTPCounter(time.Unix(vector.unix, 0)))
+		if err != nil {
+			t.Fatalf("GenerateTOTP at %d: %v", vector.unix, err)
+		}
+		if code != vector.code {
+			t.Errorf("GenerateTOTP at %d = %s, want %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	manager := &caesarManager{}
+	at := time.Unix(1111111111, 0)
+
+	counter, ok := manager.ValidateTOTP(rfc6238Secret, "050471", at, 1)
+	if !ok || counter != TOTPCounter(at) {
+		t.Fatalf("ValidateTOTP of the current step = %d, %v", counter, ok)
+	}
+
+	if _, ok := manager.ValidateTOTP(rfc6238Secret, "050471", at.Add(TOTPPeriod*time.Second), 1); !ok {
+		t.Error("ValidateTOTP refused a code of the previous step within skew")
+	}
+
+	if _, ok := manager.ValidateTOTP(rfc6238Secret, "050471", at.Add(2*TOTPPeriod*time.Second), 1); ok {
+		t.Error("ValidateTOTP accepted a code outside of the skew")
+	}
+
+	if _, ok := manager.ValidateTOTP(rfc6238Secret, "05047", at, 1); ok {
+		t.Error("ValidateTOTP accepted a short code")
+	}
+}
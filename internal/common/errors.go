@@ -1,6 +1,7 @@
 package common
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -73,3 +74,14 @@ func (e *appError) Unwrap() error {
 func (e *appError) InternalError() error {
 	return e.internalError
 }
+
+// StatusOf returns the status code of the first error in err's chain that
+// carries one, so typed repository errors keep their status through the
+// service layer. fallback is used otherwise.
+func StatusOf(err error, fallback int) int {
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode()
+	}
+	return fallback
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
 
 	"github.com/RackSec/srslog"
 	"github.com/denizumutdereli/stream-admin/internal/database"
@@ -27,6 +29,94 @@ type PolicyRules struct {
 	RolesPoliciesMin     int `mapstructure:"roles_policies_min"`
 	RolesPoliciesMax     int `mapstructure:"roles_policies_max"`
 	DashboardIdleTimeout int `mapstructure:"dashboard_id_timeout_in_minutes"`
+	IdleWarning          int `mapstructure:"idle_warning_in_minutes"` // warning lead before the idle sign-out
+	RulesCacheTTL        int `mapstructure:"rules_cache_ttl_in_seconds"`
+}
+
+type ExportRules struct {
+	MaxRows   int `mapstructure:"max_rows"`
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+type AggregateRules struct {
+	MaxGroups int `mapstructure:"max_groups"`
+}
+
+type MaskingRules struct {
+	MaskChar      string `mapstructure:"mask_char"`
+	VisibleSuffix int    `mapstructure:"visible_suffix"`
+	MinLength     int    `mapstructure:"min_length_for_suffix"`
+}
+
+type TOTPRules struct {
+	Issuer              string `mapstructure:"issuer"`
+	SkewSteps           int    `mapstructure:"skew_steps"`
+	RecoveryCodes       int    `mapstructure:"recovery_codes"`
+	QRCodeSize          int    `mapstructure:"qr_code_size_in_pixels"`
+	EnrollmentInMinutes int    `mapstructure:"enrollment_in_minutes"`
+}
+
+type JWTRules struct {
+	Algorithm            string `mapstructure:"algorithm"`
+	PreviousKeyInMinutes int    `mapstructure:"previous_key_in_minutes"`
+	ReloadInSeconds      int    `mapstructure:"reload_in_seconds"`
+}
+
+type RateLimitRules struct {
+	PeriodInSeconds int            `mapstructure:"period_in_seconds"`
+	Burst           int            `mapstructure:"burst"`
+	Groups          map[string]int `mapstructure:"groups"`
+}
+
+type AllowlistRules struct {
+	ReloadInSeconds     int    `mapstructure:"reload_in_seconds"`
+	InvalidationSubject string `mapstructure:"invalidation_subject"`
+}
+
+type AuditRules struct {
+	NodeID                  string   `mapstructure:"node_id"`
+	AnchorIntervalInMinutes int      `mapstructure:"anchor_interval_in_minutes"`
+	DigestFile              string   `mapstructure:"digest_file"`
+	DigestSigningKey        string   `mapstructure:"digest_signing_key"`
+	VerifyBatchSize         int      `mapstructure:"verify_batch_size"`
+	MaxBodySize             int      `mapstructure:"max_body_size"`
+	RedactFields            []string `mapstructure:"redact_fields"`
+	SkipBodyRoutes          []string `mapstructure:"skip_body_routes"` // routes whose body is never logged
+}
+
+type LogRetentionRules struct {
+	RetentionInMonths      int    `mapstructure:"retention_in_months"`
+	PremakeMonths          int    `mapstructure:"premake_months"`
+	ArchiveDir             string `mapstructure:"archive_dir"`
+	CheckIntervalInMinutes int    `mapstructure:"check_interval_in_minutes"`
+	BatchSize              int    `mapstructure:"batch_size"`
+}
+
+type PasswordRules struct {
+	MinLength           int    `mapstructure:"min_length"`
+	RequireUpper        bool   `mapstructure:"require_upper"`
+	RequireLower        bool   `mapstructure:"require_lower"`
+	RequireDigit        bool   `mapstructure:"require_digit"`
+	RequireSymbol       bool   `mapstructure:"require_symbol"`
+	HistorySize         int    `mapstructure:"history_size"`
+	ResetTokenInMinutes int    `mapstructure:"reset_token_in_minutes"`
+	ResetURL            string `mapstructure:"reset_url"`
+}
+
+type NotifierConfig struct {
+	Provider     string `mapstructure:"provider"`
+	From         string `mapstructure:"from"`
+	SMTPHost     string `mapstructure:"smtp_host"`
+	SMTPPort     int    `mapstructure:"smtp_port"`
+	SMTPUsername string `mapstructure:"smtp_username"`
+	SMTPPassword string `mapstructure:"smtp_password" json:"-"`
+	LogFile      string `mapstructure:"log_file"`
+}
+
+type ApprovalRules struct {
+	ExpiryInMinutes int `mapstructure:"expiry_in_minutes"`
+	MaxBodySize     int `mapstructure:"max_body_size_in_bytes"`
+	MaxResultSize   int `mapstructure:"max_result_size_in_bytes"`
 }
 
 type Config struct {
@@ -38,6 +128,7 @@ type Config struct {
 	EtcdUrl                         string             `mapstructure:"ETCD_URL" validate:"required" json:"-"`
 	SysLog                          string             `mapstructure:"SYSLOG" validate:"required" json:"-"`
 	Https                           string             `mapstructure:"HTTPS" validate:"required" json:"-"`
+	Environment                     string             `mapstructure:"ENVIRONMENT" validate:"required"`
 	CorsWhitelist                   string             `mapstructure:"CORS_WHITELIST" validate:"required" json:"-"`
 	Channels                        []string           `mapstructure:"CHANNELS"`
 	AdminActionLogTopics            []string           `mapstructure:"ADMIN_ACTION_LOG_TOPICS"`
@@ -49,6 +140,8 @@ type Config struct {
 	AllowedRestHeaders              []string           `mapstructure:"ALLOWED_REST_HEADERS" validate:"required"`
 	AllowedSpecificIps              []string           `mapstructure:"ALLOWED_SPECIFIC_IPS" validate:"required"`
 	AllowedIpRanges                 []string           `mapstructure:"ALLOWED_IP_RANGES" validate:"required"`
+	TrustedProxies                  []string           `mapstructure:"TRUSTED_PROXIES"`
+	ForwardedHeader                 string             `mapstructure:"FORWARDED_HEADER" validate:"oneof=forwarded xff"`
 	KafkaBrokers                    []string           `mapstructure:"KAFKA_BROKERS" validate:"required" json:"-"`
 	KafkaConsumerGroup              string             `mapstructure:"KAFKA_CONSUMER_GROUP" validate:"required" json:"-"`
 	KafkaConsumeTopics              []string           `mapstructure:"KAFKA_CONSUME_TOPICS" validate:"required" json:"-"`
@@ -61,9 +154,12 @@ type Config struct {
 	MarkupApi                       string             `mapstructure:"MARKUP_API"`
 	OTPServiceApi                   string             `mapstructure:"OTP_SERVICE_API"`
 	OTPServiceKey                   string             `mapstructure:"OTP_SERVICE_KEY" json:"-"`
+	OTPProvider                     string             `mapstructure:"OTP_PROVIDER"`
+	OTPTestSeed                     string             `mapstructure:"OTP_TEST_SEED" json:"-"`
 	OTPCodesInMinutes               int                `mapstructure:"OTP_CODES_IN_MINUTES"`
 	OTPCodesMaxTry                  int                `mapstructure:"OTP_CODES_MAX_TRY"`
 	OtpCodesMaxTryInARowInMinutes   int                `mapstructure:"OTP_CODES_MAX_TRY_IN_A_ROW_IN_MINUTES"`
+	OTPStepUpTokenInSeconds         int                `mapstructure:"OTP_STEP_UP_TOKEN_IN_SECONDS"`
 	DefaultPanelLockPeriodInMinutes int                `mapstructure:"DEFAULT_PANEL_LOCK_PERIOD_IN_MINUTES"`
 	DefaultCacheQueryTimeInSeconds  int                `mapstructure:"DEFAULT_CACHE_QUERY_TIME_IN_SECONDS"`
 	DefaultFuncsTimeOutInSeconds    int                `mapstructure:"DEFAULT_FUNCS_TIMEOUT_IN_SECONDS"`
@@ -88,6 +184,18 @@ type Config struct {
 	ServiceName                     string             `json:"ServiceName"`
 	Database                        *database.CitusDSN `mapstructure:"DATABASE" validate:"required" json:"-"`
 	PolicyRules                     *PolicyRules       `mapstructure:"POLICY_RULES" validate:"required"`
+	ExportRules                     *ExportRules       `mapstructure:"EXPORT_RULES" validate:"required"`
+	AggregateRules                  *AggregateRules    `mapstructure:"AGGREGATE_RULES" validate:"required"`
+	MaskingRules                    *MaskingRules      `mapstructure:"MASKING_RULES" validate:"required"`
+	ApprovalRules                   *ApprovalRules     `mapstructure:"APPROVAL_RULES" validate:"required"`
+	TOTPRules                       *TOTPRules         `mapstructure:"TOTP_RULES" validate:"required"`
+	JWTRules                        *JWTRules          `mapstructure:"JWT_RULES" validate:"required"`
+	PasswordRules                   *PasswordRules     `mapstructure:"PASSWORD_RULES" validate:"required"`
+	Notifier                        *NotifierConfig    `mapstructure:"NOTIFIER" validate:"required"`
+	RateLimitRules                  *RateLimitRules    `mapstructure:"RATE_LIMIT_RULES" validate:"required"`
+	AllowlistRules                  *AllowlistRules    `mapstructure:"IP_ALLOWLIST_RULES" validate:"required"`
+	AuditRules                      *AuditRules        `mapstructure:"AUDIT_RULES" validate:"required"`
+	LogRetentionRules               *LogRetentionRules `mapstructure:"LOG_RETENTION_RULES" validate:"required"`
 	PrefixService                   *prefix.Prefix     `json:"-"`
 }
 
@@ -96,11 +204,37 @@ var config = &Config{}
 func init() {
 	viper.SetConfigName("config")
 	viper.AddConfigPath("./internal/config/")
+	// next to this file too, so package tests running in their own directory
+	// find it
+	if _, file, _, ok := runtime.Caller(0); ok {
+		viper.AddConfigPath(filepath.Dir(file))
+	}
 	viper.SetConfigType("json")
 
 	viper.SetDefault("REDIS_PORT", 6379)
 	viper.SetDefault("MAX_RETRY", 5)
 	viper.SetDefault("MAX_WAIT", 2000)
+	viper.SetDefault("ENVIRONMENT", "production")
+	viper.SetDefault("FORWARDED_HEADER", "xff")
+	viper.SetDefault("OTP_PROVIDER", "sms")
+	viper.SetDefault("POLICY_RULES.idle_warning_in_minutes", 2)
+	viper.SetDefault("JWT_RULES.algorithm", "RS256")
+	viper.SetDefault("JWT_RULES.reload_in_seconds", 60)
+	viper.SetDefault("NOTIFIER.provider", "smtp")
+	viper.SetDefault("RATE_LIMIT_RULES.period_in_seconds", 60)
+	viper.SetDefault("IP_ALLOWLIST_RULES.reload_in_seconds", 60)
+	viper.SetDefault("IP_ALLOWLIST_RULES.invalidation_subject", "adminallowlist.invalidate")
+	viper.SetDefault("AUDIT_RULES.anchor_interval_in_minutes", 10)
+	viper.SetDefault("AUDIT_RULES.digest_file", "audit-digests.log")
+	viper.SetDefault("AUDIT_RULES.verify_batch_size", 1000)
+	viper.SetDefault("AUDIT_RULES.max_body_size", 16384)
+	viper.SetDefault("AUDIT_RULES.redact_fields", []string{"password", "secret", "token", "otp", "code", "private_key"})
+	viper.SetDefault("AUDIT_RULES.skip_body_routes", []string{"/auth/password/change", "/auth/stepup", "/auth/totp/confirm", "/auth/totp/disable"})
+	viper.SetDefault("LOG_RETENTION_RULES.retention_in_months", 12)
+	viper.SetDefault("LOG_RETENTION_RULES.premake_months", 2)
+	viper.SetDefault("LOG_RETENTION_RULES.archive_dir", "archives/admin-logs")
+	viper.SetDefault("LOG_RETENTION_RULES.check_interval_in_minutes", 60)
+	viper.SetDefault("LOG_RETENTION_RULES.batch_size", 5000)
 
 	log.Println("Reading config...")
 	err := viper.ReadInConfig()
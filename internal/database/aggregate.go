@@ -0,0 +1,38 @@
+package database
+
+type AggregatedResult struct {
+	Data      []map[string]interface{} `json:"data"`
+	GroupBy   []string                 `json:"group_by"`
+	Metrics   []string                 `json:"metrics"`
+	Groups    int                      `json:"groups"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// AggregateTheResults trims a grouped fetch of maxGroups+1 rows to maxGroups
+// and flags it as truncated when the extra row came back.
+func AggregateTheResults(rows []map[string]interface{}, groupBy, metrics []string, maxGroups int) *AggregatedResult {
+	truncated := maxGroups > 0 && len(rows) > maxGroups
+	if truncated {
+		rows = rows[:maxGroups]
+	}
+
+	for _, row := range rows {
+		for column, value := range row {
+			if raw, ok := value.([]byte); ok {
+				row[column] = string(raw)
+			}
+		}
+	}
+
+	if rows == nil {
+		rows = []map[string]interface{}{}
+	}
+
+	return &AggregatedResult{
+		Data:      rows,
+		GroupBy:   groupBy,
+		Metrics:   metrics,
+		Groups:    len(rows),
+		Truncated: truncated,
+	}
+}
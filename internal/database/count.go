@@ -0,0 +1,82 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type CountMode string
+
+const (
+	CountExact       CountMode = "exact"
+	CountApproximate CountMode = "approx"
+	CountNone        CountMode = "none"
+)
+
+type RowCount struct {
+	Value     int64
+	Estimated bool
+}
+
+func ParseCountMode(value string) (CountMode, bool) {
+	switch CountMode(value) {
+	case CountExact, CountApproximate, CountNone:
+		return CountMode(value), true
+	}
+	return "", false
+}
+
+// CountRows counts the rows matched by countQuery. Approximate counts read
+// pg_class.reltuples for unfiltered queries and the planner estimate from
+// EXPLAIN otherwise, which keeps large tables away from a full COUNT(*).
+func CountRows(countQuery *gorm.DB, mode CountMode) (RowCount, error) {
+	switch mode {
+	case CountNone:
+		return RowCount{}, nil
+	case CountApproximate:
+		return approximateCount(countQuery)
+	}
+
+	var count int64
+	err := countQuery.Count(&count).Error
+
+	return RowCount{Value: count}, err
+}
+
+func approximateCount(countQuery *gorm.DB) (RowCount, error) {
+	var probe []map[string]interface{}
+	statement := countQuery.Session(&gorm.Session{DryRun: true}).Select("1").Find(&probe).Statement
+	if statement.Error != nil {
+		return RowCount{}, statement.Error
+	}
+
+	db := countQuery.Session(&gorm.Session{NewDB: true})
+
+	if _, filtered := statement.Clauses["WHERE"]; !filtered {
+		var reltuples float64
+		if err := db.Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", statement.Table).Row().Scan(&reltuples); err == nil && reltuples >= 0 {
+			return RowCount{Value: int64(reltuples), Estimated: true}, nil
+		}
+	}
+
+	var plan string
+	if err := db.Raw("EXPLAIN (FORMAT JSON) "+statement.SQL.String(), statement.Vars...).Row().Scan(&plan); err != nil {
+		return RowCount{}, err
+	}
+
+	var explained []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil {
+		return RowCount{}, err
+	}
+	if len(explained) == 0 {
+		return RowCount{}, errors.New("empty query plan")
+	}
+
+	return RowCount{Value: int64(explained[0].Plan.PlanRows), Estimated: true}, nil
+}
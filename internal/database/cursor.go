@@ -0,0 +1,191 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	ErrMaskedCursor  = errors.New("cursor pagination is not available on masked fields")
+
+	cursorSchemas = &sync.Map{}
+)
+
+// Cursor is the keyset position of a page. Clients only see it as an opaque
+// token; a cursor without a position asks for the first page. Masked tells
+// the columns the caller may not see, their values never go into a token.
+type Cursor struct {
+	SortBy    string                   `json:"s,omitempty"`
+	SortOrder string                   `json:"o,omitempty"`
+	Value     interface{}              `json:"v,omitempty"`
+	Key       interface{}              `json:"k,omitempty"`
+	Backward  bool                     `json:"b,omitempty"`
+	Masked    func(column string) bool `json:"-"`
+}
+
+func (c *Cursor) HasPosition() bool {
+	return c != nil && c.Key != nil
+}
+
+func (c *Cursor) Descending() bool {
+	return c.SortOrder == "desc"
+}
+
+func EncodeCursor(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.SortOrder != "" && cursor.SortOrder != "asc" && cursor.SortOrder != "desc" {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor.Value = cursorValue(cursor.Value)
+	cursor.Key = cursorValue(cursor.Key)
+
+	return &cursor, nil
+}
+
+func cursorValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return number.String()
+}
+
+// PaginateTheCursorResults turns a keyset fetch of limit+1 rows into a page.
+// The extra row only tells whether another page exists in the direction of
+// travel; backward fetches come in reverse order and are flipped here.
+func PaginateTheCursorResults(result interface{}, total RowCount, limit int, cursor *Cursor, keyColumn string) (*PaginatedResult, error) {
+	rows := reflect.ValueOf(result)
+	if rows.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cursor pagination expects a slice, got %T", result)
+	}
+
+	hasMore := rows.Len() > limit
+	if hasMore {
+		rows = rows.Slice(0, limit)
+	}
+
+	if cursor.Backward {
+		reversed := reflect.MakeSlice(rows.Type(), rows.Len(), rows.Len())
+		for i := 0; i < rows.Len(); i++ {
+			reversed.Index(i).Set(rows.Index(rows.Len() - 1 - i))
+		}
+		rows = reversed
+	}
+
+	paginatedResult := &PaginatedResult{
+		Data:           rows.Interface(),
+		Total:          total.Value,
+		TotalEstimated: total.Estimated,
+		PageSize:       limit,
+		TotalPages:     totalPages(total.Value, limit),
+	}
+
+	if rows.Len() == 0 {
+		return paginatedResult, nil
+	}
+
+	hasNext := hasMore || cursor.Backward
+	hasPrev := (hasMore && cursor.Backward) || (!cursor.Backward && cursor.HasPosition())
+
+	if hasNext {
+		next, err := rowCursor(rows.Index(rows.Len()-1), cursor, keyColumn, false)
+		if err != nil {
+			return nil, err
+		}
+		paginatedResult.NextCursor = &next
+	}
+
+	if hasPrev {
+		prev, err := rowCursor(rows.Index(0), cursor, keyColumn, true)
+		if err != nil {
+			return nil, err
+		}
+		paginatedResult.PrevCursor = &prev
+	}
+
+	return paginatedResult, nil
+}
+
+func rowCursor(row reflect.Value, cursor *Cursor, keyColumn string, backward bool) (string, error) {
+	if cursor.Masked != nil && (cursor.Masked(keyColumn) || (cursor.SortBy != "" && cursor.Masked(cursor.SortBy))) {
+		return "", ErrMaskedCursor
+	}
+
+	for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+		row = row.Elem()
+	}
+
+	rowSchema, err := schema.Parse(row.Addr().Interface(), cursorSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return "", err
+	}
+
+	next := Cursor{SortBy: cursor.SortBy, SortOrder: cursor.SortOrder, Backward: backward}
+
+	if next.Key, err = rowField(rowSchema, row, keyColumn); err != nil {
+		return "", err
+	}
+
+	if cursor.SortBy != "" && cursor.SortBy != keyColumn {
+		if next.Value, err = rowField(rowSchema, row, cursor.SortBy); err != nil {
+			return "", err
+		}
+	}
+
+	return EncodeCursor(next)
+}
+
+func rowField(rowSchema *schema.Schema, row reflect.Value, column string) (interface{}, error) {
+	field := rowSchema.LookUpField(column)
+	if field == nil {
+		return nil, fmt.Errorf("column %s is not part of the result", column)
+	}
+
+	value, _ := field.ValueOf(context.Background(), row)
+
+	fieldValue := reflect.ValueOf(value)
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil, nil
+		}
+		value = fieldValue.Elem().Interface()
+	}
+
+	return value, nil
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+type cursorRow struct {
+	ID     int64  `gorm:"column:id"`
+	Amount *int64 `gorm:"column:amount"`
+	Name   string `gorm:"column:name"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "first page", cursor: Cursor{SortBy: "amount", SortOrder: "desc"}},
+		{name: "integer position", cursor: Cursor{SortBy: "amount", SortOrder: "asc", Value: int64(1 << 60), Key: int64(42)}},
+		{name: "float position", cursor: Cursor{SortBy: "price", SortOrder: "asc", Value: 9.75, Key: int64(7)}},
+		{name: "text position backward", cursor: Cursor{SortBy: "name", SortOrder: "desc", Value: "jane", Key: "a1b2", Backward: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := EncodeCursor(tt.cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := DecodeCursor(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(*decoded, tt.cursor) {
+				t.Errorf("DecodeCursor(EncodeCursor(%+v)) = %+v", tt.cursor, *decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "not a cursor!"},
+		{name: "not json", token: "bm90IGpzb24"},
+		{name: "unknown sort order", token: mustEncodeCursor(t, Cursor{SortOrder: "sideways"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token); err != ErrInvalidCursor {
+				t.Errorf("DecodeCursor(%q) error = %v, want %v", tt.token, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func mustEncodeCursor(t *testing.T, cursor Cursor) string {
+	t.Helper()
+
+	token, err := EncodeCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func cursorRows(ids ...int64) []cursorRow {
+	rows := make([]cursorRow, len(ids))
+	for i, id := range ids {
+		amount := id * 10
+		rows[i] = cursorRow{ID: id, Amount: &amount}
+	}
+	return rows
+}
+
+func TestPaginateTheCursorResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []cursorRow
+		cursor   Cursor
+		wantIDs  []int64
+		wantNext *Cursor
+		wantPrev *Cursor
+	}{
+		{
+			name:     "first page with more rows",
+			rows:     cursorRows(1, 2, 3),
+			cursor:   Cursor{SortBy: "amount", SortOrder: "asc"},
+			wantIDs:  []int64{1, 2},
+			wantNext: &Cursor{SortBy: "amount", SortOrder: "asc", Value: int64(20), Key: int64(2)},
+		},
+		{
+			name:    "only page",
+			rows:    cursorRows(1, 2),
+			cursor:  Cursor{SortBy: "amount", SortOrder: "asc"},
+			wantIDs: []int64{1, 2},
+		},
+		{
+			name:     "last page reached forward",
+			rows:     cursorRows(3, 4),
+			cursor:   Cursor{SortBy: "amount", SortOrder: "asc", Value: int64(20), Key: int64(2)},
+			wantIDs:  []int64{3, 4},
+			wantPrev: &Cursor{SortBy: "amount", SortOrder: "asc", Value: int64(30), Key: int64(3), Backward: true},
+		},
+		{
+			name:     "backward page is flipped",
+			rows:     cursorRows(4, 3, 2),
+			cursor:   Cursor{SortOrder: "asc", Key: int64(5), Backward: true},
+			wantIDs:  []int64{3, 4},
+			wantNext: &Cursor{SortOrder: "asc", Key: int64(4)},
+			wantPrev: &Cursor{SortOrder: "asc", Key: int64(3), Backward: true},
+		},
+		{
+			name:     "backward to the first page",
+			rows:     cursorRows(2, 1),
+			cursor:   Cursor{SortOrder: "asc", Key: int64(3), Backward: true},
+			wantIDs:  []int64{1, 2},
+			wantNext: &Cursor{SortOrder: "asc", Key: int64(2)},
+		},
+		{
+			name:    "empty page",
+			rows:    cursorRows(),
+			cursor:  Cursor{SortOrder: "asc", Key: int64(9)},
+			wantIDs: []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PaginateTheCursorResults(tt.rows, RowCount{Value: 4}, 2, &tt.cursor, "id")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rows := result.Data.([]cursorRow)
+			ids := make([]int64, len(rows))
+			for i, row := range rows {
+				ids[i] = row.ID
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("rows = %v, want %v", ids, tt.wantIDs)
+			}
+
+			checkCursor(t, "next", result.NextCursor, tt.wantNext)
+			checkCursor(t, "prev", result.PrevCursor, tt.wantPrev)
+		})
+	}
+}
+
+func checkCursor(t *testing.T, name string, token *string, want *Cursor) {
+	t.Helper()
+
+	if token == nil || want == nil {
+		if (token == nil) != (want == nil) {
+			t.Errorf("%s cursor = %v, want %+v", name, token, want)
+		}
+		return
+	}
+
+	got, err := DecodeCursor(*token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*got, *want) {
+		t.Errorf("%s cursor = %+v, want %+v", name, *got, *want)
+	}
+}
+
+func TestPaginateTheCursorResultsMasked(t *testing.T) {
+	cursor := &Cursor{SortBy: "amount", SortOrder: "asc", Masked: func(column string) bool { return column == "amount" }}
+
+	if _, err := PaginateTheCursorResults(cursorRows(1, 2, 3), RowCount{Value: 3}, 2, cursor, "id"); err != ErrMaskedCursor {
+		t.Errorf("PaginateTheCursorResults() error = %v, want %v", err, ErrMaskedCursor)
+	}
+}
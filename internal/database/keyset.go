@@ -0,0 +1,137 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const defaultKeysetBatchSize = 1000
+
+// RowHandler receives the result columns once, before the first row, and then
+// every row in key order.
+type RowHandler interface {
+	Columns(columns []string) error
+	Row(values []interface{}) error
+}
+
+type KeysetOptions struct {
+	KeyColumn string // qualified column used in WHERE and ORDER BY, e.g. orders.id
+	KeyField  string // name of the key in the result columns, e.g. id
+	BatchSize int
+	MaxRows   int
+}
+
+// StreamByKeyset walks the result of newQuery in ascending key order, one
+// batch per query, so deep exports never pay for OFFSET scans. newQuery must
+// return a fresh statement on every call. It stops at MaxRows and returns the
+// number of rows handed to handle.
+func StreamByKeyset(newQuery func() *gorm.DB, options KeysetOptions, handle RowHandler) (int, error) {
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultKeysetBatchSize
+	}
+
+	var lastKey interface{}
+	streamed := 0
+	keyIndex := -1
+
+	for {
+		limit := batchSize
+		if options.MaxRows > 0 && options.MaxRows-streamed < limit {
+			limit = options.MaxRows - streamed
+		}
+		if limit <= 0 {
+			return streamed, nil
+		}
+
+		query := newQuery()
+		if lastKey != nil {
+			query = query.Where(fmt.Sprintf("%s > ?", options.KeyColumn), lastKey)
+		}
+
+		rows, err := query.Order(options.KeyColumn + " ASC").Limit(limit).Rows()
+		if err != nil {
+			return streamed, err
+		}
+
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return streamed, err
+		}
+
+		if keyIndex < 0 {
+			for i, column := range columns {
+				if column == options.KeyField {
+					keyIndex = i
+					break
+				}
+			}
+			if keyIndex < 0 {
+				rows.Close()
+				return streamed, fmt.Errorf("key field %s is not selected", options.KeyField)
+			}
+
+			if err := handle.Columns(columns); err != nil {
+				rows.Close()
+				return streamed, err
+			}
+		}
+
+		fetched := 0
+		for rows.Next() {
+			values := make([]interface{}, len(columns))
+			pointers := make([]interface{}, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+
+			if err := rows.Scan(pointers...); err != nil {
+				rows.Close()
+				return streamed, err
+			}
+
+			for i, value := range values {
+				if raw, ok := value.([]byte); ok {
+					values[i] = string(raw)
+				}
+			}
+
+			if err := handle.Row(values); err != nil {
+				rows.Close()
+				return streamed, err
+			}
+
+			lastKey = values[keyIndex]
+			fetched++
+			streamed++
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return streamed, err
+		}
+
+		if fetched < limit {
+			return streamed, nil
+		}
+	}
+}
+
+// KeysetFields makes sure an explicit column selection carries the key field
+// the iteration resumes from.
+func KeysetFields(fields []string, keyField string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	for _, field := range fields {
+		if field == keyField {
+			return fields
+		}
+	}
+
+	return append([]string{keyField}, fields...)
+}
@@ -5,17 +5,20 @@ import (
 )
 
 type PaginatedResult struct {
-	Data         interface{} `json:"data"`
-	Total        int64       `json:"total"`
-	CurrentPage  int         `json:"current_page"`
-	NextPage     *int        `json:"next_page"`
-	PreviousPage *int        `json:"previous_page"`
-	PageSize     int         `json:"page_size"`
-	TotalPages   int         `json:"total_pages"`
+	Data           interface{} `json:"data"`
+	Total          int64       `json:"total"`
+	TotalEstimated bool        `json:"total_estimated,omitempty"`
+	CurrentPage    int         `json:"current_page"`
+	NextPage       *int        `json:"next_page"`
+	PreviousPage   *int        `json:"previous_page"`
+	PageSize       int         `json:"page_size"`
+	TotalPages     int         `json:"total_pages"`
+	NextCursor     *string     `json:"next_cursor,omitempty"`
+	PrevCursor     *string     `json:"prev_cursor,omitempty"`
 }
 
 func PaginateTheResults(result interface{}, count int64, offset, page, limit int) *PaginatedResult {
-	totalPages := int(math.Ceil(float64(count) / float64(limit)))
+	totalPages := totalPages(count, limit)
 
 	nextPage := page + 1
 	if nextPage > totalPages {
@@ -38,4 +41,24 @@ func PaginateTheResults(result interface{}, count int64, offset, page, limit int
 	}
 
 	return paginatedResult
-}
\ No newline at end of file
+}
+
+// PaginateThePage builds the page for either pagination mode: offset pages
+// when cursor is nil, keyset pages otherwise.
+func PaginateThePage(result interface{}, total RowCount, page, limit int, cursor *Cursor, keyColumn string) (*PaginatedResult, error) {
+	if cursor != nil {
+		return PaginateTheCursorResults(result, total, limit, cursor, keyColumn)
+	}
+
+	paginatedResult := PaginateTheResults(result, total.Value, (page-1)*limit, page, limit)
+	paginatedResult.TotalEstimated = total.Estimated
+
+	return paginatedResult, nil
+}
+
+func totalPages(count int64, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(count) / float64(limit)))
+}
@@ -0,0 +1,176 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type TokenType int
+
+const (
+	TokenEOF TokenType = iota
+	TokenWord
+	TokenString
+	TokenPipe
+	TokenComma
+	TokenLParen
+	TokenRParen
+	TokenCompare
+	TokenAnd
+	TokenOr
+	TokenNot
+)
+
+type Token struct {
+	Type  TokenType
+	Value string
+	Pos   int
+}
+
+func (t Token) String() string {
+	switch t.Type {
+	case TokenEOF:
+		return "end of input"
+	case TokenString:
+		return fmt.Sprintf("%q", t.Value)
+	default:
+		return fmt.Sprintf("'%s'", t.Value)
+	}
+}
+
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func Tokenize(input string) ([]Token, error) {
+	l := &lexer{input: []rune(input)}
+
+	var tokens []Token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Type == TokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (Token, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+		l.pos++
+	}
+
+	if l.pos >= len(l.input) {
+		return Token{Type: TokenEOF, Pos: l.pos}, nil
+	}
+
+	start := l.pos
+	ch := l.input[l.pos]
+
+	switch ch {
+	case '|':
+		l.pos++
+		return Token{Type: TokenPipe, Value: "|", Pos: start}, nil
+	case ',':
+		l.pos++
+		return Token{Type: TokenComma, Value: ",", Pos: start}, nil
+	case '(':
+		l.pos++
+		return Token{Type: TokenLParen, Value: "(", Pos: start}, nil
+	case ')':
+		l.pos++
+		return Token{Type: TokenRParen, Value: ")", Pos: start}, nil
+	case '"', '\'':
+		return l.quoted(ch)
+	case '=', '<', '>', '!':
+		return l.compare()
+	case '~':
+		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '*' {
+			l.pos++
+			return Token{Type: TokenCompare, Value: "~*", Pos: start}, nil
+		}
+		return Token{Type: TokenCompare, Value: "~", Pos: start}, nil
+	}
+
+	for l.pos < len(l.input) && isWordRune(l.input[l.pos]) {
+		l.pos++
+	}
+
+	word := string(l.input[start:l.pos])
+
+	switch strings.ToLower(word) {
+	case "and":
+		return Token{Type: TokenAnd, Value: word, Pos: start}, nil
+	case "or":
+		return Token{Type: TokenOr, Value: word, Pos: start}, nil
+	case "not":
+		return Token{Type: TokenNot, Value: word, Pos: start}, nil
+	}
+
+	return Token{Type: TokenWord, Value: word, Pos: start}, nil
+}
+
+func (l *lexer) quoted(quote rune) (Token, error) {
+	start := l.pos
+	l.pos++
+
+	var sb strings.Builder
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+
+		if ch == '\\' && l.pos+1 < len(l.input) {
+			next := l.input[l.pos+1]
+			if next == quote || next == '\\' {
+				sb.WriteRune(next)
+				l.pos += 2
+				continue
+			}
+		}
+
+		if ch == quote {
+			l.pos++
+			return Token{Type: TokenString, Value: sb.String(), Pos: start}, nil
+		}
+
+		sb.WriteRune(ch)
+		l.pos++
+	}
+
+	return Token{}, fmt.Errorf("unterminated quoted value at position %d", start)
+}
+
+func (l *lexer) compare() (Token, error) {
+	start := l.pos
+	ch := l.input[l.pos]
+	l.pos++
+
+	if l.pos < len(l.input) && l.input[l.pos] == '=' {
+		l.pos++
+		return Token{Type: TokenCompare, Value: string(ch) + "=", Pos: start}, nil
+	}
+
+	if ch == '!' {
+		return Token{}, fmt.Errorf("unexpected '!' at position %d", start)
+	}
+
+	return Token{Type: TokenCompare, Value: string(ch), Pos: start}, nil
+}
+
+func isWordRune(r rune) bool {
+	if unicode.IsSpace(r) {
+		return false
+	}
+
+	switch r {
+	case '|', ',', '(', ')', '"', '\'', '=', '<', '>', '!', '~':
+		return false
+	}
+
+	return true
+}
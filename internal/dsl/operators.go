@@ -0,0 +1,142 @@
+package dsl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Enum is implemented by named search field types that only accept a fixed
+// set of values, so DSL conditions can be checked before they reach the query.
+type Enum interface {
+	EnumValues() []string
+}
+
+var comparisonOperators = map[string]struct{}{
+	"=": {}, "eq": {},
+	">": {}, "gt": {},
+	">=": {}, "gte": {},
+	"<": {}, "lt": {},
+	"<=": {}, "lte": {},
+	"!=": {}, "neq": {},
+}
+
+var textOperators = map[string]struct{}{
+	"contains": {}, "cont": {}, "inc": {},
+	"notcontains": {}, "nocont": {}, "noinc": {},
+	"start": {}, "end": {},
+	"regex": {}, "~": {},
+	"iregex": {}, "~*": {},
+}
+
+var listOperators = map[string]struct{}{
+	"in": {}, "nin": {},
+}
+
+var rangeOperators = map[string]struct{}{
+	"between": {},
+}
+
+var nullOperators = map[string]struct{}{
+	"isnull": {}, "notnull": {},
+}
+
+var relativeTimeOperators = map[string]struct{}{
+	"last": {}, "since": {},
+}
+
+func IsComparisonOperator(operator string) bool {
+	_, ok := comparisonOperators[operator]
+	return ok
+}
+
+func IsTextOperator(operator string) bool {
+	_, ok := textOperators[operator]
+	return ok
+}
+
+func IsListOperator(operator string) bool {
+	_, ok := listOperators[operator]
+	return ok
+}
+
+func IsRangeOperator(operator string) bool {
+	_, ok := rangeOperators[operator]
+	return ok
+}
+
+func IsNullOperator(operator string) bool {
+	_, ok := nullOperators[operator]
+	return ok
+}
+
+func IsRelativeTimeOperator(operator string) bool {
+	_, ok := relativeTimeOperators[operator]
+	return ok
+}
+
+func IsKnownOperator(operator string) bool {
+	return IsComparisonOperator(operator) || IsTextOperator(operator) || IsListOperator(operator) ||
+		IsRangeOperator(operator) || IsNullOperator(operator) || IsRelativeTimeOperator(operator)
+}
+
+// RelativeTime resolves "last 7d" style windows and "since 2024-01-01" dates
+// to the lower bound they describe.
+func RelativeTime(operator, value string, now time.Time) (time.Time, error) {
+	switch operator {
+	case "last":
+		window, err := ParseWindow(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-window), nil
+	case "since":
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", value)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid date '%s', expected RFC3339 or 2006-01-02", value)
+			}
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unknown relative time operator '%s'", operator)
+}
+
+// ParseWindow accepts <n><unit> with units s, m, h, d and w.
+func ParseWindow(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 {
+		return 0, fmt.Errorf("invalid time window '%s', expected e.g. 7d or 12h", value)
+	}
+
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid time window '%s', expected e.g. 7d or 12h", value)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 's':
+		unit = time.Second
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid time window unit in '%s', use s, m, h, d or w", value)
+	}
+
+	if time.Duration(amount) > time.Duration(math.MaxInt64)/unit {
+		return 0, fmt.Errorf("time window '%s' is too long", value)
+	}
+
+	return time.Duration(amount) * unit, nil
+}
@@ -0,0 +1,42 @@
+package dsl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "30s", want: 30 * time.Second},
+		{value: "15m", want: 15 * time.Minute},
+		{value: " 12h ", want: 12 * time.Hour},
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "2w", want: 14 * 24 * time.Hour},
+		{value: "106751d", want: 106751 * 24 * time.Hour},
+		{value: "106752d", wantErr: true},
+		{value: "15250285w", wantErr: true},
+		{value: "9223372036854775807s", wantErr: true},
+		{value: "99999999999999999999s", wantErr: true},
+		{value: "0d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "7y", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseWindow(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWindow(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWindow(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
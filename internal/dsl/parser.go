@@ -0,0 +1,297 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+/*
+	expression := or
+	or         := and ( "or" and )*
+	and        := unary ( ( "and" | "," ) unary )*
+	unary      := "not" unary | primary
+	primary    := "(" or ")" | condition
+	condition  := field "|" operator [ "|" operand ] | field ( compare | operator ) [ operand ]
+	operand    := value | "(" value ( "," value )* ")"
+*/
+
+type Node interface {
+	node()
+}
+
+type BinaryNode struct {
+	Logic string
+	Left  Node
+	Right Node
+}
+
+type NotNode struct {
+	Operand Node
+}
+
+type ConditionNode struct {
+	Field    string
+	Operator string
+	Value    string
+	Values   []string
+}
+
+func (*BinaryNode) node()    {}
+func (*NotNode) node()       {}
+func (*ConditionNode) node() {}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+func Parse(input string) (Node, error) {
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	if p.peek().Type == TokenEOF {
+		return nil, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.Type != TokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.Pos)
+	}
+
+	return root, nil
+}
+
+func ParseConditions(input string) ([]types.QueryCondition, error) {
+	root, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ToQueryConditions(root), nil
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() Token {
+	tok := p.tokens[p.pos]
+	if tok.Type != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(tokenType TokenType, expected string) (Token, error) {
+	tok := p.advance()
+	if tok.Type != tokenType {
+		return tok, fmt.Errorf("expected %s but found %s at position %d", expected, tok, tok.Pos)
+	}
+	return tok, nil
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().Type == TokenOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryNode{Logic: types.LogicOr, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().Type == TokenAnd || p.peek().Type == TokenComma {
+		p.advance()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryNode{Logic: types.LogicAnd, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().Type == TokenNot {
+		p.advance()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	if p.peek().Type == TokenLParen {
+		p.advance()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(TokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (Node, error) {
+	field, err := p.expect(TokenWord, "field name")
+	if err != nil {
+		return nil, err
+	}
+
+	var operator string
+	piped := false
+
+	switch tok := p.advance(); tok.Type {
+	case TokenPipe:
+		op := p.advance()
+		if op.Type != TokenWord && op.Type != TokenCompare {
+			return nil, fmt.Errorf("expected operator but found %s at position %d", op, op.Pos)
+		}
+		operator = strings.ToLower(op.Value)
+		piped = true
+	case TokenCompare:
+		operator = tok.Value
+	case TokenWord:
+		operator = strings.ToLower(tok.Value)
+		if !IsKnownOperator(operator) {
+			return nil, fmt.Errorf("unknown operator %s at position %d", tok, tok.Pos)
+		}
+	default:
+		return nil, fmt.Errorf("expected '|' or operator after field %s but found %s at position %d", field, tok, tok.Pos)
+	}
+
+	condition := &ConditionNode{Field: field.Value, Operator: operator}
+
+	if piped {
+		if p.peek().Type != TokenPipe {
+			if IsNullOperator(operator) {
+				return condition, nil
+			}
+			tok := p.peek()
+			return nil, fmt.Errorf("expected '|' but found %s at position %d", tok, tok.Pos)
+		}
+		p.advance()
+	}
+
+	if IsNullOperator(operator) {
+		if tok := p.peek(); piped && (tok.Type == TokenWord || tok.Type == TokenString) {
+			p.advance()
+		}
+		return condition, nil
+	}
+
+	if p.peek().Type == TokenLParen {
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		condition.Values = values
+		return condition, nil
+	}
+
+	value := p.advance()
+	if value.Type != TokenWord && value.Type != TokenString {
+		return nil, fmt.Errorf("expected value but found %s at position %d", value, value.Pos)
+	}
+
+	condition.Value = value.Value
+	if IsListOperator(operator) || IsRangeOperator(operator) {
+		condition.Values = []string{value.Value}
+	}
+
+	return condition, nil
+}
+
+func (p *parser) parseList() ([]string, error) {
+	p.advance()
+
+	var values []string
+	for {
+		value := p.advance()
+		if value.Type != TokenWord && value.Type != TokenString {
+			return nil, fmt.Errorf("expected list value but found %s at position %d", value, value.Pos)
+		}
+		values = append(values, value.Value)
+
+		switch tok := p.advance(); tok.Type {
+		case TokenComma:
+			continue
+		case TokenRParen:
+			return values, nil
+		default:
+			return nil, fmt.Errorf("expected ',' or ')' but found %s at position %d", tok, tok.Pos)
+		}
+	}
+}
+
+// ToQueryConditions flattens chains of the same logic into sibling lists and
+// nests mixed logic as groups, so the result can be compiled clause by clause.
+func ToQueryConditions(root Node) []types.QueryCondition {
+	if root == nil {
+		return nil
+	}
+
+	return flatten(root)
+}
+
+func flatten(n Node) []types.QueryCondition {
+	switch node := n.(type) {
+	case *BinaryNode:
+		left := operand(node.Left, node.Logic)
+		right := operand(node.Right, node.Logic)
+		right[0].Logic = node.Logic
+		return append(left, right...)
+	case *NotNode:
+		inner := flatten(node.Operand)
+		if len(inner) == 1 {
+			inner[0].Negate = !inner[0].Negate
+			return inner
+		}
+		return []types.QueryCondition{{Negate: true, Group: inner}}
+	case *ConditionNode:
+		return []types.QueryCondition{{Field: node.Field, Operator: node.Operator, Value: node.Value, Values: node.Values}}
+	}
+
+	return nil
+}
+
+func operand(n Node, parentLogic string) []types.QueryCondition {
+	if child, ok := n.(*BinaryNode); ok && child.Logic != parentLogic {
+		return []types.QueryCondition{{Group: flatten(child)}}
+	}
+
+	return flatten(n)
+}
@@ -0,0 +1,145 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+// render writes conditions back as DSL with every group parenthesized, so the
+// tests read the precedence the parser picked.
+func render(conditions []types.QueryCondition) string {
+	var sb strings.Builder
+	for i, condition := range conditions {
+		if i > 0 {
+			sb.WriteString(" " + condition.Logic + " ")
+		}
+		if condition.Negate {
+			sb.WriteString("not ")
+		}
+
+		if len(condition.Group) > 0 {
+			sb.WriteString("(" + render(condition.Group) + ")")
+			continue
+		}
+
+		sb.WriteString(condition.Field + " " + condition.Operator)
+		switch {
+		case len(condition.Values) > 0:
+			sb.WriteString(" (" + strings.Join(condition.Values, ",") + ")")
+		case condition.Value != "":
+			sb.WriteString(" " + condition.Value)
+		}
+	}
+	return sb.String()
+}
+
+func TestParseConditionsPrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "a = 1 and b = 2 or c = 3", want: "(a = 1 and b = 2) or c = 3"},
+		{input: "a = 1 or b = 2 and c = 3", want: "a = 1 or (b = 2 and c = 3)"},
+		{input: "a = 1 OR b = 2 AND c = 3", want: "a = 1 or (b = 2 and c = 3)"},
+		{input: "(a = 1 or b = 2) and c = 3", want: "(a = 1 or b = 2) and c = 3"},
+		{input: "a = 1 and b = 2 and c = 3", want: "a = 1 and b = 2 and c = 3"},
+		{input: "a = 1 or b = 2 or c = 3", want: "a = 1 or b = 2 or c = 3"},
+		{input: "a = 1, b = 2 or c = 3", want: "(a = 1 and b = 2) or c = 3"},
+		{input: "((a = 1))", want: "a = 1"},
+		{input: "not a = 1 and b = 2", want: "not a = 1 and b = 2"},
+		{input: "not (a = 1 or b = 2) and c = 3", want: "not (a = 1 or b = 2) and c = 3"},
+		{input: "not not a = 1", want: "a = 1"},
+		{input: "(a = 1 or b = 2) and (c = 3 or d = 4)", want: "(a = 1 or b = 2) and (c = 3 or d = 4)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			conditions, err := ParseConditions(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := render(conditions); got != tt.want {
+				t.Errorf("ParseConditions(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConditionsForms(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "amount >= 5", want: "amount >= 5"},
+		{input: "amount|>=|5", want: "amount >= 5"},
+		{input: "name|contains|jane", want: "name contains jane"},
+		{input: "name CONTAINS 'jane doe'", want: "name contains jane doe"},
+		{input: `name = "say \"hi\""`, want: `name = say "hi"`},
+		{input: "id in (1, 2, 3)", want: "id in (1,2,3)"},
+		{input: "id|in|(1,2)", want: "id in (1,2)"},
+		{input: "id in 1", want: "id in (1)"},
+		{input: "price between (1, 2)", want: "price between (1,2)"},
+		{input: "deleted_at|isnull", want: "deleted_at isnull"},
+		{input: "deleted_at isnull and a = 1", want: "deleted_at isnull and a = 1"},
+		{input: "name ~* '^j'", want: "name ~* ^j"},
+		{input: "created_at last 7d", want: "created_at last 7d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			conditions, err := ParseConditions(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := render(conditions); got != tt.want {
+				t.Errorf("ParseConditions(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConditionsEmpty(t *testing.T) {
+	conditions, err := ParseConditions("   ")
+	if err != nil || conditions != nil {
+		t.Errorf("ParseConditions of blank input = %v, %v, want nil, nil", conditions, err)
+	}
+}
+
+func TestParseConditionsErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "a = 1 and", want: "expected field name but found end of input at position 9"},
+		{input: "(a = 1", want: "expected ')' but found end of input at position 6"},
+		{input: "a = 1)", want: "unexpected ')' at position 5"},
+		{input: "a = 1 or or b = 2", want: "expected field name but found 'or' at position 9"},
+		{input: "a foo 1", want: "unknown operator 'foo' at position 2"},
+		{input: "a", want: "expected '|' or operator after field 'a' but found end of input at position 1"},
+		{input: "a =", want: "expected value but found end of input at position 3"},
+		{input: "a|=", want: "expected '|' but found end of input at position 3"},
+		{input: "a|(|1", want: "expected operator but found '(' at position 2"},
+		{input: "a = 'x", want: "unterminated quoted value at position 4"},
+		{input: "a ! 1", want: "unexpected '!' at position 2"},
+		{input: "a in (1,", want: "expected list value but found end of input at position 8"},
+		{input: "a in (1 2)", want: "expected ',' or ')' but found '2' at position 8"},
+		{input: "= 1", want: "expected field name but found '=' at position 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseConditions(tt.input)
+			if err == nil {
+				t.Fatalf("ParseConditions(%q) succeeded, want %q", tt.input, tt.want)
+			}
+
+			if err.Error() != tt.want {
+				t.Errorf("ParseConditions(%q) error = %q, want %q", tt.input, err.Error(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteHeader(columns []string) error {
+	c.record = make([]string, len(columns))
+	return c.writer.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	if len(c.record) != len(values) {
+		c.record = make([]string, len(values))
+	}
+
+	for i, value := range values {
+		c.record[i] = formatValue(value)
+	}
+
+	return c.writer.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// ndjsonWriter writes one object per row. Keys are emitted in column order,
+// which a map would not preserve.
+type ndjsonWriter struct {
+	writer *bufio.Writer
+	keys   [][]byte
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{writer: bufio.NewWriter(w)}
+}
+
+func (n *ndjsonWriter) WriteHeader(columns []string) error {
+	n.keys = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		n.keys[i] = key
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) WriteRow(values []interface{}) error {
+	n.writer.WriteByte('{')
+
+	for i, value := range values {
+		if i > 0 {
+			n.writer.WriteByte(',')
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		n.writer.Write(n.keys[i])
+		n.writer.WriteByte(':')
+		n.writer.Write(encoded)
+	}
+
+	n.writer.WriteByte('}')
+	return n.writer.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.writer.Flush()
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderExportStatus = "X-Export-Status"
+	HeaderExportRows   = "X-Export-Rows"
+	HeaderExportRowCap = "X-Export-Row-Cap"
+
+	StatusComplete  = "complete"
+	StatusTruncated = "truncated"
+	StatusFailed    = "failed"
+)
+
+type responseStream struct {
+	context   *gin.Context
+	format    Format
+	name      string
+	maxRows   int
+	writer    Writer
+	written   int
+	truncated bool
+}
+
+// Stream runs the export and writes it as an attachment. Headers are deferred
+// until the query has returned its columns, so a failure before that point is
+// still answered with a regular JSON error. The outcome and row count are sent
+// as trailers since the body is already on the wire by then. The export is
+// run for one row past the cap; that row is not written, it only tells the
+// export was cut.
+func Stream(c *gin.Context, logger *zap.Logger, params *types.ExportParams, name string, run func(handle database.RowHandler) (int, appErrors.Error)) {
+	format, err := ParseFormat(params.Format)
+	if err != nil {
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid export format", http.StatusBadRequest)
+		return
+	}
+
+	stream := &responseStream{context: c, format: format, name: name, maxRows: params.MaxRows}
+
+	if params.MaxRows > 0 {
+		params.MaxRows++
+		defer func() { params.MaxRows-- }()
+	}
+
+	rows, appErr := run(stream)
+	if stream.truncated {
+		rows = stream.written
+	}
+	if stream.writer == nil {
+		if appErr != nil {
+			utils.IfErrorExistReturnWithError(c, appErr)
+			return
+		}
+		utils.IfErrorExistReturnWithErrorExplanation(c, nil, "Export returned no columns", http.StatusInternalServerError)
+		return
+	}
+
+	status := StatusComplete
+	if appErr != nil {
+		status = StatusFailed
+		logger.Error("export interrupted", zap.String("export", name), zap.Int("rows", rows), zap.Error(appErr))
+	} else if stream.truncated {
+		status = StatusTruncated
+	}
+
+	// a failed xlsx is left without its central directory on purpose, so it
+	// cannot be mistaken for a complete workbook
+	if appErr == nil || format != FormatXLSX {
+		if err := stream.writer.Close(); err != nil {
+			status = StatusFailed
+			logger.Error("error closing export", zap.String("export", name), zap.Error(err))
+		}
+	}
+
+	c.Writer.Header().Set(HeaderExportStatus, status)
+	c.Writer.Header().Set(HeaderExportRows, strconv.Itoa(rows))
+	c.Writer.Flush()
+}
+
+func (s *responseStream) Columns(columns []string) error {
+	if s.writer != nil {
+		return nil
+	}
+
+	header := s.context.Writer.Header()
+	header.Set("Content-Type", s.format.ContentType())
+	header.Set("Content-Disposition", `attachment; filename="`+s.format.FileName(s.name, time.Now())+`"`)
+	header.Set("Cache-Control", "no-store")
+	header.Set("Trailer", HeaderExportStatus+", "+HeaderExportRows)
+	if s.maxRows > 0 {
+		header.Set(HeaderExportRowCap, strconv.Itoa(s.maxRows))
+	}
+	s.context.Status(http.StatusOK)
+
+	writer, err := NewWriter(s.format, s.context.Writer)
+	if err != nil {
+		return err
+	}
+
+	s.writer = writer
+	return writer.WriteHeader(columns)
+}
+
+func (s *responseStream) Row(values []interface{}) error {
+	if s.maxRows > 0 && s.written >= s.maxRows {
+		s.truncated = true
+		return nil
+	}
+
+	s.written++
+	return s.writer.WriteRow(values)
+}
@@ -3,10 +3,13 @@ package factory
 import (
 	"context"
 
+	administratorAllowlistHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/allowlist"
+	administratorApprovalsHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/approvals"
 	administratorAuthHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/auth"
 	administratorLogsHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/logs"
 	administratorPolicyHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/policy"
 	administratorUserRolesHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/roles"
+	administratorSearchesHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/searches"
 	administratorUserHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/user"
 
 	"github.com/denizumutdereli/stream-admin/internal/handler"
@@ -33,6 +36,8 @@ func (f *serviceFactory) NewAdminLogsService(ctx context.Context) (*administrato
 		return nil, err
 	}
 
+	service.SetIsLeader(ctx, f.isLeader.Load())
+
 	handler, err := f.registry.handlers.RegisterAdminLogsHandler(service)
 	if err != nil {
 		f.logger.Error("Failed to register and get admin auth handler")
@@ -160,6 +165,136 @@ func (f *serviceFactory) NewAdminPolicyService(ctx context.Context) (*administra
 	return handler, nil
 }
 
+// NewAdminSavedSearchService registers the policy service it depends on itself,
+// as services are initialized concurrently.
+func (f *serviceFactory) NewAdminSavedSearchService(ctx context.Context) (*administratorSearchesHandler.AdminSavedSearchHandler, error) {
+	serviceName := "admin-saved-searches"
+	servicePrefix, exists := f.config.PrefixService.GetServicePrefix(serviceName)
+	if !exists {
+		f.logger.Fatal("No prefix found for service:", zap.String("serviceName", serviceName))
+	}
+
+	repo, err := f.registry.repos.RegisterAdminSavedSearchRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	policyRepo, err := f.registry.repos.RegisterAdminPolicyRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	policyService, err := f.registry.services.RegisterAdminPolicyService(policyRepo, f.caesar, f.config)
+	if err != nil {
+		f.logger.Fatal("service registry error:", zap.Error(err))
+		return nil, err
+	}
+
+	service, err := f.registry.services.RegisterAdminSavedSearchService(repo, policyService, f.caesar, f.config)
+	if err != nil {
+		f.logger.Fatal("service registry error:", zap.Error(err))
+		return nil, err
+	}
+
+	handler, err := f.registry.handlers.RegisterAdminSavedSearchHandler(&service)
+	if err != nil {
+		f.logger.Error("Failed to register and get admin saved search handler")
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+// NewAdminApprovalsService registers the policy service and the contextual
+// messages it depends on itself, as services are initialized concurrently.
+func (f *serviceFactory) NewAdminApprovalsService(ctx context.Context) (*administratorApprovalsHandler.AdminApprovalsHandler, error) {
+	serviceName := "admin-approvals"
+	servicePrefix, exists := f.config.PrefixService.GetServicePrefix(serviceName)
+	if !exists {
+		f.logger.Fatal("No prefix found for service:", zap.String("serviceName", serviceName))
+	}
+
+	repo, err := f.registry.repos.RegisterAdminApprovalsRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	adminUsersRepo, err := f.registry.repos.RegisterAdminUsersRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	policyRepo, err := f.registry.repos.RegisterAdminPolicyRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	policyService, err := f.registry.services.RegisterAdminPolicyService(policyRepo, f.caesar, f.config)
+	if err != nil {
+		f.logger.Fatal("service registry error:", zap.Error(err))
+		return nil, err
+	}
+
+	contextMessages, err := f.registry.services.RegisterAdminContextMessageService(f.config, f.redis, f.nats)
+	if err != nil {
+		f.logger.Fatal("service registry error:", zap.Error(err))
+		return nil, err
+	}
+
+	service, err := f.registry.services.RegisterAdminApprovalsService(repo, adminUsersRepo, policyService, contextMessages, f.caesar, f.config)
+	if err != nil {
+		f.logger.Fatal("service registry error:", zap.Error(err))
+		return nil, err
+	}
+
+	handler, err := f.registry.handlers.RegisterAdminApprovalsHandler(&service)
+	if err != nil {
+		f.logger.Error("Failed to register and get admin approvals handler")
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+func (f *serviceFactory) NewAdminAllowlistService(ctx context.Context) (*administratorAllowlistHandler.AdminAllowlistHandler, error) {
+	serviceName := "admin-allowlist"
+	servicePrefix, exists := f.config.PrefixService.GetServicePrefix(serviceName)
+	if !exists {
+		f.logger.Fatal("No prefix found for service:", zap.String("serviceName", serviceName))
+	}
+
+	repo, err := f.registry.repos.RegisterAdminAllowlistRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	adminUsersRepo, err := f.registry.repos.RegisterAdminUsersRepository(servicePrefix)
+	if err != nil {
+		f.logger.Fatal("service repository error:", zap.Error(err))
+		return nil, err
+	}
+
+	service, err := f.registry.services.RegisterAdminAllowlistService(repo, adminUsersRepo, f.nats, f.config)
+	if err != nil {
+		f.logger.Fatal("service registry error:", zap.Error(err))
+		return nil, err
+	}
+
+	handler, err := f.registry.handlers.RegisterAdminAllowlistHandler(&service)
+	if err != nil {
+		f.logger.Error("Failed to register and get admin allowlist handler")
+		return nil, err
+	}
+
+	return handler, nil
+}
+
 func (f *serviceFactory) NewAdminServiceFactory(ctx context.Context) (*handler.AdminRestHandler, error) {
 
 	repo, err := f.registry.repos.RegisterAdminRepository()
@@ -177,6 +312,10 @@ func (f *serviceFactory) NewAdminServiceFactory(ctx context.Context) (*handler.A
 	go func() {
 		for leaderStatus := range f.config.IsLeader {
 			isInstanceLeader := leaderStatus
+			f.isLeader.Store(leaderStatus)
+			if logsService, _ := f.registry.services.GetAdminLogsService(); logsService != nil {
+				logsService.SetIsLeader(ctx, leaderStatus)
+			}
 			if leaderStatus {
 				f.logger.Info("I am now the leader :)", zap.Bool("isLeader", isInstanceLeader))
 				service.SetIsLeader(ctx, true)
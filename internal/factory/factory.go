@@ -3,6 +3,7 @@ package factory
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/caesar"
@@ -10,10 +11,13 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/config"
 
 	"github.com/denizumutdereli/stream-admin/internal/handler"
+	administratorAllowlistHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/allowlist"
+	administratorApprovalsHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/approvals"
 	administratorAuthHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/auth"
 	administratorLogsHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/logs"
 	administratorPolicyHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/policy"
 	administratorUserRolesHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/roles"
+	administratorSearchesHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/searches"
 	administratorUserHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/user"
 	"github.com/denizumutdereli/stream-admin/internal/registry"
 	"github.com/denizumutdereli/stream-admin/internal/service/administrator/stream"
@@ -50,6 +54,9 @@ type ServiceFactory interface {
 	NewAdminUserService(ctx context.Context) (*administratorUserHandler.AdminUserHandler, error)
 	NewAdminUserRolesService(ctx context.Context) (*administratorUserRolesHandler.AdminUserRolesHandler, error)
 	NewAdminPolicyService(ctx context.Context) (*administratorPolicyHandler.AdminPolicyHandler, error)
+	NewAdminSavedSearchService(ctx context.Context) (*administratorSearchesHandler.AdminSavedSearchHandler, error)
+	NewAdminApprovalsService(ctx context.Context) (*administratorApprovalsHandler.AdminApprovalsHandler, error)
+	NewAdminAllowlistService(ctx context.Context) (*administratorAllowlistHandler.AdminAllowlistHandler, error)
 	NewAdminContextMessageService(ctx context.Context) (contextMessage.ContextMessages, error)
 
 	NewStreamAssetsService() (*stream.AssetsService, error)
@@ -85,6 +92,7 @@ type serviceFactory struct {
 	wsserver            *wsserver.Server
 	serverReady         chan struct{}
 	registry            serviceRegistry
+	isLeader            atomic.Bool
 }
 
 func NewServiceFactory(config *config.Config) (ServiceFactory, error) {
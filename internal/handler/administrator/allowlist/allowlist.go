@@ -0,0 +1,175 @@
+package allowlist
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	service "github.com/denizumutdereli/stream-admin/internal/service/administrator/allowlist"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type AdminAllowlistHandler interface {
+	CreateEntry(c *gin.Context)
+	UpdateEntry(c *gin.Context)
+	DeleteEntry(c *gin.Context)
+	GetEntry(c *gin.Context)
+	GetEntries(c *gin.Context)
+}
+
+type adminAllowlistHandler struct {
+	allowlistService service.AdminAllowlistService
+	config           *config.Config
+	logger           *zap.Logger
+	builders         builders.BuilderService
+	mid_             types.QueryParams
+}
+
+func NewAdminAllowlistHandler(allowlistService *service.AdminAllowlistService, cfg *config.Config, builders builders.BuilderService) AdminAllowlistHandler {
+	return &adminAllowlistHandler{allowlistService: *allowlistService, config: cfg, logger: cfg.Logger, builders: builders}
+}
+
+func (h *adminAllowlistHandler) CreateEntry(c *gin.Context) {
+	var request models.AdministratorIPAllowlistRequest
+
+	if !h.bindAllowlistRequest(c, &request) {
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	added, err := h.allowlistService.CreateEntry(c.Request.Context(), &request, userID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  http.StatusCreated,
+		"message": "Allowlist entry successfully created",
+		"data":    added,
+	})
+}
+
+func (h *adminAllowlistHandler) UpdateEntry(c *gin.Context) {
+	var request models.AdministratorIPAllowlistRequest
+
+	if !h.bindAllowlistRequest(c, &request) {
+		return
+	}
+
+	if request.EntryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Allowlist entry ID is required"})
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	updated, err := h.allowlistService.UpdateEntry(c.Request.Context(), &request, userID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": "Allowlist entry successfully updated",
+		"data":    updated,
+	})
+}
+
+func (h *adminAllowlistHandler) DeleteEntry(c *gin.Context) {
+	entryID := c.Param("entry_id")
+
+	if entryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Allowlist entry ID is required"})
+		return
+	}
+
+	if err := h.allowlistService.DeleteEntry(c.Request.Context(), entryID); err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": "Allowlist entry successfully deleted",
+	})
+}
+
+func (h *adminAllowlistHandler) GetEntry(c *gin.Context) {
+	entryID := c.Param("entry_id")
+
+	if entryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Allowlist entry ID is required"})
+		return
+	}
+
+	entry, err := h.allowlistService.GetEntry(entryID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *adminAllowlistHandler) GetEntries(c *gin.Context) {
+	var queryParams models.AdministratorIPAllowlistSearch
+	dqlQuery := make([]types.QueryCondition, 0)
+
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+
+	if err := bind.GetError(); err != nil {
+		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Error in query parameters", msgs, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Bad request", http.StatusBadRequest)
+		}
+		return
+	}
+
+	queryParams.DSLSearchOperator = &dqlQuery
+
+	paginatedResults, err := h.allowlistService.GetEntries(&h.mid_.Pagination, &queryParams)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedResults)
+}
+
+func (h *adminAllowlistHandler) bindAllowlistRequest(c *gin.Context, request *models.AdministratorIPAllowlistRequest) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return false
+		}
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return false
+	}
+
+	if err := models.ValidateAllowlistEntry(request); err != nil {
+		h.logger.Error("Validation error", zap.Error(err))
+
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errorMessages := make(map[string]string)
+			for _, errField := range validationErrors {
+				errorMessages[errField.Field()] = errField.Translate(nil)
+			}
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Validation error", errorMessages, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Validation error", http.StatusBadRequest)
+		}
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,160 @@
+package approvals
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	service "github.com/denizumutdereli/stream-admin/internal/service/administrator/approvals"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type AdminApprovalsHandler interface {
+	GetApprovals(c *gin.Context)
+	GetApproval(c *gin.Context)
+	ApproveRequest(c *gin.Context)
+	RejectRequest(c *gin.Context)
+}
+
+type adminApprovalsHandler struct {
+	approvalsService service.AdminApprovalsService
+	config           *config.Config
+	logger           *zap.Logger
+	builders         builders.BuilderService
+	mid_             types.QueryParams
+}
+
+func NewAdminApprovalsHandler(approvalsService *service.AdminApprovalsService, cfg *config.Config, builders builders.BuilderService) AdminApprovalsHandler {
+	return &adminApprovalsHandler{approvalsService: *approvalsService, config: cfg, logger: cfg.Logger, builders: builders}
+}
+
+func (h *adminApprovalsHandler) GetApprovals(c *gin.Context) {
+	var queryParams models.AdministratorApprovalSearch
+	dqlQuery := make([]types.QueryCondition, 0)
+
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+
+	if err := bind.GetError(); err != nil {
+		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Error in query parameters", msgs, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Bad request", http.StatusBadRequest)
+		}
+		return
+	}
+
+	queryParams.DSLSearchOperator = &dqlQuery
+
+	paginatedResults, err := h.approvalsService.GetApprovals(&h.mid_.Pagination, &queryParams)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedResults)
+}
+
+func (h *adminApprovalsHandler) GetApproval(c *gin.Context) {
+	approvalID := c.Param("approval_id")
+
+	if approvalID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Approval ID is required"})
+		return
+	}
+
+	approval, err := h.approvalsService.GetApproval(approvalID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, approval)
+}
+
+func (h *adminApprovalsHandler) ApproveRequest(c *gin.Context) {
+	approvalID := c.Param("approval_id")
+
+	if approvalID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Approval ID is required"})
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	roleID := c.GetString(string(types.ContextRoleKey))
+
+	stepUpVerified := c.GetBool(string(types.ContextStepUpVerifiedKey))
+
+	approval, err := h.approvalsService.ApproveRequest(c.Request.Context(), approvalID, userID, roleID, stepUpVerified)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	message := "Request approved and executed"
+	if approval.Status == models.ApprovalStatusFailed {
+		message = "Request approved but its execution has failed"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": message,
+		"data":    approval,
+	})
+}
+
+func (h *adminApprovalsHandler) RejectRequest(c *gin.Context) {
+	approvalID := c.Param("approval_id")
+
+	if approvalID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Approval ID is required"})
+		return
+	}
+
+	var rejection models.AdministratorApprovalRejection
+
+	if err := c.ShouldBindJSON(&rejection); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	if err := models.ValidateApprovalRejection(&rejection); err != nil {
+		h.logger.Error("Validation error", zap.Error(err))
+
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errorMessages := make(map[string]string)
+			for _, errField := range validationErrors {
+				errorMessages[errField.Field()] = errField.Translate(nil)
+			}
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Validation error", errorMessages, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Validation error", http.StatusBadRequest)
+		}
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	roleID := c.GetString(string(types.ContextRoleKey))
+
+	approval, err := h.approvalsService.RejectRequest(c.Request.Context(), approvalID, userID, roleID, rejection.Reason)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": "Request rejected",
+		"data":    approval,
+	})
+}
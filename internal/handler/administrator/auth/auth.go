@@ -7,6 +7,7 @@ import (
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
 	service "github.com/denizumutdereli/stream-admin/internal/service/administrator/auth"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/denizumutdereli/stream-admin/internal/utils"
@@ -20,6 +21,18 @@ type AdminAuthHandler interface {
 	Logout(c *gin.Context)
 	RefreshToken(c *gin.Context)
 	VerifyAccount(c *gin.Context)
+	StepUp(c *gin.Context)
+	EnrollTOTP(c *gin.Context)
+	ConfirmTOTP(c *gin.Context)
+	DisableTOTP(c *gin.Context)
+	GetSessions(c *gin.Context)
+	RevokeSession(c *gin.Context)
+	GetUserSessions(c *gin.Context)
+	RevokeUserSessions(c *gin.Context)
+	JWKS(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	ChangePassword(c *gin.Context)
 }
 
 type adminAuthHandler struct {
@@ -34,11 +47,9 @@ func NewAdminAuthHandler(authService *service.AdminAuthService, cfg *config.Conf
 }
 
 func (ac *adminAuthHandler) Login(c *gin.Context) {
-	debug := false
 	var loginInfo struct {
 		Username string `json:"username" binding:"required"`
 		Password string `json:"password" binding:"required"`
-		Debug    string `json:"debug" binding:""`
 	}
 
 	if err := c.ShouldBindJSON(&loginInfo); err != nil {
@@ -51,19 +62,21 @@ func (ac *adminAuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	if loginInfo.Debug != "" {
-		debug = true
-	}
-
-	_, err := ac.authService.SignIn(loginInfo.Username, loginInfo.Password, debug)
+	method, err := ac.authService.SignIn(loginInfo.Username, loginInfo.Password)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	message := "your credentials are valid and otp code sent"
+	if method == models.TwoFactorTOTP {
+		message = "your credentials are valid, enter the code of your authenticator app"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  true,
-		"message": "your credentials are valid and otp code sent",
+		"message": message,
+		"method":  method,
 	})
 }
 
@@ -85,7 +98,7 @@ func (ac *adminAuthHandler) VerifyOTPAndLogin(c *gin.Context) {
 
 	userAgent := c.Request.Header.Get("User-Agent")
 
-	responseTokens, err := ac.authService.VerifyOTPAndLogin(loginInfo.OTP, loginInfo.Username, userAgent)
+	responseTokens, err := ac.authService.VerifyOTPAndLogin(loginInfo.OTP, loginInfo.Username, userAgent, utils.GetClientIP(c))
 
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
@@ -93,10 +106,11 @@ func (ac *adminAuthHandler) VerifyOTPAndLogin(c *gin.Context) {
 	}
 
 	response := types.TokenResponse{
-		AccessToken:           responseTokens.AccessToken,
-		RefreshToken:          responseTokens.RefreshToken,
-		AccessTokenExpiresIn:  responseTokens.AccessTokenExpiresIn,
-		RefreshTokenExpiresIn: responseTokens.RefreshTokenExpiresIn,
+		AccessToken:            responseTokens.AccessToken,
+		RefreshToken:           responseTokens.RefreshToken,
+		AccessTokenExpiresIn:   responseTokens.AccessTokenExpiresIn,
+		RefreshTokenExpiresIn:  responseTokens.RefreshTokenExpiresIn,
+		PasswordChangeRequired: responseTokens.PasswordChangeRequired,
 	}
 	
 	c.JSON(http.StatusOK, response)
@@ -153,6 +167,280 @@ func (ac *adminAuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// StepUp answers the challenge of a sensitive action with the code sent to
+// the user and returns the token to retry the action with.
+func (ac *adminAuthHandler) StepUp(c *gin.Context) {
+	var stepUpRequest struct {
+		ChallengeID string `json:"challenge_id" binding:"required"`
+		OTP         string `json:"otp" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&stepUpRequest); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	stepUpToken, err := ac.authService.VerifyStepUp(c.Request.Context(), userID, stepUpRequest.ChallengeID, stepUpRequest.OTP)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"message": "step-up verified, retry the action with the step-up token",
+		"data":    stepUpToken,
+	})
+}
+
+// EnrollTOTP returns the secret and the qr code to set up an authenticator
+// app with. The enrollment is pending until confirmed with a first code.
+func (ac *adminAuthHandler) EnrollTOTP(c *gin.Context) {
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	enrollment, err := ac.authService.EnrollTOTP(c.Request.Context(), userID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"message": "scan the qr code with your authenticator app and confirm with a code",
+		"data":    enrollment,
+	})
+}
+
+func (ac *adminAuthHandler) ConfirmTOTP(c *gin.Context) {
+	var confirmRequest struct {
+		OTP string `json:"otp" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&confirmRequest); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	recoveryCodes, err := ac.authService.ConfirmTOTP(c.Request.Context(), userID, confirmRequest.OTP)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":         true,
+		"message":        "authenticator enabled, keep the recovery codes somewhere safe, they are not shown again",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func (ac *adminAuthHandler) DisableTOTP(c *gin.Context) {
+	var disableRequest struct {
+		OTP string `json:"otp" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&disableRequest); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	if err := ac.authService.DisableTOTP(c.Request.Context(), userID, disableRequest.OTP); err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"message": "authenticator disabled, otp codes are sent by sms again",
+	})
+}
+
+func (ac *adminAuthHandler) ForgotPassword(c *gin.Context) {
+	var forgotRequest struct {
+		Username string `json:"username" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&forgotRequest); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	if err := ac.authService.ForgotPassword(c.Request.Context(), forgotRequest.Username); err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"message": "if the account exists, a password reset link has been sent",
+	})
+}
+
+func (ac *adminAuthHandler) ResetPassword(c *gin.Context) {
+	var resetRequest struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&resetRequest); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	if err := ac.authService.ResetPassword(c.Request.Context(), resetRequest.Token, resetRequest.Password); err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"message": "password reset, sign in with the new password",
+	})
+}
+
+func (ac *adminAuthHandler) ChangePassword(c *gin.Context) {
+	var changeRequest struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&changeRequest); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	sessionID := c.GetString(string(types.ContextSessionID))
+
+	tokens, err := ac.authService.ChangePassword(c.Request.Context(), userID, sessionID, changeRequest.CurrentPassword, changeRequest.NewPassword)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"message": "password changed, other sessions are signed out",
+		"data":    tokens,
+	})
+}
+
+// JWKS serves the public signing keys as a plain JWK set, the format other
+// services expect when verifying admin tokens.
+func (ac *adminAuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, ac.authService.GetJWKS())
+}
+
+func (ac *adminAuthHandler) GetSessions(c *gin.Context) {
+	userID := c.GetString(string(types.ContextUserIDKey))
+	sessionID := c.GetString(string(types.ContextSessionID))
+
+	sessions, err := ac.authService.GetSessions(userID, sessionID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+func (ac *adminAuthHandler) RevokeSession(c *gin.Context) {
+	sessionID := c.Param("session_id")
+
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session ID is required"})
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	if err := ac.authService.RevokeSession(userID, sessionID); err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+func (ac *adminAuthHandler) GetUserSessions(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		return
+	}
+
+	sessions, err := ac.authService.GetSessions(userID, c.GetString(string(types.ContextSessionID)))
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+func (ac *adminAuthHandler) RevokeUserSessions(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		return
+	}
+
+	// signing other admins out is left to superadmins, anyone may sign themselves out
+	if userID != c.GetString(string(types.ContextUserIDKey)) {
+		decision, _ := c.Get(string(types.ContextPolicyDecisionKey))
+		if policyDecision, ok := decision.(types.PolicyDecision); !ok || !policyDecision.SuperAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only a superadmin can revoke the sessions of another admin"})
+			return
+		}
+	}
+
+	if err := ac.authService.RevokeUserSessions(userID); err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All sessions of the user are revoked"})
+}
+
 func (ac *adminAuthHandler) VerifyAccount(c *gin.Context) {
 	var verificationRequest struct {
 		VerificationCode string `json:"verification_code" binding:"required"`
@@ -2,9 +2,13 @@ package logs
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/export"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
 	logService "github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -14,6 +18,7 @@ import (
 
 type AdminLogsRestHandler interface {
 	GetAll(c *gin.Context)
+	Verify(c *gin.Context)
 }
 
 type adminLogsRestHandler struct {
@@ -30,8 +35,9 @@ func NewAdminLogsRestHandler(s logService.AdminLogsService, cfg *config.Config,
 func (h *adminLogsRestHandler) GetAll(c *gin.Context) {
 	var queryParams models.AdministratorLogsSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -44,6 +50,13 @@ func (h *adminLogsRestHandler) GetAll(c *gin.Context) {
 
 	queryParams.DSLSearchOperator = &dqlQuery
 
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "admin-logs", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.Export(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetAll(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithErrorExplanation(c, err, "error while fetching data", err.StatusCode())
@@ -52,3 +65,38 @@ func (h *adminLogsRestHandler) GetAll(c *gin.Context) {
 
 	c.JSON(http.StatusOK, paginatedResults)
 }
+
+func (h *adminLogsRestHandler) Verify(c *gin.Context) {
+	var seqs [2]int64
+
+	for i, param := range []string{"from_seq", "to_seq"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+
+		seq, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seq < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a positive number"})
+			return
+		}
+		seqs[i] = seq
+	}
+
+	reports, err := h.StreamService.VerifyChain(c.Query("node_id"), seqs[0], seqs[1])
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	valid := true
+	for _, report := range reports {
+		valid = valid && report.Valid
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": http.StatusOK,
+		"valid":  valid,
+		"data":   reports,
+	})
+}
@@ -1,12 +1,14 @@
 package policy
 
 import (
+	"errors"
 	"io"
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	rolePolicyModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/peggy"
 	service "github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/denizumutdereli/stream-admin/internal/utils"
@@ -20,6 +22,7 @@ type AdminPolicyHandler interface {
 	UpdateAdminRolePolicy(c *gin.Context)
 	DeleteAdminRolePolicy(c *gin.Context)
 	GetAdminRolePolicies(c *gin.Context)
+	CompileAdminRolePolicy(c *gin.Context)
 }
 
 type adminPolicyHandler struct {
@@ -70,6 +73,8 @@ func (h *adminPolicyHandler) CreateAdminRolePolicy(c *gin.Context) {
 		return
 	}
 
+	utils.SetAuditResource(c, "", added.PolicyID)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  http.StatusCreated,
 		"message": "Admin role policy successfully created",
@@ -105,12 +110,16 @@ func (h *adminPolicyHandler) UpdateAdminRolePolicy(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.policyService.GetAdminRolePolicyByID(adminRolePolicy.PolicyID)
+
 	updatedPolicy, err := h.policyService.UpdateAdminRolePolicy(c.Request.Context(), &adminRolePolicy)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	utils.SetAuditChange(c, adminRolePolicy.PolicyID, before, updatedPolicy)
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  http.StatusOK,
 		"message": "Admin role policy successfully updated",
@@ -126,18 +135,61 @@ func (h *adminPolicyHandler) DeleteAdminRolePolicy(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.policyService.GetAdminRolePolicyByID(policyID)
+
 	err := h.policyService.DeleteAdminRolePolicy(c.Request.Context(), policyID)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	utils.SetAuditChange(c, policyID, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  http.StatusOK,
 		"message": "Admin role policy successfully deleted",
 	})
 }
 
+// CompileAdminRolePolicy parses policy text without storing it, so editors
+// can show the resulting rules or the line and column of each error.
+func (h *adminPolicyHandler) CompileAdminRolePolicy(c *gin.Context) {
+	var policyText rolePolicyModels.AdministratorRolePolicyText
+
+	if err := c.ShouldBindJSON(&policyText); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return
+		}
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	if policyText.SubPoliciesText == "" {
+		utils.IfErrorExistReturnWithErrorExplanation(c, nil, "sub_policies_text is required", http.StatusBadRequest)
+		return
+	}
+
+	subPolicies, err := h.policyService.CompileSubPolicies(policyText.SubPoliciesText)
+	if err != nil {
+		var syntaxErrors peggy.SyntaxErrors
+		if errors.As(err, &syntaxErrors) {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Policy text has syntax errors", Details: syntaxErrors})
+			return
+		}
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": "Admin role policy successfully compiled",
+		"data": gin.H{
+			"sub_policy_rules": subPolicies,
+		},
+	})
+}
+
 func (h *adminPolicyHandler) GetAdminRolePolicies(c *gin.Context) {
 	var queryParams rolePolicyModels.AdministratorRolePolicySearch
 	dqlQuery := make([]types.QueryCondition, 0)
@@ -68,6 +68,8 @@ func (h *adminUserRolesHandler) CreateAdminRole(c *gin.Context) {
 		return
 	}
 
+	utils.SetAuditResource(c, "", added.RoleID)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  http.StatusCreated,
 		"message": "Admin role successfully created",
@@ -118,12 +120,17 @@ func (h *adminUserRolesHandler) AttachPoliciesToRole(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.rolesService.GetRoleWithPolicyTitles(request.RoleID)
+
 	role, err := h.rolesService.AttachPoliciesToRole(c.Request.Context(), request.RoleID, request.PolicyIDs)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	after, _ := h.rolesService.GetRoleWithPolicyTitles(request.RoleID)
+	utils.SetAuditChange(c, request.RoleID, before, after)
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  http.StatusOK,
 		"message": "Policies successfully attached to role",
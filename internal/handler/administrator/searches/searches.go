@@ -0,0 +1,199 @@
+package searches
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	reportModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	service "github.com/denizumutdereli/stream-admin/internal/service/administrator/searches"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type AdminSavedSearchHandler interface {
+	CreateSavedSearch(c *gin.Context)
+	UpdateSavedSearch(c *gin.Context)
+	DeleteSavedSearch(c *gin.Context)
+	GetSavedSearch(c *gin.Context)
+	GetSavedSearches(c *gin.Context)
+}
+
+type adminSavedSearchHandler struct {
+	searchService service.AdminSavedSearchService
+	config        *config.Config
+	logger        *zap.Logger
+	builders      builders.BuilderService
+	mid_          types.QueryParams
+}
+
+func NewAdminSavedSearchHandler(searchService *service.AdminSavedSearchService, cfg *config.Config, builders builders.BuilderService) AdminSavedSearchHandler {
+	return &adminSavedSearchHandler{searchService: *searchService, config: cfg, logger: cfg.Logger, builders: builders}
+}
+
+func (h *adminSavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	var request reportModels.AdministratorSavedSearchRequest
+
+	if !h.bindSavedSearchRequest(c, &request) {
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	roleID := c.GetString(string(types.ContextRoleKey))
+
+	added, err := h.searchService.CreateSavedSearch(c.Request.Context(), &request, userID, roleID)
+	if err != nil {
+		h.returnSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  http.StatusCreated,
+		"message": "Saved search successfully created",
+		"data":    added,
+	})
+}
+
+func (h *adminSavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	var request reportModels.AdministratorSavedSearchRequest
+
+	if !h.bindSavedSearchRequest(c, &request) {
+		return
+	}
+
+	if request.ID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Saved search ID is required"})
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	roleID := c.GetString(string(types.ContextRoleKey))
+
+	updated, err := h.searchService.UpdateSavedSearch(c.Request.Context(), &request, userID, roleID)
+	if err != nil {
+		h.returnSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": "Saved search successfully updated",
+		"data":    updated,
+	})
+}
+
+func (h *adminSavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	searchID, convErr := strconv.ParseUint(c.Param("search_id"), 10, 64)
+	if convErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+
+	err := h.searchService.DeleteSavedSearch(c.Request.Context(), uint(searchID), userID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  http.StatusOK,
+		"message": "Saved search successfully deleted",
+	})
+}
+
+func (h *adminSavedSearchHandler) GetSavedSearch(c *gin.Context) {
+	searchID, convErr := strconv.ParseUint(c.Param("search_id"), 10, 64)
+	if convErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	roleID := c.GetString(string(types.ContextRoleKey))
+
+	savedSearch, err := h.searchService.GetSavedSearch(uint(searchID), userID, roleID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, savedSearch)
+}
+
+func (h *adminSavedSearchHandler) GetSavedSearches(c *gin.Context) {
+	var queryParams reportModels.AdministratorDashboardQuerySearch
+	dqlQuery := make([]types.QueryCondition, 0)
+
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+
+	if err := bind.GetError(); err != nil {
+		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Error in query parameters", msgs, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Bad request", http.StatusBadRequest)
+		}
+		return
+	}
+
+	queryParams.DSLSearchOperator = &dqlQuery
+
+	userID := c.GetString(string(types.ContextUserIDKey))
+	roleID := c.GetString(string(types.ContextRoleKey))
+
+	paginatedResults, err := h.searchService.GetSavedSearches(&h.mid_.Pagination, &queryParams, userID, roleID)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedResults)
+}
+
+func (h *adminSavedSearchHandler) bindSavedSearchRequest(c *gin.Context, request *reportModels.AdministratorSavedSearchRequest) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		if err == io.EOF {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body is empty", http.StatusBadRequest)
+			return false
+		}
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Invalid JSON format", http.StatusBadRequest)
+		return false
+	}
+
+	if err := reportModels.ValidateSavedSearch(request); err != nil {
+		h.logger.Error("Validation error", zap.Error(err))
+
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			errorMessages := make(map[string]string)
+			for _, errField := range validationErrors {
+				errorMessages[errField.Field()] = errField.Translate(nil)
+			}
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Validation error", errorMessages, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Validation error", http.StatusBadRequest)
+		}
+		return false
+	}
+
+	return true
+}
+
+// returnSavedSearchError surfaces per-field DSL errors the same way the search
+// endpoints do, so a rejected save points at the offending fields.
+func (h *adminSavedSearchHandler) returnSavedSearchError(c *gin.Context, err appErrors.Error) {
+	var validationErr *builders.DSLValidationError
+	if errors.As(err.InternalError(), &validationErr) {
+		utils.IfErrorExistReturnWithErrorDetails(c, err, "Saved search validation has failed", validationErr.Details, err.StatusCode())
+		return
+	}
+
+	utils.IfErrorExistReturnWithError(c, err)
+}
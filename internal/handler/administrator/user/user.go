@@ -89,6 +89,8 @@ func (h *adminUserHandler) CreateAdminUser(c *gin.Context) {
 		return
 	}
 
+	utils.SetAuditResource(c, "", added.UserID)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"status":  http.StatusCreated,
 		"message": "Admin user successfully created. Let's verify.",
@@ -124,12 +126,17 @@ func (h *adminUserHandler) UpdateAdminUser(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.usersService.GetAdminUserByID(adminUser.UserID)
+
 	updatedPolicy, err := h.usersService.UpdateAdminUser(c.Request.Context(), &adminUser)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	after, _ := h.usersService.GetAdminUserByID(adminUser.UserID)
+	utils.SetAuditChange(c, adminUser.UserID, before, after)
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  http.StatusOK,
 		"message": "Admin user policy successfully updated",
@@ -145,12 +152,16 @@ func (h *adminUserHandler) DeleteAdminUser(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.usersService.GetAdminUserByID(userID)
+
 	err := h.usersService.DeleteAdminUser(c.Request.Context(), userID)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	utils.SetAuditChange(c, userID, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  http.StatusOK,
 		"message": "Admin user successfully deleted",
@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/export"
 	models "github.com/denizumutdereli/stream-admin/internal/models/assets"
 	"github.com/denizumutdereli/stream-admin/internal/service"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -43,8 +46,9 @@ func (h *assetsRestHandler) GetSearchParameters(c *gin.Context) {
 func (h *assetsRestHandler) GetCoins(c *gin.Context) {
 	var queryParams models.AssetsCoinsSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -56,6 +60,14 @@ func (h *assetsRestHandler) GetCoins(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "coins", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportCoins(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetCoins(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
@@ -68,8 +80,9 @@ func (h *assetsRestHandler) GetCoins(c *gin.Context) {
 func (h *assetsRestHandler) GetAssets(c *gin.Context) {
 	var queryParams models.AssetsSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -81,6 +94,14 @@ func (h *assetsRestHandler) GetAssets(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "assets", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportAssets(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetAssets(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
@@ -93,8 +114,9 @@ func (h *assetsRestHandler) GetAssets(c *gin.Context) {
 func (h *assetsRestHandler) GetNetworks(c *gin.Context) {
 	var queryParams models.AssetsNetworksSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -106,6 +128,14 @@ func (h *assetsRestHandler) GetNetworks(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "networks", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportNetworks(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetNetworks(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
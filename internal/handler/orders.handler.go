@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/export"
 	models "github.com/denizumutdereli/stream-admin/internal/models/orders"
 	"github.com/denizumutdereli/stream-admin/internal/service"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -14,6 +17,7 @@ import (
 
 type OrdersRestHandler interface {
 	GetAll(c *gin.Context)
+	Aggregate(c *gin.Context)
 }
 
 type ordersRestHandler struct {
@@ -30,8 +34,9 @@ func NewOrdersRestHandler(s service.OrdersService, cfg *config.Config, builders
 func (h *ordersRestHandler) GetAll(c *gin.Context) {
 	var queryParams models.OrderSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -43,6 +48,14 @@ func (h *ordersRestHandler) GetAll(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "orders", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.Export(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetAll(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
@@ -51,3 +64,30 @@ func (h *ordersRestHandler) GetAll(c *gin.Context) {
 
 	c.JSON(http.StatusOK, paginatedResults)
 }
+
+func (h *ordersRestHandler) Aggregate(c *gin.Context) {
+	var queryParams models.OrderSearch
+	dqlQuery := make([]types.QueryCondition, 0)
+	var aggregateParams types.AggregateParams
+
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindAggregate(&aggregateParams).Validate()
+
+	if err := bind.GetError(); err != nil {
+		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Error in query parameters", msgs, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Bad request", http.StatusBadRequest)
+		}
+		return
+	}
+
+	queryParams.DSLSearchOperator = &dqlQuery
+
+	aggregatedResults, err := h.StreamService.Aggregate(&aggregateParams, &queryParams)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, aggregatedResults)
+}
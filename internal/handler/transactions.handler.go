@@ -4,7 +4,11 @@ import (
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/export"
+	"github.com/denizumutdereli/stream-admin/internal/masking"
 	models "github.com/denizumutdereli/stream-admin/internal/models/transactions"
 	"github.com/denizumutdereli/stream-admin/internal/service"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -17,6 +21,8 @@ type TransactionsRestHandler interface {
 	GetFiatTransactions(c *gin.Context)
 	GetCryptoTransactions(c *gin.Context)
 	GetCryptoWallets(c *gin.Context)
+	AggregateFiatTransactions(c *gin.Context)
+	AggregateCryptoTransactions(c *gin.Context)
 }
 
 type transactionsRestHandler struct {
@@ -43,8 +49,9 @@ func (h *transactionsRestHandler) GetSearchParameters(c *gin.Context) {
 func (h *transactionsRestHandler) GetFiatTransactions(c *gin.Context) {
 	var queryParams models.FiatTransactionsSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -56,20 +63,32 @@ func (h *transactionsRestHandler) GetFiatTransactions(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+	masker := masking.FromContext(c, h.config.MaskingRules)
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "fiat-transactions", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportFiatTransactions(&exportParams, &queryParams, masker.Rows(handle))
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetFiatTransactions(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	masker.Apply(paginatedResults.Data)
+
 	c.JSON(http.StatusOK, paginatedResults)
 }
 
 func (h *transactionsRestHandler) GetCryptoTransactions(c *gin.Context) {
 	var queryParams models.CryptoTransactionsSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -81,6 +100,14 @@ func (h *transactionsRestHandler) GetCryptoTransactions(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "crypto-transactions", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportCryptoTransactions(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetCryptoTransactions(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
@@ -93,8 +120,9 @@ func (h *transactionsRestHandler) GetCryptoTransactions(c *gin.Context) {
 func (h *transactionsRestHandler) GetCryptoWallets(c *gin.Context) {
 	var queryParams models.CryptoWalletsSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -106,6 +134,14 @@ func (h *transactionsRestHandler) GetCryptoWallets(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "crypto-wallets", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportCryptoWallets(&exportParams, &queryParams, handle)
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetCryptoWallets(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
@@ -114,3 +150,63 @@ func (h *transactionsRestHandler) GetCryptoWallets(c *gin.Context) {
 
 	c.JSON(http.StatusOK, paginatedResults)
 }
+
+func (h *transactionsRestHandler) AggregateFiatTransactions(c *gin.Context) {
+	var queryParams models.FiatTransactionsSearch
+	dqlQuery := make([]types.QueryCondition, 0)
+	var aggregateParams types.AggregateParams
+
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindAggregate(&aggregateParams).Validate()
+
+	if err := bind.GetError(); err != nil {
+		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Error in query parameters", msgs, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Bad request", http.StatusBadRequest)
+		}
+		return
+	}
+
+	queryParams.DSLSearchOperator = &dqlQuery
+
+	// grouping or measuring a masked column would reveal its values
+	if details := masking.FromContext(c, h.config.MaskingRules).HiddenDetails(aggregateParams.Columns()...); details != nil {
+		utils.IfErrorExistReturnWithErrorDetails(c, nil, "Masked fields cannot be aggregated", details, http.StatusForbidden)
+		return
+	}
+
+	aggregatedResults, err := h.StreamService.AggregateFiatTransactions(&aggregateParams, &queryParams)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, aggregatedResults)
+}
+
+func (h *transactionsRestHandler) AggregateCryptoTransactions(c *gin.Context) {
+	var queryParams models.CryptoTransactionsSearch
+	dqlQuery := make([]types.QueryCondition, 0)
+	var aggregateParams types.AggregateParams
+
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindAggregate(&aggregateParams).Validate()
+
+	if err := bind.GetError(); err != nil {
+		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
+			utils.IfErrorExistReturnWithErrorDetails(c, err, "Error in query parameters", msgs, http.StatusBadRequest)
+		} else {
+			utils.IfErrorExistReturnWithErrorExplanation(c, err, "Bad request", http.StatusBadRequest)
+		}
+		return
+	}
+
+	queryParams.DSLSearchOperator = &dqlQuery
+
+	aggregatedResults, err := h.StreamService.AggregateCryptoTransactions(&aggregateParams, &queryParams)
+	if err != nil {
+		utils.IfErrorExistReturnWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, aggregatedResults)
+}
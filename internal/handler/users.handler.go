@@ -4,7 +4,11 @@ import (
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/export"
+	"github.com/denizumutdereli/stream-admin/internal/masking"
 	models "github.com/denizumutdereli/stream-admin/internal/models/users"
 	"github.com/denizumutdereli/stream-admin/internal/service"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -54,8 +58,9 @@ func (h *usersRestHandler) GetSearchKYCParameters(c *gin.Context) {
 func (h *usersRestHandler) GetUsers(c *gin.Context) {
 	var queryParams models.UserSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -67,12 +72,23 @@ func (h *usersRestHandler) GetUsers(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+	masker := masking.FromContext(c, h.config.MaskingRules)
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "users", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportUsers(&exportParams, &queryParams, masker.Rows(handle))
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetUsers(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	masker.Apply(paginatedResults.Data)
+
 	c.JSON(http.StatusOK, paginatedResults)
 }
 
@@ -121,8 +137,9 @@ func (h *usersRestHandler) GetUsers(c *gin.Context) {
 func (h *usersRestHandler) GetKYC(c *gin.Context) {
 	var queryParams models.UserKYCSearch
 	dqlQuery := make([]types.QueryCondition, 0)
+	var exportParams types.ExportParams
 
-	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).Validate()
+	bind := h.builders.NewHandleBinding(c, &queryParams, &dqlQuery).BindQuery().BindDSL().BindPagination(&h.mid_.Pagination).BindExport(&exportParams).Validate()
 
 	if err := bind.GetError(); err != nil {
 		if msgs := bind.GetErrorMessages(); len(msgs) > 0 {
@@ -134,11 +151,22 @@ func (h *usersRestHandler) GetKYC(c *gin.Context) {
 	}
 
 	queryParams.DSLSearchOperator = &dqlQuery
+	masker := masking.FromContext(c, h.config.MaskingRules)
+
+	if exportParams.Format != "" {
+		export.Stream(c, h.config.Logger, &exportParams, "kyc", func(handle database.RowHandler) (int, appErrors.Error) {
+			return h.StreamService.ExportKYC(&exportParams, &queryParams, masker.Rows(handle))
+		})
+		return
+	}
+
 	paginatedResults, err := h.StreamService.GetKYC(&h.mid_.Pagination, &queryParams)
 	if err != nil {
 		utils.IfErrorExistReturnWithError(c, err)
 		return
 	}
 
+	masker.Apply(paginatedResults.Data)
+
 	c.JSON(http.StatusOK, paginatedResults)
 }
@@ -0,0 +1,241 @@
+package masking
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Masker hides the fields a "partial allowed" policy decision lists. Field
+// names match regardless of their form, so IDCardNumber, id_card_number and
+// the json name of a struct field all refer to the same field. A nil Masker
+// leaves everything untouched.
+type Masker struct {
+	fields map[string]struct{}
+	rules  *config.MaskingRules
+}
+
+func New(fields []string, rules *config.MaskingRules) *Masker {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	masker := &Masker{fields: make(map[string]struct{}, len(fields)), rules: rules}
+	for _, field := range fields {
+		masker.fields[normalize(field)] = struct{}{}
+	}
+
+	return masker
+}
+
+// FromContext builds the masker of the policy decision enforced on the
+// request, or nil when the caller is not restricted.
+func FromContext(c *gin.Context, rules *config.MaskingRules) *Masker {
+	value, exists := c.Get(string(types.ContextPolicyDecisionKey))
+	if !exists {
+		return nil
+	}
+
+	decision, ok := value.(types.PolicyDecision)
+	if !ok || decision.Allowance != types.AllowancePartialAllowed {
+		return nil
+	}
+
+	return New(decision.MaskedFields, rules)
+}
+
+func (m *Masker) Hides(name string) bool {
+	if m == nil {
+		return false
+	}
+	_, hidden := m.fields[normalize(name)]
+	return hidden
+}
+
+// Hidden returns the given columns that are masked.
+func (m *Masker) Hidden(columns ...string) []string {
+	var hidden []string
+	for _, column := range columns {
+		if m.Hides(column) {
+			hidden = append(hidden, column)
+		}
+	}
+	return hidden
+}
+
+// HiddenDetails lists the given columns that are masked as error details,
+// nil when none is.
+func (m *Masker) HiddenDetails(columns ...string) map[string]string {
+	hidden := m.Hidden(columns...)
+	if len(hidden) == 0 {
+		return nil
+	}
+
+	details := make(map[string]string, len(hidden))
+	for _, column := range hidden {
+		details[column] = "field is masked by policy"
+	}
+	return details
+}
+
+// Apply masks data in place. It walks structs, slices, maps and pointers, so
+// it works on repository results as they are returned.
+func (m *Masker) Apply(data interface{}) {
+	if m == nil || data == nil {
+		return
+	}
+	m.apply(reflect.ValueOf(data))
+}
+
+// Rows wraps an export row handler so streamed rows are masked too.
+func (m *Masker) Rows(handle database.RowHandler) database.RowHandler {
+	if m == nil {
+		return handle
+	}
+	return &maskedRows{masker: m, handle: handle}
+}
+
+func (m *Masker) apply(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !value.IsNil() {
+			m.apply(value.Elem())
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			m.apply(value.Index(i))
+		}
+
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range value.MapKeys() {
+			if m.Hides(key.String()) {
+				masked := reflect.ValueOf(m.maskValue(value.MapIndex(key).Interface()))
+				if !masked.IsValid() {
+					masked = reflect.Zero(value.Type().Elem())
+				}
+				value.SetMapIndex(key, masked)
+				continue
+			}
+			m.apply(value.MapIndex(key))
+		}
+
+	case reflect.Struct:
+		structType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := structType.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			if !field.Anonymous && m.hidesField(field) {
+				m.maskField(value.Field(i))
+				continue
+			}
+			m.apply(value.Field(i))
+		}
+	}
+}
+
+func (m *Masker) hidesField(field reflect.StructField) bool {
+	if m.Hides(field.Name) {
+		return true
+	}
+	for _, tag := range []string{"json", "form"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" && m.Hides(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Masker) maskField(field reflect.Value) {
+	if !field.CanSet() {
+		return
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(m.maskString(field.String()))
+	case field.Kind() == reflect.Ptr && !field.IsNil() && field.Elem().Kind() == reflect.String:
+		field.Elem().SetString(m.maskString(field.Elem().String()))
+	default:
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
+
+func (m *Masker) maskValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return m.maskString(v)
+	case []byte:
+		return m.maskString(string(v))
+	case *string:
+		if v == nil {
+			return v
+		}
+		masked := m.maskString(*v)
+		return &masked
+	default:
+		return nil
+	}
+}
+
+// maskString keeps a short suffix of long values, which is usually enough to
+// tell two identity numbers apart, and hides short values entirely.
+func (m *Masker) maskString(value string) string {
+	if value == "" {
+		return value
+	}
+
+	maskChar, visible, minLength := "*", 0, 0
+	if m.rules != nil {
+		if m.rules.MaskChar != "" {
+			maskChar = m.rules.MaskChar
+		}
+		visible, minLength = m.rules.VisibleSuffix, m.rules.MinLength
+	}
+
+	runes := []rune(value)
+	if visible <= 0 || len(runes) < minLength || len(runes) <= visible {
+		return strings.Repeat(maskChar, len(runes))
+	}
+
+	return strings.Repeat(maskChar, len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+type maskedRows struct {
+	masker *Masker
+	handle database.RowHandler
+	masked []bool
+}
+
+func (r *maskedRows) Columns(columns []string) error {
+	r.masked = make([]bool, len(columns))
+	for i, column := range columns {
+		r.masked[i] = r.masker.Hides(column)
+	}
+	return r.handle.Columns(columns)
+}
+
+func (r *maskedRows) Row(values []interface{}) error {
+	for i := range values {
+		if i < len(r.masked) && r.masked[i] {
+			values[i] = r.masker.maskValue(values[i])
+		}
+	}
+	return r.handle.Row(values)
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.TrimSpace(name)))
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	administratorLogsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const defaultAuditBodySize = 16384
+
+type AuditMiddleware interface {
+	Audit() gin.HandlerFunc
+}
+
+type auditMiddleware struct {
+	config      *config.Config
+	logger      *zap.Logger
+	adminLogger administratorLogsService.AdminLogsService
+}
+
+func NewAuditMiddleware(config *config.Config, adminLogger administratorLogsService.AdminLogsService) AuditMiddleware {
+	return &auditMiddleware{config: config, logger: config.Logger, adminLogger: adminLogger}
+}
+
+// Audit logs the request of the signed in admin once it has been handled, so
+// the entry carries the final status, the latency and the error, whichever
+// middleware or handler ended the request. It belongs right after the guard.
+func (a *auditMiddleware) Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString(string(types.ContextUserIDKey))
+		if userID == "" {
+			c.Next()
+			return
+		}
+
+		record := &types.AuditRecord{StartedAt: time.Now()}
+		record.RequestBody = a.readBody(c)
+		c.Set(string(types.ContextAuditRecordKey), record)
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer, limit: a.maxBodySize()}
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusBadRequest && record.ErrorMessage == "" {
+			record.ErrorMessage = errorMessageOf(writer.body.Bytes())
+		}
+		if record.ErrorMessage == "" && len(c.Errors) > 0 {
+			record.ErrorMessage = c.Errors.Last().Error()
+		}
+
+		logLevel := 0
+		if c.IsAborted() || status >= http.StatusBadRequest {
+			logLevel = 1
+		}
+
+		a.adminLogger.LogAction(c, c.GetString(string(types.ContextRoleKey)), userID, logLevel)
+	}
+}
+
+// AuditResource names the resource type of a route, the ID is taken from its
+// first path parameter until the handler tells otherwise.
+func AuditResource(resourceType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resourceID := ""
+		if len(c.Params) > 0 {
+			resourceID = c.Params[0].Value
+		}
+
+		utils.SetAuditResource(c, resourceType, resourceID)
+		c.Next()
+	}
+}
+
+// readBody returns the JSON body and puts it back for the handler. Other
+// content types and bodies over the limit are not recorded. The body is
+// redacted when the entry is stored.
+func (a *auditMiddleware) readBody(c *gin.Context) string {
+	if c.Request.Body == nil || c.ContentType() != gin.MIMEJSON {
+		return ""
+	}
+
+	limit := a.maxBodySize()
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil || len(body) > limit {
+		return ""
+	}
+
+	return string(body)
+}
+
+func (a *auditMiddleware) maxBodySize() int {
+	if a.config.AuditRules.MaxBodySize > 0 {
+		return a.config.AuditRules.MaxBodySize
+	}
+	return defaultAuditBodySize
+}
+
+// errorMessageOf reads the message of an error response.
+func errorMessageOf(body []byte) string {
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return string(body)
+	}
+
+	for _, key := range []string{"error", "message"} {
+		if message, ok := response[key].(string); ok && message != "" {
+			return message
+		}
+	}
+
+	return ""
+}
+
+// auditResponseWriter keeps the beginning of error responses.
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(data string) (int, error) {
+	w.capture([]byte(data))
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (w *auditResponseWriter) capture(data []byte) {
+	if w.Status() < http.StatusBadRequest {
+		return
+	}
+
+	if room := w.limit - w.body.Len(); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+		w.body.Write(data)
+	}
+}
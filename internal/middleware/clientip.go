@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIP resolves the client address once, for utils.GetClientIP to hand
+// out to the allowlist, the rate limiter and the admin logs.
+func ClientIP(resolver *utils.ClientIPResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(types.ContextClientIPKey), resolver.Resolve(c.Request))
+		c.Next()
+	}
+}
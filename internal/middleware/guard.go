@@ -10,7 +10,6 @@ import (
 	administratorAuthRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -22,7 +21,6 @@ type GuardMiddleware interface {
 type guardMiddleware struct {
 	config         *config.Config
 	logger         *zap.Logger
-	jwtSectret     string
 	adminAuthRepo  administratorAuthRepo.AdminAuthRepository
 	contextMessage contextMessageService.ContextMessages
 }
@@ -31,7 +29,6 @@ func NewGuardMiddleware(config *config.Config, authRepo administratorAuthRepo.Ad
 	return &guardMiddleware{
 		config:         config,
 		logger:         config.Logger,
-		jwtSectret:     config.SecretJWTToken,
 		adminAuthRepo:  authRepo,
 		contextMessage: contextMessages,
 	}
@@ -39,6 +36,15 @@ func NewGuardMiddleware(config *config.Config, authRepo administratorAuthRepo.Ad
 
 func (g *guardMiddleware) Guard() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// approved requests are replayed in-process on behalf of the requester
+		if replay, ok := types.ApprovalReplayFrom(c.Request.Context()); ok {
+			c.Set(string(types.ContextUserIDKey), replay.UserID)
+			c.Set(string(types.ContextRoleKey), replay.RoleID)
+			c.Set(string(types.ContextUserAgent), replay.UserAgent)
+			c.Next()
+			return
+		}
+
 		authHeader := c.Request.Header.Get("Authorization")
 		if authHeader == "" {
 			g.logger.Debug("No auth token provided")
@@ -55,45 +61,37 @@ func (g *guardMiddleware) Guard() gin.HandlerFunc {
 
 		tokenString := splitToken[1]
 
-		token, err := jwt.ParseWithClaims(tokenString, &types.AccessTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(g.jwtSectret), nil
-		})
-
+		claims, err := g.adminAuthRepo.ParseAccessToken(tokenString)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		if claims, ok := token.Claims.(*types.AccessTokenClaims); ok && token.Valid {
-
-			verified := g.adminAuthRepo.IsAccessTokenValidAndExist(claims.UserID, tokenString, claims.UserAgent)
-
-			if !verified {
+		verified := g.adminAuthRepo.IsAccessTokenValidAndExist(claims, tokenString)
 
-				// Check if there is a contextual message as a reason
-				tokenMessage, err := g.contextMessage.GetContextualMessage(claims.UserID, "token", true)
-				if err != nil {
-					g.logger.Error("Error getting contextual message for why user is not authenticated", zap.Error(err))
-				}
+		if !verified {
 
-				if tokenMessage.Message != "" {
-					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": tokenMessage.Message})
-				} else {
-					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-				}
-
-				return
+			// Check if there is a contextual message as a reason
+			tokenMessage, err := g.contextMessage.GetContextualMessage(claims.UserID, "token", true)
+			if err != nil {
+				g.logger.Error("Error getting contextual message for why user is not authenticated", zap.Error(err))
 			}
 
-			g.adminAuthRepo.HandleUserActivity(claims.UserID)
+			if tokenMessage.Message != "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": tokenMessage.Message})
+			} else {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			}
 
-			c.Set(string(types.ContextUserIDKey), claims.UserID)
-			c.Set(string(types.ContextRoleKey), claims.RoleID)
-			c.Set(string(types.ContextUserAgent), claims.UserAgent)
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
+
+		c.Set(string(types.ContextUserIDKey), claims.UserID)
+		c.Set(string(types.ContextRoleKey), claims.RoleID)
+		c.Set(string(types.ContextUserAgent), claims.UserAgent)
+		c.Set(string(types.ContextSessionID), claims.SessionID)
+		c.Set(string(types.ContextPasswordChangeRequiredKey), claims.PasswordChangeRequired)
+
 		c.Next()
 	}
 }
@@ -1,11 +1,12 @@
 package middleware
 
 import (
-	"net"
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/config"
-	administratorUsersService "github.com/denizumutdereli/stream-admin/internal/service/administrator/users"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/allowlist"
+	administratorLogsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/denizumutdereli/stream-admin/internal/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -13,82 +14,62 @@ import (
 
 type IpController interface {
 	IPAllowedMiddleware() gin.HandlerFunc
-	IsRangeOfIPAllowed() gin.HandlerFunc
+	UserIPAllowedMiddleware() gin.HandlerFunc
 }
 
 type ipController struct {
-	config             *config.Config
-	logger             *zap.Logger
-	allowedRanges      []string
-	specificAllowedIPs []string
-	adminUsersService  administratorUsersService.AdminUserService
+	config           *config.Config
+	logger           *zap.Logger
+	allowlistService allowlist.AdminAllowlistService
+	adminLogger      administratorLogsService.AdminLogsService
 }
 
-func NewIPController(config *config.Config, administratorUsersService administratorUsersService.AdminUserService) IpController {
-
-	allowedIPs := make(map[string]struct{})
-	for _, ip := range config.AllowedSpecificIps {
-		allowedIPs[ip] = struct{}{}
-	}
-
+func NewIPController(config *config.Config, allowlistService allowlist.AdminAllowlistService, adminLogger administratorLogsService.AdminLogsService) IpController {
 	return &ipController{
-		config:             config,
-		logger:             config.Logger,
-		allowedRanges:      config.AllowedIpRanges,
-		specificAllowedIPs: config.AllowedSpecificIps,
-		adminUsersService:  administratorUsersService,
+		config:           config,
+		logger:           config.Logger,
+		allowlistService: allowlistService,
+		adminLogger:      adminLogger,
 	}
 }
 
+// IPAllowedMiddleware lets in the addresses of any allowlist range. Ranges
+// given to a role or an admin are narrowed down by UserIPAllowedMiddleware
+// once the admin is signed in.
 func (i *ipController) IPAllowedMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userVpnAddrs, err := i.adminUsersService.GetAdminActiveUsersVPNAddresses()
-		if err != nil {
-			i.logger.Error("Error getting admin users vpn addresses", zap.Error(err))
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Internal server error"})
-			return
-		}
-
-		userVpnAddrs = append(userVpnAddrs, "127.0.0.1")
 		clientIP := utils.GetClientIP(c)
 
-		for _, vpn := range userVpnAddrs {
-			if vpn == clientIP {
-				c.Next()
-				return
-			}
+		if i.allowlistService.IsAllowed(clientIP) {
+			c.Next()
+			return
 		}
 
-		i.logger.Debug("IP address is not allowed", zap.String("clientIP", clientIP))
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Your IP address is not permitted to access this service."})
+		i.deny(c, clientIP, "", "")
 	}
 }
 
-func (i *ipController) IsRangeOfIPAllowed() gin.HandlerFunc {
+// UserIPAllowedMiddleware checks the address against the ranges of the signed
+// in admin, so it belongs after the guard.
+func (i *ipController) UserIPAllowedMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientIP := utils.GetClientIP(c)
-		parsedIP := net.ParseIP(clientIP)
+		userID := c.GetString(string(types.ContextUserIDKey))
+		roleID := c.GetString(string(types.ContextRoleKey))
 
-		if parsedIP == nil {
-			i.logger.Debug("Invalid IP address provided in checking subset of allowed ranges")
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Your IP address is not permitted to access this range of service."})
+		if i.allowlistService.IsAllowedFor(clientIP, userID, roleID) {
+			c.Next()
 			return
 		}
 
-		for _, allowedRange := range i.allowedRanges {
-			_, subnet, err := net.ParseCIDR(allowedRange)
-			if err != nil {
-				i.logger.Error("error parsing CIDR", zap.Error(err))
-				continue
-			}
-
-			if subnet.Contains(parsedIP) {
-				c.Next()
-				return
-			}
-
-		}
-
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Your IP address is not permitted to access this range of service."})
+		i.deny(c, clientIP, userID, roleID)
 	}
 }
+
+// deny records the refused address in the admin logs as well, the request
+// never reaches the audit of its route.
+func (i *ipController) deny(c *gin.Context, clientIP, userID, roleID string) {
+	i.logger.Info("IP address is not allowed", zap.String("clientIP", clientIP), zap.String("user", userID))
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Your IP address is not permitted to access this service."})
+	i.adminLogger.LogAction(c, roleID, userID, 1)
+}
@@ -1,49 +1,140 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 )
 
+const (
+	rateLimitKeyPrefix = "rate_limit"
+	globalRateLimit    = "global"
+
+	defaultPerRequestLimit = 50
+)
+
+var rateLimitRejections = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "admin_rate_limit_rejections_total",
+		Help: "Number of requests rejected by the rate limiter",
+	}, []string{"group", "scope"})
+
+// gcraScript is a generic cell rate limiter. The theoretical arrival time of
+// the next request is kept per key and the redis clock is used, so every
+// replica shares the same limit. It returns allowed, remaining, the
+// milliseconds to wait before retrying and until the limit is fully reset.
+var gcraScript = redis.NewScript(`
+if redis.replicate_commands then redis.replicate_commands() end
+
+local key = KEYS[1]
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+
+local clock = redis.call("TIME")
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+	tat = now
+end
+
+local next_tat = tat + emission
+local diff = now - (next_tat - tolerance)
+
+if diff < 0 then
+	return {0, 0, -diff, tat - now}
+end
+
+redis.call("SET", key, next_tat, "PX", next_tat - now)
+
+return {1, math.floor(diff / emission), 0, next_tat - now}
+`)
+
+// RateLimit allows Requests per Period with at most Burst of them at once.
+// A zero Period or Burst falls back to RATE_LIMIT_RULES.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
 type RateLimiter interface {
 	RateLimitMiddleware() gin.HandlerFunc
+	Limit(group string, limit RateLimit) gin.HandlerFunc
 }
 
 type rateLimiter struct {
-	config        *config.Config
-	logger        *zap.Logger
-	globalLimiter *rate.Limiter
+	config      *config.Config
+	logger      *zap.Logger
+	redisClient *transport.RedisManager
 }
 
-func NewRateLimiter(config *config.Config) RateLimiter {
-	var perRequestLimit int
-	var err error
-
-	if perRequestLimit, err = strconv.Atoi(config.PerRequestLimit); err != nil {
-		perRequestLimit = 50
-	}
-
-	globalLimiter := rate.NewLimiter(rate.Limit(float64(perRequestLimit)/60.0), perRequestLimit) // n requests per minute
-
+func NewRateLimiter(config *config.Config, redisClient *transport.RedisManager) RateLimiter {
 	return &rateLimiter{
-		config:        config,
-		logger:        config.Logger,
-		globalLimiter: globalLimiter,
+		config:      config,
+		logger:      config.Logger,
+		redisClient: redisClient,
 	}
 }
 
+// RateLimitMiddleware applies PER_REQUEST_LIMIT to every request of an ip
+// address, before anyone is authenticated.
 func (r *rateLimiter) RateLimitMiddleware() gin.HandlerFunc {
+	perRequestLimit, err := strconv.Atoi(r.config.PerRequestLimit)
+	if err != nil || perRequestLimit <= 0 {
+		perRequestLimit = defaultPerRequestLimit
+	}
+
+	return r.Limit(globalRateLimit, RateLimit{Requests: perRequestLimit, Period: time.Minute})
+}
 
-	r.logger.Debug("RateLimitMiddleware..................") // TODO: debug
+// Limit applies the limit to the requests of a group, keyed by the signed in
+// user or by the ip address otherwise.
+func (r *rateLimiter) Limit(group string, limit RateLimit) gin.HandlerFunc {
+	limit = r.withDefaults(limit)
+
+	emission := limit.Period.Milliseconds() / int64(limit.Requests)
+	if emission <= 0 {
+		emission = 1
+	}
+	tolerance := emission * int64(limit.Burst)
 
 	return func(c *gin.Context) {
+		scope, subject := "ip", utils.GetClientIP(c)
+		if userID := c.GetString(string(types.ContextUserIDKey)); userID != "" {
+			scope, subject = "user", userID
+		}
 
-		if !r.globalLimiter.Allow() {
+		key := rateLimitKeyPrefix + ":" + group + ":" + scope + ":" + subject
+
+		result, err := gcraScript.Run(c.Request.Context(), r.redisClient.Client, []string{key}, emission, tolerance).Int64Slice()
+		if err != nil || len(result) != 4 {
+			// an unavailable limiter must not lock the admins out
+			r.logger.Error("rate limiter is unavailable", zap.String("group", group), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		allowed, remaining, retryAfter, resetAfter := result[0] == 1, result[1], result[2], result[3]
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(millisToSeconds(resetAfter), 10))
+
+		if !allowed {
+			rateLimitRejections.WithLabelValues(group, scope).Inc()
+			c.Header("Retry-After", strconv.FormatInt(millisToSeconds(retryAfter), 10))
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "You have exceeded the request limit."})
 			return
 		}
@@ -51,3 +142,29 @@ func (r *rateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func (r *rateLimiter) withDefaults(limit RateLimit) RateLimit {
+	if limit.Requests <= 0 {
+		limit.Requests = defaultPerRequestLimit
+	}
+
+	if limit.Period <= 0 {
+		limit.Period = time.Duration(r.config.RateLimitRules.PeriodInSeconds) * time.Second
+		if limit.Period <= 0 {
+			limit.Period = time.Minute
+		}
+	}
+
+	if limit.Burst <= 0 {
+		limit.Burst = r.config.RateLimitRules.Burst
+	}
+	if limit.Burst <= 0 || limit.Burst > limit.Requests {
+		limit.Burst = limit.Requests
+	}
+
+	return limit
+}
+
+func millisToSeconds(millis int64) int64 {
+	return int64(math.Ceil(float64(millis) / 1000))
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newTestLimiter(t *testing.T) (*rateLimiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	cnf := &config.Config{
+		Logger:                zap.NewNop(),
+		DefaultTickerInterval: 1000,
+		RateLimitRules:        &config.RateLimitRules{PeriodInSeconds: 30, Burst: 5},
+	}
+
+	redis, err := transport.NewRedisManager("redis://"+server.Addr(), cnf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewRateLimiter(cnf, redis).(*rateLimiter), server
+}
+
+func newLimitedEngine(limiter *rateLimiter, limit RateLimit) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.GET("/limited", func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set(string(types.ContextUserIDKey), userID)
+		}
+		c.Next()
+	}, limiter.Limit("test", limit), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return engine
+}
+
+func TestLimit(t *testing.T) {
+	limiter, server := newTestLimiter(t)
+	engine := newLimitedEngine(limiter, RateLimit{Requests: 3, Period: 3 * time.Second, Burst: 2})
+
+	now := time.Unix(1700000000, 0)
+	server.SetTime(now)
+
+	steps := []struct {
+		name          string
+		advance       time.Duration
+		ip            string
+		user          string
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+		wantRetry     string
+	}{
+		{name: "first request of the burst", ip: "203.0.113.7", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+		{name: "last request of the burst", ip: "203.0.113.7", wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "2"},
+		{name: "exhausted burst is rejected", ip: "203.0.113.7", wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "2", wantRetry: "1"},
+		{name: "other ip has its own limit", ip: "198.51.100.1", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+		{name: "signed in user is keyed by user", ip: "203.0.113.7", user: "user-1", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+		{name: "one emission later a request is allowed", advance: time.Second, ip: "203.0.113.7", wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "2"},
+		{name: "and the next one is rejected again", ip: "203.0.113.7", wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "2", wantRetry: "1"},
+		{name: "idle key is fully reset", advance: 10 * time.Second, ip: "203.0.113.7", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "1"},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		server.SetTime(now)
+
+		request := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		request.RemoteAddr = step.ip + ":5000"
+		if step.user != "" {
+			request.Header.Set("X-Test-User", step.user)
+		}
+
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+
+		if recorder.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", step.name, recorder.Code, step.wantStatus)
+		}
+
+		header := recorder.Header()
+		if got := header.Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("%s: X-RateLimit-Limit = %q, want 3", step.name, got)
+		}
+		if got := header.Get("X-RateLimit-Remaining"); got != step.wantRemaining {
+			t.Errorf("%s: X-RateLimit-Remaining = %q, want %q", step.name, got, step.wantRemaining)
+		}
+		if got := header.Get("X-RateLimit-Reset"); got != step.wantReset {
+			t.Errorf("%s: X-RateLimit-Reset = %q, want %q", step.name, got, step.wantReset)
+		}
+		if got := header.Get("Retry-After"); got != step.wantRetry {
+			t.Errorf("%s: Retry-After = %q, want %q", step.name, got, step.wantRetry)
+		}
+	}
+}
+
+func TestLimitFailsOpen(t *testing.T) {
+	limiter, server := newTestLimiter(t)
+	engine := newLimitedEngine(limiter, RateLimit{Requests: 1, Period: time.Minute})
+
+	server.Close()
+
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/limited", nil))
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d with the limiter down, want %d", i, recorder.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	limiter := &rateLimiter{config: &config.Config{
+		RateLimitRules: &config.RateLimitRules{PeriodInSeconds: 30, Burst: 5},
+	}}
+
+	tests := []struct {
+		name  string
+		limit RateLimit
+		want  RateLimit
+	}{
+		{
+			name:  "explicit limit is kept",
+			limit: RateLimit{Requests: 10, Period: time.Second, Burst: 2},
+			want:  RateLimit{Requests: 10, Period: time.Second, Burst: 2},
+		},
+		{
+			name:  "period and burst come from the rules",
+			limit: RateLimit{Requests: 10},
+			want:  RateLimit{Requests: 10, Period: 30 * time.Second, Burst: 5},
+		},
+		{
+			name:  "burst is capped by requests",
+			limit: RateLimit{Requests: 3, Period: time.Second, Burst: 10},
+			want:  RateLimit{Requests: 3, Period: time.Second, Burst: 3},
+		},
+		{
+			name:  "rules burst is capped by requests",
+			limit: RateLimit{Requests: 2, Period: time.Second},
+			want:  RateLimit{Requests: 2, Period: time.Second, Burst: 2},
+		},
+		{
+			name:  "missing requests use the per request limit",
+			limit: RateLimit{},
+			want:  RateLimit{Requests: defaultPerRequestLimit, Period: 30 * time.Second, Burst: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limiter.withDefaults(tt.limit); got != tt.want {
+				t.Errorf("withDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	limiter.config.RateLimitRules = &config.RateLimitRules{}
+	if got := limiter.withDefaults(RateLimit{Requests: 4}); got != (RateLimit{Requests: 4, Period: time.Minute, Burst: 4}) {
+		t.Errorf("withDefaults() without rules = %+v", got)
+	}
+}
+
+func TestMillisToSeconds(t *testing.T) {
+	tests := []struct {
+		millis int64
+		want   int64
+	}{
+		{0, 0},
+		{1, 1},
+		{999, 1},
+		{1000, 1},
+		{1001, 2},
+		{59999, 60},
+	}
+
+	for _, tt := range tests {
+		if got := millisToSeconds(tt.millis); got != tt.want {
+			t.Errorf("millisToSeconds(%d) = %d, want %d", tt.millis, got, tt.want)
+		}
+	}
+}
@@ -2,8 +2,12 @@ package middleware
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/masking"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +15,10 @@ import (
 const (
 	DefaultPage     = 1
 	DefaultPageSize = 10
+
+	CursorQueryKey = "cursor"
+	PagingQueryKey = "paging"
+	CountQueryKey  = "count"
 )
 
 func Pagination() gin.HandlerFunc {
@@ -28,11 +36,70 @@ func Pagination() gin.HandlerFunc {
 		sortBy := c.DefaultQuery("sortBy", "")
 		sortOrder := c.DefaultQuery("sortOrder", "")
 
+		sort, err := builders.ParseSort(sortBy, sortOrder)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		paginationParams := types.PaginationParams{
 			Page:      page,
 			Limit:     pageSize,
 			SortBy:    sortBy,
 			SortOrder: sortOrder,
+			Sort:      sort,
+			CountMode: database.CountExact,
+		}
+
+		// cursor mode starts with ?paging=cursor (or an empty ?cursor=) and
+		// continues with the opaque next_cursor/prev_cursor tokens, which carry
+		// the sort they were issued for
+		cursor, cursorMode := c.GetQuery(CursorQueryKey)
+		if cursorMode || c.Query(PagingQueryKey) == "cursor" {
+			if len(sort) > 1 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Cursor pagination supports a single sort column"})
+				return
+			}
+
+			paginationParams.Cursor = &database.Cursor{SortOrder: "asc"}
+			if len(sort) == 1 {
+				paginationParams.Cursor = &database.Cursor{SortBy: sort[0].Column, SortOrder: sort[0].Order}
+			}
+			paginationParams.CountMode = database.CountApproximate
+
+			if cursor != "" {
+				decoded, err := database.DecodeCursor(cursor)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination cursor"})
+					return
+				}
+				paginationParams.Cursor = decoded
+				paginationParams.SortBy = decoded.SortBy
+				paginationParams.SortOrder = decoded.SortOrder
+				paginationParams.Sort = nil
+				if decoded.SortBy != "" {
+					paginationParams.Sort = []types.SortField{{Column: decoded.SortBy, Order: decoded.SortOrder}}
+				}
+			}
+		}
+
+		// sorting by a masked column orders the rows by the hidden values
+		masker := masking.FromContext(c, nil)
+		if details := masker.HiddenDetails(sortColumns(paginationParams.Sort)...); details != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Masked fields cannot be sorted by", "details": details})
+			return
+		}
+		if masker != nil && paginationParams.Cursor != nil {
+			paginationParams.Cursor.Masked = masker.Hides
+		}
+
+		if countParam := c.Query(CountQueryKey); countParam != "" {
+			countMode, ok := database.ParseCountMode(countParam)
+			if !ok {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid count mode, expected exact, approx or none"})
+				return
+			}
+			paginationParams.CountMode = countMode
 		}
 
 		fmt.Println(paginationParams)
@@ -42,6 +109,14 @@ func Pagination() gin.HandlerFunc {
 	}
 }
 
+func sortColumns(sort []types.SortField) []string {
+	columns := make([]string, 0, len(sort))
+	for _, field := range sort {
+		columns = append(columns, field.Column)
+	}
+	return columns
+}
+
 func SanitizePaginationParams(params *types.PaginationParams) *types.PaginationParams {
 	if params.Page <= 0 {
 		params.Page = 1
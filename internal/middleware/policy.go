@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/approvals"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/auth"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const StepUpTokenHeader = "X-Step-Up-Token"
+
+type PolicyMiddleware interface {
+	Enforce(source types.PolicySource, action types.PolicyAction) gin.HandlerFunc
+	RequireStepUp() gin.HandlerFunc
+}
+
+type policyMiddleware struct {
+	config           *config.Config
+	logger           *zap.Logger
+	policyService    policy.AdminPolicyService
+	authService      auth.AdminAuthService
+	approvalsService approvals.AdminApprovalsService
+}
+
+func NewPolicyMiddleware(config *config.Config, policyService policy.AdminPolicyService, authService auth.AdminAuthService, approvalsService approvals.AdminApprovalsService) PolicyMiddleware {
+	return &policyMiddleware{config: config, logger: config.Logger, policyService: policyService, authService: authService, approvalsService: approvalsService}
+}
+
+// Enforce resolves the caller's role policies for the route's (source, action)
+// pair and applies the resulting allowance. The decision is left in the
+// context for handlers that shape their response by it.
+func (p *policyMiddleware) Enforce(source types.PolicySource, action types.PolicyAction) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString(string(types.ContextUserIDKey))
+		roleID := c.GetString(string(types.ContextRoleKey))
+
+		if userID == "" || roleID == "" {
+			p.logger.Error("policy enforcement without an authenticated user", zap.String("path", c.FullPath()))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		decision, appErr := p.policyService.ResolvePolicy(c.Request.Context(), roleID, source, action)
+		if appErr != nil {
+			p.deny(c, userID, appErr.Error())
+			return
+		}
+
+		c.Set(string(types.ContextPolicyDecisionKey), decision)
+
+		switch decision.Allowance {
+		case types.AllowanceAllowed, types.AllowancePartialAllowed:
+			c.Next()
+
+		case types.AllowanceRequireOTP:
+			if !p.stepUp(c, userID) {
+				return
+			}
+			c.Next()
+
+		case types.AllowanceAskPermission:
+			if replay, ok := types.ApprovalReplayFrom(c.Request.Context()); ok && replay.Source == source && replay.Action == action {
+				c.Next()
+				return
+			}
+			p.requestApproval(c, roleID, userID, source, action)
+
+		default:
+			p.deny(c, userID, "policy does not allow the action")
+		}
+	}
+}
+
+// RequireStepUp guards sensitive routes with a second factor whatever the
+// caller's policies say, super admins included. The requester of an approved
+// request never answered a challenge, its replay only passes when the approver
+// passed step-up on the approve call.
+func (p *policyMiddleware) RequireStepUp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString(string(types.ContextUserIDKey))
+		if userID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		if c.GetBool(string(types.ContextStepUpVerifiedKey)) {
+			c.Next()
+			return
+		}
+
+		if replay, ok := types.ApprovalReplayFrom(c.Request.Context()); ok {
+			if !replay.StepUpVerified {
+				p.deny(c, userID, "approver did not pass step-up for the approved request")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if p.stepUp(c, userID) {
+			c.Next()
+		}
+	}
+}
+
+// stepUp challenges the request with a one time code when it carries no
+// step-up token. The client answers the challenge on POST /auth/stepup and
+// retries the request with the token it gets in the X-Step-Up-Token header.
+func (p *policyMiddleware) stepUp(c *gin.Context, userID string) bool {
+	ctx := c.Request.Context()
+
+	token := c.GetHeader(StepUpTokenHeader)
+	if token != "" {
+		if appErr := p.authService.ConsumeStepUpToken(ctx, userID, token, c.Request.Method, c.Request.URL.Path); appErr != nil {
+			utils.IfErrorExistReturnWithError(c, appErr)
+			c.Abort()
+			return false
+		}
+		c.Set(string(types.ContextStepUpVerifiedKey), true)
+		return true
+	}
+
+	challenge, appErr := p.authService.InitiateStepUp(ctx, userID, c.Request.Method, c.Request.URL.Path)
+	if appErr != nil {
+		utils.IfErrorExistReturnWithError(c, appErr)
+		c.Abort()
+		return false
+	}
+
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error":        "Step-up verification required",
+		"challenge_id": challenge.ChallengeID,
+		"factor":       challenge.Factor,
+		"expires_at":   challenge.ExpiresAt,
+		"header":       StepUpTokenHeader,
+	})
+	return false
+}
+
+// requestApproval holds the request back until another administrator approves
+// it. The body is kept as sent so that the approval can replay it.
+func (p *policyMiddleware) requestApproval(c *gin.Context, roleID, userID string, source types.PolicySource, action types.PolicyAction) {
+	maxBodySize := int64(p.config.ApprovalRules.MaxBodySize)
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize+1))
+	if err != nil {
+		utils.IfErrorExistReturnWithErrorExplanation(c, err, "Request body could not be read", http.StatusBadRequest)
+		c.Abort()
+		return
+	}
+
+	if int64(len(body)) > maxBodySize {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large to be held for approval"})
+		return
+	}
+
+	approval, appErr := p.approvalsService.RequestApproval(c.Request.Context(), &models.AdministratorApproval{
+		RequesterID:   userID,
+		RequesterRole: roleID,
+		Source:        string(source),
+		Action:        string(action),
+		Method:        c.Request.Method,
+		Path:          c.Request.URL.Path,
+		Query:         c.Request.URL.RawQuery,
+		Body:          string(body),
+		ContentType:   c.ContentType(),
+		Ip:            utils.GetClientIP(c),
+		UserAgent:     c.Request.UserAgent(),
+	})
+	if appErr != nil {
+		utils.IfErrorExistReturnWithError(c, appErr)
+		c.Abort()
+		return
+	}
+
+	c.AbortWithStatusJSON(http.StatusAccepted, gin.H{
+		"status":  http.StatusAccepted,
+		"message": "This action requires approval from another administrator",
+		"data":    approval,
+	})
+}
+
+func (p *policyMiddleware) deny(c *gin.Context, userID, reason string) {
+	p.logger.Info("policy denied action", zap.String("user", userID), zap.String("action", c.Request.RequestURI), zap.String("reason", reason))
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have the necessary permissions to access this resource. Contact your system administrator if you believe this is an error."})
+}
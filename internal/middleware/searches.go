@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/masking"
+	reportModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/searches"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const SavedSearchQueryKey = "saved"
+
+type SavedSearchMiddleware interface {
+	ResolveSavedSearch(resource reportModels.SearchResource) gin.HandlerFunc
+}
+
+type savedSearchMiddleware struct {
+	config        *config.Config
+	logger        *zap.Logger
+	searchService searches.AdminSavedSearchService
+}
+
+func NewSavedSearchMiddleware(config *config.Config, searchService searches.AdminSavedSearchService) SavedSearchMiddleware {
+	return &savedSearchMiddleware{config: config, logger: config.Logger, searchService: searchService}
+}
+
+// ResolveSavedSearch loads ?saved=<id> for the resource and hands its DSL to
+// the binding chain. The stored sort applies unless the request sets its own.
+func (s *savedSearchMiddleware) ResolveSavedSearch(resource reportModels.SearchResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		savedParam := c.Query(SavedSearchQueryKey)
+		if savedParam == "" {
+			c.Next()
+			return
+		}
+
+		searchID, err := strconv.ParseUint(savedParam, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+			return
+		}
+
+		if s.searchService == nil {
+			s.logger.Error("saved search service is not registered")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal error"})
+			return
+		}
+
+		userID := c.GetString(string(types.ContextUserIDKey))
+		roleID := c.GetString(string(types.ContextRoleKey))
+
+		savedSearch, appErr := s.searchService.RunSavedSearch(c.Request.Context(), uint(searchID), resource, userID, roleID)
+		if appErr != nil {
+			utils.IfErrorExistReturnWithError(c, appErr)
+			c.Abort()
+			return
+		}
+
+		c.Set(string(types.ContextSavedSearchKey), savedSearch.Filters)
+
+		if pagination, exists := c.Get("pagination"); exists && c.Query("sortBy") == "" && savedSearch.SortBy != "" {
+			if paginationParams, ok := pagination.(types.PaginationParams); ok && !paginationParams.Cursor.HasPosition() {
+				sort, err := builders.ParseSort(savedSearch.SortBy, savedSearch.SortOrder)
+				if err != nil {
+					s.logger.Error("saved search has an invalid sort", zap.Uint("id", savedSearch.ID), zap.Error(err))
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+
+				if details := masking.FromContext(c, nil).HiddenDetails(sortColumns(sort)...); details != nil {
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Masked fields cannot be sorted by", "details": details})
+					return
+				}
+
+				paginationParams.SortBy = savedSearch.SortBy
+				paginationParams.SortOrder = savedSearch.SortOrder
+				paginationParams.Sort = sort
+				if paginationParams.Cursor != nil && len(sort) > 0 {
+					paginationParams.Cursor = &database.Cursor{SortBy: sort[0].Column, SortOrder: sort[0].Order, Masked: paginationParams.Cursor.Masked}
+				}
+				c.Set("pagination", paginationParams)
+			}
+		}
+
+		c.Next()
+	}
+}
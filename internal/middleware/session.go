@@ -9,6 +9,7 @@ import (
 	"time"
 
 	contextMessageService "github.com/denizumutdereli/stream-admin/internal/comm/message"
+	administratorAuthRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
 	administratorUserService "github.com/denizumutdereli/stream-admin/internal/service/administrator/users"
 	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/go-redis/redis/v8"
@@ -22,6 +23,7 @@ import (
 type SessionMiddleware interface {
 	RefreshTimeout() gin.HandlerFunc
 	CheckUserLock() gin.HandlerFunc
+	RequirePasswordChanged() gin.HandlerFunc
 	LimitOTPAttempts() gin.HandlerFunc
 	NotDeleteOwnUser() gin.HandlerFunc
 }
@@ -31,22 +33,54 @@ type sessionMiddleware struct {
 	logger            *zap.Logger
 	redis             *transport.RedisManager
 	adminUsersService administratorUserService.AdminUserService
+	adminAuthRepo     administratorAuthRepo.AdminAuthRepository
 	contextMessage    contextMessageService.ContextMessages
 }
 
-func NewSessionMiddleware(config *config.Config, redis *transport.RedisManager, adminUsersService administratorUserService.AdminUserService, contextMessages contextMessageService.ContextMessages) SessionMiddleware {
+func NewSessionMiddleware(config *config.Config, redis *transport.RedisManager, adminUsersService administratorUserService.AdminUserService, authRepo administratorAuthRepo.AdminAuthRepository, contextMessages contextMessageService.ContextMessages) SessionMiddleware {
 	return &sessionMiddleware{
 		config:            config,
 		logger:            config.Logger,
 		redis:             redis,
 		adminUsersService: adminUsersService,
+		adminAuthRepo:     authRepo,
 		contextMessage:    contextMessages,
 	}
 }
 
+// RefreshTimeout slides the idle timeout of the session on every request and
+// signs the session out when it has been idle for too long.
 func (s *sessionMiddleware) RefreshTimeout() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		s.logger.Debug("session refresh timeout...")
+		// approval replays run on behalf of the requester, not their session
+		if _, ok := types.ApprovalReplayFrom(c.Request.Context()); ok {
+			c.Next()
+			return
+		}
+
+		userID := c.GetString(string(types.ContextUserIDKey))
+		if userID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session required"})
+			return
+		}
+
+		// legacy tokens have no session to keep alive and expire on their own
+		sessionID := c.GetString(string(types.ContextSessionID))
+		if sessionID == "" {
+			c.Next()
+			return
+		}
+
+		err := s.adminAuthRepo.HandleUserActivity(userID, sessionID)
+		if err == administratorAuthRepo.ErrSessionIdle {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
+			s.logger.Error("Error refreshing the idle session timeout", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Error checking session status"})
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -88,6 +122,19 @@ func (s *sessionMiddleware) CheckUserLock() gin.HandlerFunc {
 	}
 }
 
+// RequirePasswordChanged holds back sessions that signed in with a password
+// they have to change first, such as the temporary password of a new admin.
+func (s *sessionMiddleware) RequirePasswordChanged() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(string(types.ContextPasswordChangeRequiredKey)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Password change required"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func (s *sessionMiddleware) LimitOTPAttempts() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
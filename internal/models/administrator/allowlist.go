@@ -0,0 +1,105 @@
+package models
+
+import (
+	"net"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
+	"github.com/go-playground/validator/v10"
+)
+
+type AllowlistScope string
+
+const (
+	AllowlistScopeGlobal AllowlistScope = "global"
+	AllowlistScopeRole   AllowlistScope = "role"
+	AllowlistScopeAdmin  AllowlistScope = "admin"
+)
+
+var validateAllowlist *validator.Validate
+
+// AdministratorIPAllowlist is a range of addresses the panel can be reached
+// from, by everyone, by the admins of a role or by a single admin.
+type AdministratorIPAllowlist struct {
+	EntryID   string         `gorm:"primaryKey;varchar(255)" json:"entry_id"`
+	CIDR      string         `gorm:"not null;type:varchar(64)" json:"cidr"`
+	Scope     AllowlistScope `gorm:"not null;type:varchar(16);index" json:"scope"`
+	ScopeID   string         `gorm:"type:varchar(255);index" json:"scope_id,omitempty"`
+	Reason    string         `gorm:"not null;type:text" json:"reason"`
+	ExpiresAt *time.Time     `gorm:"index" json:"expires_at,omitempty"`
+	CreatedBy string         `gorm:"not null;type:varchar(255)" json:"created_by"`
+	UpdatedBy string         `gorm:"type:varchar(255)" json:"updated_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type AdministratorIPAllowlistRequest struct {
+	EntryID   string         `json:"entry_id"`
+	CIDR      string         `json:"cidr" validate:"required,allowlistCIDR"`
+	Scope     AllowlistScope `json:"scope" validate:"allowlistScope"`
+	ScopeID   string         `json:"scope_id" validate:"required_unless=Scope global,max=255"`
+	Reason    string         `json:"reason" validate:"required,min=3,max=500"`
+	ExpiresAt *time.Time     `json:"expires_at"`
+}
+
+type AdministratorIPAllowlistSearch struct {
+	EntryID       *string         `form:"entry_id"`
+	CIDR          *string         `form:"cidr"`
+	Scope         *AllowlistScope `form:"scope"`
+	ScopeID       *string         `form:"scope_id"`
+	CreatedBy     *string         `form:"created_by"`
+	ExpiresAt     *time.Time      `form:"expires_at"`
+	CreatedAt     *time.Time      `form:"created_at"`
+	dsl.DSLFields `gorm:"-" json:"-"`
+}
+
+func (AllowlistScope) EnumValues() []string {
+	return []string{string(AllowlistScopeGlobal), string(AllowlistScopeRole), string(AllowlistScopeAdmin)}
+}
+
+func (e *AdministratorIPAllowlist) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// NormalizeCIDR turns a single address into its host range and masks the
+// address of a range, so equal ranges are stored the same way.
+func NormalizeCIDR(cidr string) (string, error) {
+	if ip := net.ParseIP(cidr); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+
+	return network.String(), nil
+}
+
+func ValidateAllowlistEntry(request *AdministratorIPAllowlistRequest) error {
+	return validateAllowlist.Struct(request)
+}
+
+func allowlistCIDRValidation(fl validator.FieldLevel) bool {
+	_, err := NormalizeCIDR(fl.Field().String())
+	return err == nil
+}
+
+func allowlistScopeValidation(fl validator.FieldLevel) bool {
+	scope := fl.Field().String()
+	for _, allowed := range AllowlistScope("").EnumValues() {
+		if scope == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	validateAllowlist = validator.New()
+	validateAllowlist.RegisterValidation("allowlistCIDR", allowlistCIDRValidation)
+	validateAllowlist.RegisterValidation("allowlistScope", allowlistScopeValidation)
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
+	"github.com/go-playground/validator/v10"
+)
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+	ApprovalStatusExpired  ApprovalStatus = "expired"
+	ApprovalStatusFailed   ApprovalStatus = "failed"
+)
+
+var validateApprovals *validator.Validate
+
+// AdministratorApproval is an action held back by an "ask permission" policy.
+// The request is kept as it was sent, so it can be replayed on behalf of the
+// requester once another administrator approves it.
+type AdministratorApproval struct {
+	ApprovalID      string         `gorm:"primaryKey;varchar(255)" json:"approval_id"`
+	RequesterID     string         `gorm:"not null;type:varchar(255);index" json:"requester_id"`
+	RequesterRole   string         `gorm:"not null;type:varchar(255)" json:"requester_role"`
+	Source          string         `gorm:"not null;type:varchar(255)" json:"source"`
+	Action          string         `gorm:"not null;type:varchar(255)" json:"action"`
+	Method          string         `gorm:"not null;type:varchar(16)" json:"method"`
+	Path            string         `gorm:"not null;type:text" json:"path"`
+	Query           string         `gorm:"type:text" json:"query,omitempty"`
+	Body            string         `gorm:"type:text" json:"body,omitempty"`
+	ContentType     string         `gorm:"type:varchar(255)" json:"content_type,omitempty"`
+	Ip              string         `gorm:"type:varchar(255)" json:"ip"`
+	UserAgent       string         `gorm:"type:text" json:"user_agent"`
+	Status          ApprovalStatus `gorm:"type:varchar(255);default:'pending';index" json:"status"`
+	ApproverID      string         `gorm:"type:varchar(255)" json:"approver_id,omitempty"`
+	RejectionReason string         `gorm:"type:text" json:"rejection_reason,omitempty"`
+	ResultStatus    int            `gorm:"type:int" json:"result_status,omitempty"`
+	ResultBody      string         `gorm:"type:text" json:"result_body,omitempty"`
+	ExpiresAt       time.Time      `gorm:"index" json:"expires_at"`
+	DecidedAt       *time.Time     `json:"decided_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+type AdministratorApprovalRejection struct {
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type AdministratorApprovalSearch struct {
+	ApprovalID    *string         `form:"approval_id"`
+	RequesterID   *string         `form:"requester_id"`
+	RequesterRole *string         `form:"requester_role"`
+	Source        *string         `form:"source"`
+	Action        *string         `form:"action"`
+	Method        *string         `form:"method"`
+	Status        *ApprovalStatus `form:"status"`
+	ApproverID    *string         `form:"approver_id"`
+	ExpiresAt     *time.Time      `form:"expires_at"`
+	DecidedAt     *time.Time      `form:"decided_at"`
+	CreatedAt     *time.Time      `form:"created_at"`
+	dsl.DSLFields `gorm:"-" json:"-"`
+}
+
+func (ApprovalStatus) EnumValues() []string {
+	return []string{
+		string(ApprovalStatusPending), string(ApprovalStatusApproved), string(ApprovalStatusRejected),
+		string(ApprovalStatusExpired), string(ApprovalStatusFailed),
+	}
+}
+
+func (a *AdministratorApproval) IsExpired(now time.Time) bool {
+	return a.Status == ApprovalStatusPending && !now.Before(a.ExpiresAt)
+}
+
+func ValidateApprovalRejection(rejection *AdministratorApprovalRejection) error {
+	return validateApprovals.Struct(rejection)
+}
+
+func init() {
+	validateApprovals = validator.New()
+}
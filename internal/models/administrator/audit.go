@@ -0,0 +1,125 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AdministratorLogAnchor records the head of a node's log chain at a point in
+// time. Rows removed from the end of a chain no longer match its last anchor.
+type AdministratorLogAnchor struct {
+	ID        int64     `gorm:"primary_key;type:bigint;autoIncrement:true" json:"id"`
+	NodeID    string    `gorm:"not null;type:varchar(255);index" json:"node_id"`
+	ChainSeq  int64     `gorm:"not null;type:bigint" json:"chain_seq"`
+	Hash      string    `gorm:"not null;type:varchar(64)" json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AdministratorLogDigest is the signed summary of a day of a node's chain, as
+// it was written to the digest sink.
+type AdministratorLogDigest struct {
+	ID        int64     `gorm:"primary_key;type:bigint;autoIncrement:true" json:"id"`
+	NodeID    string    `gorm:"not null;type:varchar(255);uniqueIndex:idx_admin_log_digests_day" json:"node_id"`
+	Day       string    `gorm:"not null;type:varchar(10);uniqueIndex:idx_admin_log_digests_day" json:"day"`
+	FirstSeq  int64     `gorm:"type:bigint" json:"first_seq"`
+	LastSeq   int64     `gorm:"type:bigint" json:"last_seq"`
+	Entries   int64     `gorm:"type:bigint" json:"entries"`
+	HeadHash  string    `gorm:"type:varchar(64)" json:"head_hash"`
+	PublicKey string    `gorm:"type:text" json:"public_key"`
+	Signature string    `gorm:"type:text" json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AdministratorLogChainBreak struct {
+	ID       int64  `json:"id"`
+	ChainSeq int64  `json:"chain_seq"`
+	Reason   string `json:"reason"`
+}
+
+type AdministratorLogChainReport struct {
+	NodeID   string                      `json:"node_id"`
+	FromSeq  int64                       `json:"from_seq"`
+	ToSeq    int64                       `json:"to_seq"`
+	Checked  int64                       `json:"checked"`
+	Anchors  int                         `json:"anchors"`
+	Valid    bool                        `json:"valid"`
+	BrokenAt *AdministratorLogChainBreak `json:"broken_at,omitempty"`
+}
+
+// ChainHash hashes the content of the entry together with its place in the
+// chain, so changing either shows up when the chain is walked.
+func (l *AdministratorLogs) ChainHash() string {
+	content, _ := json.Marshal(struct {
+		NodeID     string `json:"node_id"`
+		ChainSeq   int64  `json:"chain_seq"`
+		PrevHash   string `json:"prev_hash"`
+		LogLevel   int    `json:"log_level"`
+		UserID     string `json:"user_id"`
+		UserRole   string `json:"user_role"`
+		Action     string `json:"action"`
+		Method     string `json:"method"`
+		Ip         string `json:"ip"`
+		Status     int    `json:"status"`
+		UserAgent  string `json:"user_agent"`
+		ApprovalID string `json:"approval_id"`
+		Timestamps int64  `json:"timestamps"`
+
+		// left out when empty, entries chained before they existed keep their hash
+		Route        string `json:"route,omitempty"`
+		ResourceType string `json:"resource_type,omitempty"`
+		ResourceID   string `json:"resource_id,omitempty"`
+		RequestBody  string `json:"request_body,omitempty"`
+		Diff         string `json:"diff,omitempty"`
+		LatencyMs    int64  `json:"latency_ms,omitempty"`
+		ErrorMessage string `json:"error_message,omitempty"`
+	}{
+		l.NodeID, l.ChainSeq, l.PrevHash, l.LogLevel, l.UserID, l.UserRole, l.Action,
+		l.Method, l.Ip, l.Status, l.UserAgent, l.ApprovalID, l.Timestamps.UnixMicro(),
+		l.Route, l.ResourceType, l.ResourceID, l.RequestBody, l.Diff, l.LatencyMs, l.ErrorMessage,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// DigestPayload is the content of the digest its signature covers.
+func (d *AdministratorLogDigest) DigestPayload() []byte {
+	payload, _ := json.Marshal(struct {
+		NodeID   string `json:"node_id"`
+		Day      string `json:"day"`
+		FirstSeq int64  `json:"first_seq"`
+		LastSeq  int64  `json:"last_seq"`
+		Entries  int64  `json:"entries"`
+		HeadHash string `json:"head_hash"`
+	}{d.NodeID, d.Day, d.FirstSeq, d.LastSeq, d.Entries, d.HeadHash})
+
+	return payload
+}
+
+// AdministratorLogArchiveManifest describes an archived partition of the logs
+// table, it is written next to the archive.
+type AdministratorLogArchiveManifest struct {
+	Table      string                          `json:"table"`
+	Partition  string                          `json:"partition"`
+	From       time.Time                       `json:"from"`
+	To         time.Time                       `json:"to"`
+	File       string                          `json:"file"`
+	SHA256     string                          `json:"sha256"`
+	Rows       int64                           `json:"rows"`
+	FirstID    int64                           `json:"first_id"`
+	LastID     int64                           `json:"last_id"`
+	Chains     []*AdministratorLogArchiveChain `json:"chains"`
+	ArchivedAt time.Time                       `json:"archived_at"`
+}
+
+// AdministratorLogArchiveChain is the part of a node chain an archive holds.
+// The entry following LastSeq links to LastHash.
+type AdministratorLogArchiveChain struct {
+	NodeID        string `json:"node_id"`
+	FirstSeq      int64  `json:"first_seq"`
+	LastSeq       int64  `json:"last_seq"`
+	FirstPrevHash string `json:"first_prev_hash"`
+	LastHash      string `json:"last_hash"`
+}
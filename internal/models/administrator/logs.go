@@ -7,19 +7,31 @@ import (
 )
 
 type AdministratorLogs struct {
-	ID         int64     `gorm:"primary_key;type:bigint;autoIncrement:true" json:"id"`
-	LogLevel   int       `gorm:"type:smallint" json:"log_level" validate:"required"`
-	UserID     string    `gorm:"type:text" json:"user_id" validate:"required"`
-	UserRole   string    `gorm:"type:text" json:"user_role" validate:"required"`
-	Action     string    `gorm:"type:text" json:"action" validate:"required"`
-	Method     string    `gorm:"type:text" json:"method" validate:"required"`
-	Ip         string    `gorm:"type:text" json:"ip" validate:"required"`
-	Status     int       `gorm:"type:int" json:"status" validate:"required"`
-	UserAgent  string    `gorm:"type:text" json:"user_agent" validate:"required"`
-	Timestamps time.Time `gorm:"type:timestamp" json:"timestamps"`
-	CreatedAt  int64     `gorm:"type:bigint" json:"created_at"`
-	UpdatedAt  int64     `gorm:"type:bigint" json:"updated_at"`
-	DeletedAt  int64     `gorm:"type:bigint" json:"deleted_at"`
+	ID           int64     `gorm:"primary_key;type:bigint;autoIncrement:true" json:"id"`
+	LogLevel     int       `gorm:"type:smallint" json:"log_level" validate:"required"`
+	UserID       string    `gorm:"type:text" json:"user_id" validate:"required"`
+	UserRole     string    `gorm:"type:text" json:"user_role" validate:"required"`
+	Action       string    `gorm:"type:text" json:"action" validate:"required"`
+	Method       string    `gorm:"type:text" json:"method" validate:"required"`
+	Ip           string    `gorm:"type:text" json:"ip" validate:"required"`
+	Status       int       `gorm:"type:int" json:"status" validate:"required"`
+	UserAgent    string    `gorm:"type:text" json:"user_agent" validate:"required"`
+	ApprovalID   string    `gorm:"type:varchar(255)" json:"approval_id,omitempty"`
+	Route        string    `gorm:"type:varchar(255)" json:"route,omitempty"`
+	ResourceType string    `gorm:"type:varchar(64);index:idx_admin_logs_resource,priority:1" json:"resource_type,omitempty"`
+	ResourceID   string    `gorm:"type:varchar(255);index:idx_admin_logs_resource,priority:2" json:"resource_id,omitempty"`
+	RequestBody  string    `gorm:"type:text" json:"request_body,omitempty"`
+	Diff         string    `gorm:"type:text" json:"diff,omitempty"`
+	LatencyMs    int64     `gorm:"type:bigint" json:"latency_ms"`
+	ErrorMessage string    `gorm:"type:text" json:"error_message,omitempty"`
+	NodeID       string    `gorm:"type:varchar(255);index:idx_admin_logs_chain,priority:1" json:"node_id,omitempty"`
+	ChainSeq     int64     `gorm:"type:bigint;index:idx_admin_logs_chain,priority:2" json:"chain_seq,omitempty"`
+	PrevHash     string    `gorm:"type:varchar(64)" json:"prev_hash,omitempty"`
+	Hash         string    `gorm:"type:varchar(64)" json:"hash,omitempty"`
+	Timestamps   time.Time `gorm:"type:timestamp" json:"timestamps"`
+	CreatedAt    int64     `gorm:"type:bigint" json:"created_at"`
+	UpdatedAt    int64     `gorm:"type:bigint" json:"updated_at"`
+	DeletedAt    int64     `gorm:"type:bigint" json:"deleted_at"`
 }
 
 type AdministratorLogsSearch struct {
@@ -31,6 +43,12 @@ type AdministratorLogsSearch struct {
 	Ip            *string    `form:"ip"`
 	Status        *int       `form:"status"`
 	UserAgent     *string    `form:"user_agent"`
+	ApprovalID    *string    `form:"approval_id"`
+	Route         *string    `form:"route"`
+	ResourceType  *string    `form:"resource_type"`
+	ResourceID    *string    `form:"resource_id"`
+	NodeID        *string    `form:"node_id"`
+	ChainSeq      *int64     `form:"chain_seq"`
 	Timestamps    *time.Time `form:"timestamps"`
 	CreatedAt     **int64    `form:"created_at"`
 	UpdatedAt     int64      `form:"updated_at"`
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// AdministratorPasswordHistory keeps the hashes of passwords a user had, so
+// recent ones cannot be set again.
+type AdministratorPasswordHistory struct {
+	HistoryID    string    `gorm:"primaryKey;type:varchar(255)" json:"history_id"`
+	UserID       string    `gorm:"index;not null;type:varchar(255)" json:"user_id"`
+	PasswordHash string    `gorm:"not null;type:varchar(255)" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -26,16 +26,17 @@ const (
 var validateAdminRolePolicy *validator.Validate
 
 type AdministratorRolePolicy struct {
-	PolicyID       string                  `gorm:"primaryKey;size:255" json:"policy_id"`
-	Readonly       PolicyEditing           `gorm:"type:varchar(255);default:'false'" json:"readonly" validate:"policyEditing"`
-	Target         PolicyTargeting         `gorm:"type:varchar(255);default:'roles'" json:"target" validate:"policyTarget"`
-	Title          string                  `gorm:"unique;not null;size:255" json:"title" validate:"required"`
-	SubPolicies    string                  `gorm:"type:json" json:"sub_policies" validate:"required"`
-	SubPolicyRules []types.SubRolePolicies `gorm:"-" json:"sub_policy_rules"`
-	Status         PolicyStatus            `gorm:"type:varchar(255);default:'active'" json:"status" validate:"policyStatus"`
-	CreatedAt      time.Time               `json:"created_at"`
-	UpdatedAt      time.Time               `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt          `gorm:"index" json:"deleted_at"`
+	PolicyID        string                  `gorm:"primaryKey;size:255" json:"policy_id"`
+	Readonly        PolicyEditing           `gorm:"type:varchar(255);default:'false'" json:"readonly" validate:"policyEditing"`
+	Target          PolicyTargeting         `gorm:"type:varchar(255);default:'roles'" json:"target" validate:"policyTarget"`
+	Title           string                  `gorm:"unique;not null;size:255" json:"title" validate:"required"`
+	SubPolicies     string                  `gorm:"type:json" json:"sub_policies" validate:"required_without=SubPoliciesText"`
+	SubPoliciesText string                  `gorm:"-" json:"sub_policies_text,omitempty"`
+	SubPolicyRules  []types.SubRolePolicies `gorm:"-" json:"sub_policy_rules"`
+	Status          PolicyStatus            `gorm:"type:varchar(255);default:'active'" json:"status" validate:"policyStatus"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt          `gorm:"index" json:"deleted_at"`
 }
 
 type AdministratorRolePolicyResponse struct {
@@ -50,6 +51,12 @@ type AdministratorRolePolicyResponse struct {
 	DeletedAt      *time.Time              `json:"deleted_at"`
 }
 
+// AdministratorRolePolicyText carries policy text to compile without
+// storing it.
+type AdministratorRolePolicyText struct {
+	SubPoliciesText string `json:"sub_policies_text" validate:"required"`
+}
+
 type AdministratorRolePolicySearch struct {
 	PolicyID      *string          `form:"policy_id"`
 	Readonly      *PolicyEditing   `form:"readonly"`
@@ -62,6 +69,18 @@ type AdministratorRolePolicySearch struct {
 	dsl.DSLFields `gorm:"-" json:"-"`
 }
 
+func (PolicyStatus) EnumValues() []string {
+	return []string{string(RoleStatusActive), string(RoleStatusPaused)}
+}
+
+func (PolicyTargeting) EnumValues() []string {
+	return []string{string(RolesPolicy)}
+}
+
+func (PolicyEditing) EnumValues() []string {
+	return []string{string(PolicyReadonly), string(PolicyEditable)}
+}
+
 func ValidateAdminRolePolicy(adminRolePolicy *AdministratorRolePolicy) error {
 	return validateAdminRolePolicy.Struct(adminRolePolicy)
 }
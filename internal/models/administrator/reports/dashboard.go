@@ -53,13 +53,21 @@ type AdministratorDashboardTab struct {
 }
 
 type AdministratorDashboardQuery struct {
-	ID           uint      `json:"id" validate:"required" gorm:"primaryKey"`
-	Name         string    `json:"name" validate:"required,max=100" gorm:"type:varchar(100);not null;unique"`
-	Filters      string    `json:"filters" validate:"omitempty,max=1000" gorm:"type:text"`
-	DSLFilters   string    `json:"dsl_filters" validate:"omitempty,json" gorm:"type:jsonb"`
-	IsPredefined bool      `json:"is_predefined" gorm:"default:false"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            uint           `json:"id" validate:"required" gorm:"primaryKey"`
+	Name          string         `json:"name" validate:"required,max=100" gorm:"type:varchar(100);not null;unique"`
+	Resource      SearchResource `json:"resource" gorm:"type:varchar(50);index"`
+	Filters       string         `json:"filters" validate:"omitempty,max=1000" gorm:"type:text"`
+	DSLFilters    string         `json:"dsl_filters" validate:"omitempty,json" gorm:"type:jsonb"`
+	SortBy        string         `json:"sort_by" gorm:"type:varchar(100)"`
+	SortOrder     string         `json:"sort_order" gorm:"type:varchar(10)"`
+	SharedRoles   string         `json:"-" gorm:"type:jsonb"`
+	SharedRoleIDs []string       `json:"shared_roles" gorm:"-"`
+	IsPredefined  bool           `json:"is_predefined" gorm:"default:false"`
+	CreatedBy     string         `json:"created_by" gorm:"type:varchar(255);index"`
+	LastRunBy     string         `json:"last_run_by" gorm:"type:varchar(255)"`
+	LastRunAt     *time.Time     `json:"last_run_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
 type AdministratorDashboardSchedule struct {
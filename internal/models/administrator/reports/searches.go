@@ -0,0 +1,118 @@
+package reports
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+type SearchResource string
+
+const (
+	SearchResourceOrders   SearchResource = "orders"
+	SearchResourceUsers    SearchResource = "users"
+	SearchResourceKYC      SearchResource = "kyc"
+	SearchResourceFiat     SearchResource = "fiat"
+	SearchResourceCrypto   SearchResource = "crypto"
+	SearchResourceWallets  SearchResource = "wallets"
+	SearchResourceCoins    SearchResource = "coins"
+	SearchResourceAssets   SearchResource = "assets"
+	SearchResourceNetworks SearchResource = "networks"
+)
+
+var validateSavedSearch *validator.Validate
+
+type AdministratorSavedSearchRequest struct {
+	ID          uint           `json:"id"`
+	Name        string         `json:"name" validate:"required,max=100"`
+	Resource    SearchResource `json:"resource" validate:"searchResource"`
+	DSL         string         `json:"dsl" validate:"required,max=1000"`
+	SortBy      string         `json:"sort_by" validate:"omitempty,max=100"`
+	SortOrder   string         `json:"sort_order" validate:"omitempty,oneof=asc desc"`
+	SharedRoles []string       `json:"shared_roles" validate:"dive,required"`
+}
+
+type AdministratorDashboardQuerySearch struct {
+	ID            *uint           `form:"id"`
+	Name          *string         `form:"name"`
+	Resource      *SearchResource `form:"resource"`
+	IsPredefined  *bool           `form:"is_predefined"`
+	CreatedBy     *string         `form:"created_by"`
+	LastRunBy     *string         `form:"last_run_by"`
+	CreatedAt     *time.Time      `form:"created_at"`
+	UpdatedAt     *time.Time      `form:"updated_at"`
+	dsl.DSLFields `gorm:"-" json:"-"`
+}
+
+func (SearchResource) EnumValues() []string {
+	return []string{
+		string(SearchResourceOrders), string(SearchResourceUsers), string(SearchResourceKYC),
+		string(SearchResourceFiat), string(SearchResourceCrypto), string(SearchResourceWallets),
+		string(SearchResourceCoins), string(SearchResourceAssets), string(SearchResourceNetworks),
+	}
+}
+
+// IsVisibleTo reports whether the admin may run the search: its creator, any
+// admin holding one of the shared roles, or anyone for predefined searches.
+func (q *AdministratorDashboardQuery) IsVisibleTo(userID, roleID string) bool {
+	if q.IsPredefined || q.CreatedBy == userID {
+		return true
+	}
+
+	for _, sharedRole := range q.SharedRoleIDs {
+		if sharedRole == roleID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *AdministratorDashboardQuery) BeforeSave(tx *gorm.DB) error {
+	if q.SharedRoleIDs == nil {
+		q.SharedRoleIDs = []string{}
+	}
+
+	sharedRoles, err := json.Marshal(q.SharedRoleIDs)
+	if err != nil {
+		return err
+	}
+	q.SharedRoles = string(sharedRoles)
+
+	if q.DSLFilters == "" {
+		q.DSLFilters = "[]"
+	}
+
+	return nil
+}
+
+func (q *AdministratorDashboardQuery) AfterFind(tx *gorm.DB) error {
+	if q.SharedRoles == "" {
+		q.SharedRoleIDs = []string{}
+		return nil
+	}
+
+	return json.Unmarshal([]byte(q.SharedRoles), &q.SharedRoleIDs)
+}
+
+func ValidateSavedSearch(request *AdministratorSavedSearchRequest) error {
+	return validateSavedSearch.Struct(request)
+}
+
+func searchResourceValidation(fl validator.FieldLevel) bool {
+	resource := fl.Field().String()
+	for _, allowed := range SearchResource("").EnumValues() {
+		if resource == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	validateSavedSearch = validator.New()
+	validateSavedSearch.RegisterValidation("searchResource", searchResourceValidation)
+}
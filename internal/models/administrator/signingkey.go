@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type SigningKeyStatus string
+
+const (
+	SigningKeyActive   SigningKeyStatus = "active"
+	SigningKeyPrevious SigningKeyStatus = "previous"
+	SigningKeyRetired  SigningKeyStatus = "retired"
+)
+
+// AdministratorSigningKey is a key access tokens are signed with. Only one key
+// is active; previous keys keep verifying tokens until RetiresAt. The private
+// key is stored encrypted.
+type AdministratorSigningKey struct {
+	KeyID      string           `gorm:"primaryKey;type:varchar(255)" json:"kid"`
+	Algorithm  string           `gorm:"not null;type:varchar(16)" json:"alg"`
+	PrivateKey string           `gorm:"not null;type:text" json:"-"`
+	PublicKey  string           `gorm:"not null;type:text" json:"public_key"`
+	Status     SigningKeyStatus `gorm:"index;not null;type:varchar(16)" json:"status"`
+	RetiresAt  *time.Time       `json:"retires_at"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type TwoFactorMethod string
+
+const (
+	TwoFactorSMS  TwoFactorMethod = "sms"
+	TwoFactorTOTP TwoFactorMethod = "totp"
+)
+
+// AdministratorRecoveryCode is a single use code for signing in without the
+// authenticator. Only the hash of the code is stored.
+type AdministratorRecoveryCode struct {
+	CodeID    string     `gorm:"primaryKey;type:varchar(255)" json:"code_id"`
+	UserID    string     `gorm:"index;not null;type:varchar(255)" json:"user_id"`
+	CodeHash  string     `gorm:"uniqueIndex;not null;type:varchar(64)" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
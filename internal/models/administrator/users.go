@@ -31,19 +31,23 @@ const (
 var validateAdminUsers *validator.Validate
 
 type AdministratorUser struct {
-	UserID           string             `gorm:"primaryKey;varchar(255)" json:"user_id"`
-	EmployerName     string             `gorm:"unique;not null;varchar(255)" json:"employer_name" validate:"required,min=3"`
-	Username         string             `gorm:"unique;not null;varchar(255)" json:"username" validate:"required,email"`
-	Password         string             `gorm:"not null;varchar(255)" json:"-"`
-	PhoneNumber      string             `gorm:"not null;varchar(255)" json:"phone_number" validate:"required,phone"`
-	VerificationCode string             `gorm:"varchar(255)" json:"-"`
-	VpnAddr          string             `gorm:"type:varchar(255)" json:"vpn_addr" validate:"vpnaddr"`
-	UserRole         string             `gorm:"not null;type:varchar(255)" json:"user_role" validate:"required"`
-	Role             *AdministratorRole `gorm:"foreignKey:UserRole;references:RoleID;onUpdate:CASCADE;onDelete:SET NULL"`
-	Status           UserStatus         `gorm:"type:varchar(255);default:'pending'" json:"status" validate:"userStatus"`
-	LastLogin        time.Time          `gorm:"-" json:"last_login" validate:"omitempty"`
-	CreatedAt        time.Time          `json:"CreatedAt"`
-	UpdatedAt        time.Time          `json:"UpdatedAt"`
+	UserID             string             `gorm:"primaryKey;varchar(255)" json:"user_id"`
+	EmployerName       string             `gorm:"unique;not null;varchar(255)" json:"employer_name" validate:"required,min=3"`
+	Username           string             `gorm:"unique;not null;varchar(255)" json:"username" validate:"required,email"`
+	Password           string             `gorm:"not null;varchar(255)" json:"-"`
+	PhoneNumber        string             `gorm:"not null;varchar(255)" json:"phone_number" validate:"required,phone"`
+	VerificationCode   string             `gorm:"varchar(255)" json:"-"`
+	VpnAddr            string             `gorm:"type:varchar(255)" json:"vpn_addr" validate:"vpnaddr"`
+	UserRole           string             `gorm:"not null;type:varchar(255)" json:"user_role" validate:"required"`
+	Role               *AdministratorRole `gorm:"foreignKey:UserRole;references:RoleID;onUpdate:CASCADE;onDelete:SET NULL"`
+	Status             UserStatus         `gorm:"type:varchar(255);default:'pending'" json:"status" validate:"userStatus"`
+	TwoFactorMethod    TwoFactorMethod    `gorm:"type:varchar(16);default:'sms'" json:"-"`
+	TOTPSecret         string             `gorm:"type:varchar(255)" json:"-"`
+	MustChangePassword bool               `gorm:"default:false" json:"must_change_password"`
+	PasswordChangedAt  *time.Time         `json:"password_changed_at"`
+	LastLogin          time.Time          `gorm:"-" json:"last_login" validate:"omitempty"`
+	CreatedAt          time.Time          `json:"CreatedAt"`
+	UpdatedAt          time.Time          `json:"UpdatedAt"`
 }
 
 type AdministratorRole struct {
@@ -84,6 +88,10 @@ type AdministratorRoleSearch struct {
 	dsl.DSLFields `gorm:"-" json:"-"`
 }
 
+func (UserStatus) EnumValues() []string {
+	return []string{string(UserStatusPending), string(UserStatusPaused), string(UserStatusVerified)}
+}
+
 func ValidateAdminRole(adminRole *AdministratorRole) error {
 	return validateAdminUsers.Struct(adminRole)
 }
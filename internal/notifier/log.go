@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"go.uber.org/zap"
+)
+
+// logNotifier writes messages to a file, or to the logger when no file is
+// set, so reset links can be picked up while testing locally.
+type logNotifier struct {
+	file   string
+	logger *zap.Logger
+	mutex  sync.Mutex
+}
+
+func newLogNotifier(cfg *config.Config) *logNotifier {
+	return &logNotifier{file: cfg.Notifier.LogFile, logger: cfg.Logger}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, message *Message) error {
+	if err := validateMessage(message); err != nil {
+		return err
+	}
+
+	if n.file == "" {
+		n.logger.Info("notification", zap.String("to", message.To), zap.String("subject", message.Subject), zap.String("body", message.Body))
+		return nil
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	f, err := os.OpenFile(n.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "--- %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+	return err
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+)
+
+const (
+	ProviderSMTP = "smtp"
+	ProviderLog  = "log"
+
+	productionEnvironment = "production"
+)
+
+var ErrInvalidMessage = errors.New("notification has an invalid recipient or subject")
+
+// Message is a plain text notification to an admin user.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to admin users out of band, such as password
+// reset links and temporary passwords.
+type Notifier interface {
+	Notify(ctx context.Context, message *Message) error
+}
+
+// NewNotifier builds the notifier configured in NOTIFIER. The log sink keeps
+// the secrets it is given in plain text, so it is refused in production.
+func NewNotifier(cfg *config.Config) (Notifier, error) {
+	switch cfg.Notifier.Provider {
+	case "", ProviderSMTP:
+		if cfg.Notifier.SMTPHost == "" || cfg.Notifier.From == "" {
+			return nil, errors.New("smtp notifier needs a smtp_host and a from address")
+		}
+		return newSMTPNotifier(cfg), nil
+
+	case ProviderLog:
+		if cfg.Environment == productionEnvironment {
+			return nil, fmt.Errorf("notifier %q can not be used in production", ProviderLog)
+		}
+		return newLogNotifier(cfg), nil
+
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.Notifier.Provider)
+	}
+}
+
+// validateMessage keeps header values on a single line so they cannot add
+// headers or recipients of their own.
+func validateMessage(message *Message) error {
+	if message.To == "" || strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return ErrInvalidMessage
+	}
+	return nil
+}
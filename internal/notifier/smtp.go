@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+)
+
+const smtpTimeout = 10 * time.Second
+
+type smtpNotifier struct {
+	config *config.NotifierConfig
+	addr   string
+	auth   smtp.Auth
+}
+
+func newSMTPNotifier(cfg *config.Config) *smtpNotifier {
+	n := &smtpNotifier{
+		config: cfg.Notifier,
+		addr:   net.JoinHostPort(cfg.Notifier.SMTPHost, strconv.Itoa(cfg.Notifier.SMTPPort)),
+	}
+
+	if cfg.Notifier.SMTPUsername != "" {
+		n.auth = smtp.PlainAuth("", cfg.Notifier.SMTPUsername, cfg.Notifier.SMTPPassword, cfg.Notifier.SMTPHost)
+	}
+
+	return n
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, message *Message) error {
+	if err := validateMessage(message); err != nil {
+		return err
+	}
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.SMTPHost}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(n.compose(message)); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (n *smtpNotifier) compose(message *Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + n.config.From + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + message.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
@@ -0,0 +1,287 @@
+package peggy
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+// Parse compiles policy text as described by peggy/grammar.peggy into
+// sub-policies, one statement per line:
+//
+//	orders read allowed
+//	kyc read partial allowed [id_card_number, national_id]   # support desk
+//	users delete require otp
+//
+// Every line is checked, so the returned SyntaxErrors lists all problems of
+// the text rather than only the first one.
+func Parse(text string) ([]types.SubRolePolicies, error) {
+	var (
+		policies []types.SubRolePolicies
+		errs     SyntaxErrors
+	)
+
+	for i, line := range strings.Split(text, "\n") {
+		p := &lineParser{line: []rune(strings.TrimSuffix(line, "\r")), number: i + 1}
+
+		policy, err := p.parse()
+		if err != nil {
+			errs = append(errs, *err)
+			continue
+		}
+		if policy != nil {
+			policies = append(policies, *policy)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(policies) == 0 {
+		return nil, SyntaxErrors{{Line: 1, Column: 1, Message: "policy text has no statements"}}
+	}
+
+	return policies, nil
+}
+
+// SyntaxError points at the line and column (both 1-based) a statement
+// could not be parsed at.
+type SyntaxError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type SyntaxErrors []SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("policy text has a syntax error at line %d, column %d: %s", e[0].Line, e[0].Column, e[0].Message)
+	}
+	return fmt.Sprintf("policy text has %d syntax errors, first at line %d, column %d: %s", len(e), e[0].Line, e[0].Column, e[0].Message)
+}
+
+func (e SyntaxErrors) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e SyntaxErrors) FieldDetails() map[string]string {
+	details := make(map[string]string, len(e))
+	for _, err := range e {
+		details[fmt.Sprintf("%d:%d", err.Line, err.Column)] = err.Message
+	}
+	return details
+}
+
+var sourceAliases = map[string]types.PolicySource{
+	"order": types.SourceOrders,
+}
+
+type lineParser struct {
+	line   []rune
+	pos    int
+	number int
+}
+
+func (p *lineParser) parse() (*types.SubRolePolicies, *SyntaxError) {
+	p.skipSpace()
+	if p.atEnd() {
+		return nil, nil
+	}
+
+	source, ok := p.keyword(sourceKeywords())
+	if !ok {
+		return nil, p.expected("a source", sourceKeywords())
+	}
+	if alias, found := sourceAliases[source]; found {
+		source = string(alias)
+	}
+
+	if err := p.separator(); err != nil {
+		return nil, err
+	}
+
+	action, ok := p.keyword(types.PolicyAction("").EnumValues())
+	if !ok {
+		return nil, p.expected("an action", types.PolicyAction("").EnumValues())
+	}
+
+	if err := p.separator(); err != nil {
+		return nil, err
+	}
+
+	allowanceColumn := p.column()
+	allowance, ok := p.keyword(types.PolicyAllowance("").EnumValues())
+	if !ok {
+		return nil, p.expected("an allowance", types.PolicyAllowance("").EnumValues())
+	}
+
+	policy := &types.SubRolePolicies{Source: source, Action: action, Allowance: allowance}
+
+	p.skipSpace()
+	fieldsColumn := p.column()
+	if p.peek() == '[' {
+		fields, err := p.fields()
+		if err != nil {
+			return nil, err
+		}
+		policy.Fields = fields
+	}
+
+	p.skipSpace()
+	if !p.atEnd() {
+		return nil, p.errorf("unexpected %q, expected end of statement", p.rest())
+	}
+
+	partial := types.PolicyAllowance(allowance) == types.AllowancePartialAllowed
+	if partial && len(policy.Fields) == 0 {
+		return nil, &SyntaxError{Line: p.number, Column: allowanceColumn, Message: "partial allowed needs a list of fields to mask, e.g. [national_id, iban]"}
+	}
+	if !partial && len(policy.Fields) > 0 {
+		return nil, &SyntaxError{Line: p.number, Column: fieldsColumn, Message: "only partial allowed takes a list of fields"}
+	}
+
+	return policy, nil
+}
+
+// keyword matches the longest option at the current position. Words of a
+// multi word option may be separated by any amount of blanks.
+func (p *lineParser) keyword(options []string) (string, bool) {
+	sorted := append([]string(nil), options...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	for _, option := range sorted {
+		if end, ok := p.matchWords(strings.Fields(option)); ok {
+			p.pos = end
+			return option, true
+		}
+	}
+	return "", false
+}
+
+func (p *lineParser) matchWords(words []string) (int, bool) {
+	pos := p.pos
+	for i, word := range words {
+		if i > 0 {
+			start := pos
+			for pos < len(p.line) && isBlank(p.line[pos]) {
+				pos++
+			}
+			if pos == start {
+				return 0, false
+			}
+		}
+
+		w := []rune(word)
+		if pos+len(w) > len(p.line) || string(p.line[pos:pos+len(w)]) != word {
+			return 0, false
+		}
+		pos += len(w)
+	}
+
+	if pos < len(p.line) && !isBoundary(p.line[pos]) {
+		return 0, false
+	}
+	return pos, true
+}
+
+func (p *lineParser) fields() ([]string, *SyntaxError) {
+	p.pos++ // [
+
+	var fields []string
+	for {
+		p.skipSpace()
+
+		start := p.pos
+		for p.pos < len(p.line) && isFieldRune(p.line[p.pos], p.pos == start) {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, p.errorf("expected a field name")
+		}
+		fields = append(fields, string(p.line[start:p.pos]))
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return fields, nil
+		default:
+			return nil, p.errorf("expected ',' or ']' in the field list")
+		}
+	}
+}
+
+func (p *lineParser) separator() *SyntaxError {
+	if p.pos >= len(p.line) || !isBlank(p.line[p.pos]) {
+		return p.errorf("expected a space")
+	}
+	p.skipSpace()
+	return nil
+}
+
+func (p *lineParser) skipSpace() {
+	for p.pos < len(p.line) && isBlank(p.line[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *lineParser) atEnd() bool {
+	return p.pos >= len(p.line) || p.line[p.pos] == '#'
+}
+
+func (p *lineParser) peek() rune {
+	if p.pos >= len(p.line) {
+		return 0
+	}
+	return p.line[p.pos]
+}
+
+func (p *lineParser) rest() string {
+	return strings.TrimSpace(string(p.line[p.pos:]))
+}
+
+func (p *lineParser) column() int {
+	return p.pos + 1
+}
+
+func (p *lineParser) expected(what string, options []string) *SyntaxError {
+	found := "end of line"
+	if !p.atEnd() {
+		found = fmt.Sprintf("%q", strings.Fields(string(p.line[p.pos:]))[0])
+	}
+	return p.errorf("expected %s (%s), found %s", what, strings.Join(options, ", "), found)
+}
+
+func (p *lineParser) errorf(format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Line: p.number, Column: p.column(), Message: fmt.Sprintf(format, args...)}
+}
+
+func sourceKeywords() []string {
+	keywords := types.PolicySource("").EnumValues()
+	for alias := range sourceAliases {
+		keywords = append(keywords, alias)
+	}
+	return keywords
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+func isBoundary(r rune) bool {
+	return isBlank(r) || r == '#' || r == '['
+}
+
+func isFieldRune(r rune, first bool) bool {
+	if r == '_' || (r < unicode.MaxASCII && unicode.IsLetter(r)) {
+		return true
+	}
+	return !first && r < unicode.MaxASCII && unicode.IsDigit(r)
+}
@@ -21,6 +21,9 @@ func NewPrefixService() *Prefix {
 	service.AddService("admin-users", "administrator")
 	service.AddService("admin-user-roles", "administrator")
 	service.AddService("admin-policy", "administrator")
+	service.AddService("admin-saved-searches", "administrator")
+	service.AddService("admin-approvals", "administrator")
+	service.AddService("admin-allowlist", "administrator")
 
 	service.AddService("orders", "order")
 	service.AddService("transactions", "transaction_manager")
@@ -9,17 +9,23 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/service"
 	"go.uber.org/zap"
 
+	administratorAllowlistHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/allowlist"
+	administratorApprovalsHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/approvals"
 	administratorAuthHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/auth"
 	administratorLogsHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/logs"
 	administratorPolicyHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/policy"
 	administratorUserRolesHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/roles"
+	administratorSearchesHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/searches"
 	administratorUserHandler "github.com/denizumutdereli/stream-admin/internal/handler/administrator/user"
 
+	administratorAllowlistService "github.com/denizumutdereli/stream-admin/internal/service/administrator/allowlist"
+	administratorApprovalsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/approvals"
 	administratorAuthService "github.com/denizumutdereli/stream-admin/internal/service/administrator/auth"
 	administratorUsersService "github.com/denizumutdereli/stream-admin/internal/service/administrator/users"
 	administratorLogsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
 	administratorPolicyService "github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
 	administratorRolesService "github.com/denizumutdereli/stream-admin/internal/service/administrator/roles"
+	administratorSearchesService "github.com/denizumutdereli/stream-admin/internal/service/administrator/searches"
 )
 
 type HandlersRegistry interface {
@@ -30,6 +36,9 @@ type HandlersRegistry interface {
 	RegisterAdminAuthHandler(service *administratorAuthService.AdminAuthService) (*administratorAuthHandler.AdminAuthHandler, error)
 	RegisterAdminLogsHandler(service administratorLogsService.AdminLogsService) (*administratorLogsHandler.AdminLogsRestHandler, error)
 	RegisterAdminPolicyHandler(service *administratorPolicyService.AdminPolicyService) (*administratorPolicyHandler.AdminPolicyHandler, error)
+	RegisterAdminSavedSearchHandler(service *administratorSearchesService.AdminSavedSearchService) (*administratorSearchesHandler.AdminSavedSearchHandler, error)
+	RegisterAdminApprovalsHandler(service *administratorApprovalsService.AdminApprovalsService) (*administratorApprovalsHandler.AdminApprovalsHandler, error)
+	RegisterAdminAllowlistHandler(service *administratorAllowlistService.AdminAllowlistService) (*administratorAllowlistHandler.AdminAllowlistHandler, error)
 
 	GetAdminRestHandler() (handler.AdminRestHandler, error)
 	GetAdminUsersHandler() (administratorUserHandler.AdminUserHandler, error)
@@ -37,6 +46,9 @@ type HandlersRegistry interface {
 	GetAdminAuthHandler() (administratorAuthHandler.AdminAuthHandler, error)
 	GetAdminLogsHandler() (administratorLogsHandler.AdminLogsRestHandler, error)
 	GetAdminPolicyHandler() (administratorPolicyHandler.AdminPolicyHandler, error)
+	GetAdminSavedSearchHandler() (administratorSearchesHandler.AdminSavedSearchHandler, error)
+	GetAdminApprovalsHandler() (administratorApprovalsHandler.AdminApprovalsHandler, error)
+	GetAdminAllowlistHandler() (administratorAllowlistHandler.AdminAllowlistHandler, error)
 	/* ------------------------------------------------------------------------------------------- */
 
 	RegisterOrdersRestHandler(service service.OrdersService) (*handler.OrdersRestHandler, error)
@@ -60,6 +72,9 @@ type handlersRegistry struct {
 	adminAuthHandler      administratorAuthHandler.AdminAuthHandler
 	adminLogsHandler      administratorLogsHandler.AdminLogsRestHandler
 	adminPolicyHandler    administratorPolicyHandler.AdminPolicyHandler
+	adminSearchHandler    administratorSearchesHandler.AdminSavedSearchHandler
+	adminApprovalsHandler administratorApprovalsHandler.AdminApprovalsHandler
+	adminAllowlistHandler administratorAllowlistHandler.AdminAllowlistHandler
 	ordersHandler         handler.OrdersRestHandler
 	transactionsHandler   handler.TransactionsRestHandler
 	usersHandler          handler.UsersRestHandler
@@ -217,6 +232,57 @@ func (h *handlersRegistry) RegisterAdminPolicyHandler(service *administratorPoli
 	return &h.adminPolicyHandler, nil
 }
 
+func (h *handlersRegistry) RegisterAdminSavedSearchHandler(service *administratorSearchesService.AdminSavedSearchService) (*administratorSearchesHandler.AdminSavedSearchHandler, error) {
+	if h.adminSearchHandler == nil {
+		h.logger.Debug("Admin saved search handler is not registered, registering it now")
+
+		handler := administratorSearchesHandler.NewAdminSavedSearchHandler(service, h.config, h.builders)
+
+		if handler == nil {
+			return nil, errors.New("received nil adminSavedSearch handler")
+		}
+
+		h.adminSearchHandler = handler
+		return &handler, nil
+	}
+
+	return &h.adminSearchHandler, nil
+}
+
+func (h *handlersRegistry) RegisterAdminApprovalsHandler(service *administratorApprovalsService.AdminApprovalsService) (*administratorApprovalsHandler.AdminApprovalsHandler, error) {
+	if h.adminApprovalsHandler == nil {
+		h.logger.Debug("Admin approvals handler is not registered, registering it now")
+
+		handler := administratorApprovalsHandler.NewAdminApprovalsHandler(service, h.config, h.builders)
+
+		if handler == nil {
+			return nil, errors.New("received nil adminApprovals handler")
+		}
+
+		h.adminApprovalsHandler = handler
+		return &handler, nil
+	}
+
+	return &h.adminApprovalsHandler, nil
+}
+
+func (h *handlersRegistry) RegisterAdminAllowlistHandler(service *administratorAllowlistService.AdminAllowlistService) (*administratorAllowlistHandler.AdminAllowlistHandler, error) {
+	if h.adminAllowlistHandler == nil {
+		h.logger.Debug("Admin allowlist handler is not registered, registering it now")
+
+		handler := administratorAllowlistHandler.NewAdminAllowlistHandler(service, h.config, h.builders)
+
+		if handler == nil {
+			return nil, errors.New("received nil adminAllowlist handler")
+		}
+
+		h.adminAllowlistHandler = handler
+		return &handler, nil
+	}
+
+	return &h.adminAllowlistHandler, nil
+}
+
 func (h *handlersRegistry) GetAdminRestHandler() (handler.AdminRestHandler, error) {
 	return h.adminRestHandler, nil
 }
@@ -241,6 +307,18 @@ func (h *handlersRegistry) GetAdminPolicyHandler() (administratorPolicyHandler.A
 	return h.adminPolicyHandler, nil
 }
 
+func (h *handlersRegistry) GetAdminSavedSearchHandler() (administratorSearchesHandler.AdminSavedSearchHandler, error) {
+	return h.adminSearchHandler, nil
+}
+
+func (h *handlersRegistry) GetAdminApprovalsHandler() (administratorApprovalsHandler.AdminApprovalsHandler, error) {
+	return h.adminApprovalsHandler, nil
+}
+
+func (h *handlersRegistry) GetAdminAllowlistHandler() (administratorAllowlistHandler.AdminAllowlistHandler, error) {
+	return h.adminAllowlistHandler, nil
+}
+
 /* ---------------------------------------------------------------------------------------- */
 
 func (h *handlersRegistry) RegisterOrdersRestHandler(service service.OrdersService) (*handler.OrdersRestHandler, error) {
@@ -8,9 +8,12 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/config"
 
 	"github.com/denizumutdereli/stream-admin/internal/repository"
+	administratorAllowlistRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/allowlist"
+	administratorApprovalsRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/approvals"
 	administratorAuthRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
 	administratorLogsRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/logs"
 	administratorPolicyRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/policy"
+	administratorQueryRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/reports/query"
 	administratorUserRolesRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/roles"
 	administratorUsersRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/users"
 
@@ -33,6 +36,9 @@ type RepositoryRegistry interface {
 	RegisterAdminAuthRepository(servicePrefix string, contextMessage contextMessages.ContextMessages) (*administratorAuthRepo.AdminAuthRepository, error)
 	RegisterAdminLogsRepository(servicePrefix string) (*administratorLogsRepo.AdminLogsRepository, error)
 	RegisterAdminPolicyRepository(servicePrefix string) (*administratorPolicyRepo.AdminRolePolicyRepository, error)
+	RegisterAdminSavedSearchRepository(servicePrefix string) (*administratorQueryRepo.QueryRepository, error)
+	RegisterAdminApprovalsRepository(servicePrefix string) (*administratorApprovalsRepo.AdminApprovalsRepository, error)
+	RegisterAdminAllowlistRepository(servicePrefix string) (*administratorAllowlistRepo.AdminAllowlistRepository, error)
 
 	// Sub-services registry
 	RegisterOrdersRepository(servicePrefix string) (*orders.OrdersRepository, error)
@@ -47,6 +53,9 @@ type RepositoryRegistry interface {
 	GetAdminAuthRepository() (administratorAuthRepo.AdminAuthRepository, error)
 	GetAdminLogsRepository() (administratorLogsRepo.AdminLogsRepository, error)
 	GetAdminPolicyRepository() (administratorPolicyRepo.AdminRolePolicyRepository, error)
+	GetAdminSavedSearchRepository() (administratorQueryRepo.QueryRepository, error)
+	GetAdminApprovalsRepository() (administratorApprovalsRepo.AdminApprovalsRepository, error)
+	GetAdminAllowlistRepository() (administratorAllowlistRepo.AdminAllowlistRepository, error)
 
 	// Sub-services registry getter
 	GetOrdersRepository() (orders.OrdersRepository, error)
@@ -62,12 +71,15 @@ type repositoryRegistry struct {
 	builders builders.BuilderService
 	redis    *transport.RedisManager
 
-	admin       repository.AdminRepository
-	adminUsers  administratorUsersRepo.AdminUsersRepository
-	adminRoles  administratorUserRolesRepo.AdminUserRolesRepository
-	adminLogs   administratorLogsRepo.AdminLogsRepository
-	adminAuth   administratorAuthRepo.AdminAuthRepository
-	adminPolicy administratorPolicyRepo.AdminRolePolicyRepository
+	admin          repository.AdminRepository
+	adminUsers     administratorUsersRepo.AdminUsersRepository
+	adminRoles     administratorUserRolesRepo.AdminUserRolesRepository
+	adminLogs      administratorLogsRepo.AdminLogsRepository
+	adminAuth      administratorAuthRepo.AdminAuthRepository
+	adminPolicy    administratorPolicyRepo.AdminRolePolicyRepository
+	adminSearch    administratorQueryRepo.QueryRepository
+	adminApprovals administratorApprovalsRepo.AdminApprovalsRepository
+	adminAllowlist administratorAllowlistRepo.AdminAllowlistRepository
 	//adminContextMessages contextMessage.ContextMessages
 	orders       orders.OrdersRepository
 	transactions transactions.TransactionRepository
@@ -112,6 +124,18 @@ func (r *repositoryRegistry) GetAdminPolicyRepository() (administratorPolicyRepo
 	return r.adminPolicy, nil
 }
 
+func (r *repositoryRegistry) GetAdminSavedSearchRepository() (administratorQueryRepo.QueryRepository, error) {
+	return r.adminSearch, nil
+}
+
+func (r *repositoryRegistry) GetAdminApprovalsRepository() (administratorApprovalsRepo.AdminApprovalsRepository, error) {
+	return r.adminApprovals, nil
+}
+
+func (r *repositoryRegistry) GetAdminAllowlistRepository() (administratorAllowlistRepo.AdminAllowlistRepository, error) {
+	return r.adminAllowlist, nil
+}
+
 func (r *repositoryRegistry) GetAdminAdminRepository() (administratorPolicyRepo.AdminRolePolicyRepository, error) {
 	return r.adminPolicy, nil
 }
@@ -250,6 +274,66 @@ func (r *repositoryRegistry) RegisterAdminPolicyRepository(servicePrefix string)
 	return &r.adminPolicy, nil
 }
 
+func (r *repositoryRegistry) RegisterAdminSavedSearchRepository(servicePrefix string) (*administratorQueryRepo.QueryRepository, error) {
+	if r.adminSearch == nil {
+		var err error
+		r.logger.Debug("admin saved search repository is not registered, registering it now")
+		r.adminSearch, err = administratorQueryRepo.NewGORMQueryRepository(r.db, servicePrefix, r.config, r.builders)
+
+		if err != nil {
+			r.logger.Fatal("service repository creation error:", zap.Error(err))
+		}
+
+		if r.adminSearch == nil {
+			return nil, errors.New("failed to initialize admin saved search repository")
+		}
+
+		return &r.adminSearch, nil
+
+	}
+	return &r.adminSearch, nil
+}
+
+func (r *repositoryRegistry) RegisterAdminApprovalsRepository(servicePrefix string) (*administratorApprovalsRepo.AdminApprovalsRepository, error) {
+	if r.adminApprovals == nil {
+		var err error
+		r.logger.Debug("admin approvals repository is not registered, registering it now")
+		r.adminApprovals, err = administratorApprovalsRepo.NewGORMAdminApprovalsRepository(r.db, servicePrefix, r.config, r.builders)
+
+		if err != nil {
+			r.logger.Fatal("service repository creation error:", zap.Error(err))
+		}
+
+		if r.adminApprovals == nil {
+			return nil, errors.New("failed to initialize admin approvals repository")
+		}
+
+		return &r.adminApprovals, nil
+
+	}
+	return &r.adminApprovals, nil
+}
+
+func (r *repositoryRegistry) RegisterAdminAllowlistRepository(servicePrefix string) (*administratorAllowlistRepo.AdminAllowlistRepository, error) {
+	if r.adminAllowlist == nil {
+		var err error
+		r.logger.Debug("admin allowlist repository is not registered, registering it now")
+		r.adminAllowlist, err = administratorAllowlistRepo.NewGORMAdminAllowlistRepository(r.db, servicePrefix, r.config, r.builders)
+
+		if err != nil {
+			r.logger.Fatal("service repository creation error:", zap.Error(err))
+		}
+
+		if r.adminAllowlist == nil {
+			return nil, errors.New("failed to initialize admin allowlist repository")
+		}
+
+		return &r.adminAllowlist, nil
+
+	}
+	return &r.adminAllowlist, nil
+}
+
 /* sub-services ------------------------------------------------------------------------------------------------- */
 
 func (r *repositoryRegistry) RegisterOrdersRepository(servicePrefix string) (*orders.OrdersRepository, error) {
@@ -4,8 +4,11 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/caesar"
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	"github.com/denizumutdereli/stream-admin/internal/repository"
+	adminAllowlistRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/allowlist"
+	adminApprovalsRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/approvals"
 	"github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
 	"github.com/denizumutdereli/stream-admin/internal/repository/administrator/logs"
+	adminQueryRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/reports/query"
 	"github.com/denizumutdereli/stream-admin/internal/repository/assets"
 	"github.com/denizumutdereli/stream-admin/internal/repository/orders"
 	"github.com/denizumutdereli/stream-admin/internal/repository/transactions"
@@ -18,10 +21,13 @@ import (
 	adminUsersRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/users"
 
 	"github.com/denizumutdereli/stream-admin/internal/service"
+	administratorAllowlistService "github.com/denizumutdereli/stream-admin/internal/service/administrator/allowlist"
+	administratorApprovalsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/approvals"
 	administratorAuthService "github.com/denizumutdereli/stream-admin/internal/service/administrator/auth"
 	administratorLogsService "github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
 	administratorPolicyService "github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
 	administratorRolesService "github.com/denizumutdereli/stream-admin/internal/service/administrator/roles"
+	administratorSearchesService "github.com/denizumutdereli/stream-admin/internal/service/administrator/searches"
 	administratorUsersService "github.com/denizumutdereli/stream-admin/internal/service/administrator/users"
 	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -34,6 +40,9 @@ type ServiceRegistry interface {
 	RegisterAdminUsersService(userRepo *adminUsersRepo.AdminUsersRepository, caesar caesar.CaesarManager, config *config.Config) (administratorUsersService.AdminUserService, error)
 	RegisterAdminUserRolesService(userRolesRepo *adminUserRolesRepo.AdminUserRolesRepository, caesar caesar.CaesarManager, config *config.Config) (administratorRolesService.AdminUserRolesService, error)
 	RegisterAdminPolicyService(policyRepo *adminPolicyRepo.AdminRolePolicyRepository, caesar caesar.CaesarManager, config *config.Config) (administratorPolicyService.AdminPolicyService, error)
+	RegisterAdminSavedSearchService(queryRepo *adminQueryRepo.QueryRepository, policyService administratorPolicyService.AdminPolicyService, caesar caesar.CaesarManager, config *config.Config) (administratorSearchesService.AdminSavedSearchService, error)
+	RegisterAdminApprovalsService(approvalsRepo *adminApprovalsRepo.AdminApprovalsRepository, userRepo *adminUsersRepo.AdminUsersRepository, policyService administratorPolicyService.AdminPolicyService, contextMessages contextMessage.ContextMessages, caesar caesar.CaesarManager, config *config.Config) (administratorApprovalsService.AdminApprovalsService, error)
+	RegisterAdminAllowlistService(allowlistRepo *adminAllowlistRepo.AdminAllowlistRepository, userRepo *adminUsersRepo.AdminUsersRepository, nats *transport.NatsManager, config *config.Config) (administratorAllowlistService.AdminAllowlistService, error)
 	RegisterAdminContextMessageService(config *config.Config, redis *transport.RedisManager, nats *transport.NatsManager) (contextMessage.ContextMessages, error)
 	RegisterAdminService(repo *repository.AdminRepository) (service.AdminService, error)
 
@@ -42,6 +51,9 @@ type ServiceRegistry interface {
 	GetAdminUsersService() (administratorUsersService.AdminUserService, error)
 	GetAdminUserRolesService() (administratorRolesService.AdminUserRolesService, error)
 	GetAdminPolicyService() (administratorPolicyService.AdminPolicyService, error)
+	GetAdminSavedSearchService() (administratorSearchesService.AdminSavedSearchService, error)
+	GetAdminApprovalsService() (administratorApprovalsService.AdminApprovalsService, error)
+	GetAdminAllowlistService() (administratorAllowlistService.AdminAllowlistService, error)
 	GetAdminContextMessageService() (contextMessage.ContextMessages, error)
 	GetAdminService() (service.AdminService, error)
 
@@ -67,6 +79,9 @@ type serviceRegistry struct {
 	administratorUsersService          administratorUsersService.AdminUserService
 	administratorUserRolesService      administratorRolesService.AdminUserRolesService
 	administratorPolicyService         administratorPolicyService.AdminPolicyService
+	administratorSavedSearchService    administratorSearchesService.AdminSavedSearchService
+	administratorApprovalsService      administratorApprovalsService.AdminApprovalsService
+	administratorAllowlistService      administratorAllowlistService.AdminAllowlistService
 	administratorContextMessageService contextMessage.ContextMessages
 	administratorService               service.AdminService
 
@@ -92,7 +107,10 @@ func NewServiceRegistry(appContext *types.ExchangeConfig) (ServiceRegistry, erro
 
 func (s *serviceRegistry) RegisterAdminAuthService(authRepo *auth.AdminAuthRepository, userRepo *adminUsersRepo.AdminUsersRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) (administratorAuthService.AdminAuthService, error) {
 	if s.administratorAuthService == nil {
-		service := administratorAuthService.NewAuthService(authRepo, userRepo, caesar, redis, s.config)
+		service, err := administratorAuthService.NewAuthService(authRepo, userRepo, caesar, redis, s.config)
+		if err != nil {
+			return nil, err
+		}
 		s.administratorAuthService = service
 		return service, nil
 	}
@@ -110,7 +128,10 @@ func (s *serviceRegistry) RegisterAdminLogsService(repo *logs.AdminLogsRepositor
 
 func (s *serviceRegistry) RegisterAdminUsersService(userRepo *adminUsersRepo.AdminUsersRepository, caesar caesar.CaesarManager, config *config.Config) (administratorUsersService.AdminUserService, error) {
 	if s.administratorUsersService == nil {
-		service := administratorUsersService.NewAdminUsersService(userRepo, caesar, s.appContext.Redis, s.config)
+		service, err := administratorUsersService.NewAdminUsersService(userRepo, caesar, s.appContext.Redis, s.config)
+		if err != nil {
+			return nil, err
+		}
 		s.administratorUsersService = service
 		return service, nil
 	}
@@ -119,7 +140,7 @@ func (s *serviceRegistry) RegisterAdminUsersService(userRepo *adminUsersRepo.Adm
 
 func (s *serviceRegistry) RegisterAdminUserRolesService(userRolesRepo *adminUserRolesRepo.AdminUserRolesRepository, caesar caesar.CaesarManager, config *config.Config) (administratorRolesService.AdminUserRolesService, error) {
 	if s.administratorUserRolesService == nil {
-		service := administratorRolesService.NewAdminUserRolesService(userRolesRepo, caesar, s.config)
+		service := administratorRolesService.NewAdminUserRolesService(userRolesRepo, caesar, s.appContext.Redis, s.config)
 		s.administratorUserRolesService = service
 		return service, nil
 	}
@@ -128,13 +149,40 @@ func (s *serviceRegistry) RegisterAdminUserRolesService(userRolesRepo *adminUser
 
 func (s *serviceRegistry) RegisterAdminPolicyService(policyRepo *adminPolicyRepo.AdminRolePolicyRepository, caesar caesar.CaesarManager, config *config.Config) (administratorPolicyService.AdminPolicyService, error) {
 	if s.administratorPolicyService == nil {
-		service := administratorPolicyService.NewAdminPolicyService(policyRepo, caesar, s.config)
+		service := administratorPolicyService.NewAdminPolicyService(policyRepo, caesar, s.appContext.Redis, s.config)
 		s.administratorPolicyService = service
 		return service, nil
 	}
 	return s.administratorPolicyService, nil
 }
 
+func (s *serviceRegistry) RegisterAdminSavedSearchService(queryRepo *adminQueryRepo.QueryRepository, policyService administratorPolicyService.AdminPolicyService, caesar caesar.CaesarManager, config *config.Config) (administratorSearchesService.AdminSavedSearchService, error) {
+	if s.administratorSavedSearchService == nil {
+		service := administratorSearchesService.NewAdminSavedSearchService(queryRepo, policyService, caesar, s.config)
+		s.administratorSavedSearchService = service
+		return service, nil
+	}
+	return s.administratorSavedSearchService, nil
+}
+
+func (s *serviceRegistry) RegisterAdminApprovalsService(approvalsRepo *adminApprovalsRepo.AdminApprovalsRepository, userRepo *adminUsersRepo.AdminUsersRepository, policyService administratorPolicyService.AdminPolicyService, contextMessages contextMessage.ContextMessages, caesar caesar.CaesarManager, config *config.Config) (administratorApprovalsService.AdminApprovalsService, error) {
+	if s.administratorApprovalsService == nil {
+		service := administratorApprovalsService.NewAdminApprovalsService(approvalsRepo, userRepo, policyService, contextMessages, caesar, s.config)
+		s.administratorApprovalsService = service
+		return service, nil
+	}
+	return s.administratorApprovalsService, nil
+}
+
+func (s *serviceRegistry) RegisterAdminAllowlistService(allowlistRepo *adminAllowlistRepo.AdminAllowlistRepository, userRepo *adminUsersRepo.AdminUsersRepository, nats *transport.NatsManager, config *config.Config) (administratorAllowlistService.AdminAllowlistService, error) {
+	if s.administratorAllowlistService == nil {
+		service := administratorAllowlistService.NewAdminAllowlistService(allowlistRepo, userRepo, nats, s.config)
+		s.administratorAllowlistService = service
+		return service, nil
+	}
+	return s.administratorAllowlistService, nil
+}
+
 func (s *serviceRegistry) RegisterAdminContextMessageService(config *config.Config, redis *transport.RedisManager, nats *transport.NatsManager) (contextMessage.ContextMessages, error) {
 	if s.administratorContextMessageService == nil {
 		service := contextMessage.NewAdminContextMessageService(config, redis, nats)
@@ -173,6 +221,18 @@ func (s *serviceRegistry) GetAdminPolicyService() (administratorPolicyService.Ad
 	return s.administratorPolicyService, nil
 }
 
+func (s *serviceRegistry) GetAdminSavedSearchService() (administratorSearchesService.AdminSavedSearchService, error) {
+	return s.administratorSavedSearchService, nil
+}
+
+func (s *serviceRegistry) GetAdminApprovalsService() (administratorApprovalsService.AdminApprovalsService, error) {
+	return s.administratorApprovalsService, nil
+}
+
+func (s *serviceRegistry) GetAdminAllowlistService() (administratorAllowlistService.AdminAllowlistService, error) {
+	return s.administratorAllowlistService, nil
+}
+
 func (s *serviceRegistry) GetAdminContextMessageService() (contextMessage.ContextMessages, error) {
 	return s.administratorContextMessageService, nil
 }
@@ -0,0 +1,127 @@
+package allowlist
+
+import (
+	"context"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/repository/scopes"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/twinj/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AdminAllowlistRepository interface {
+	Create(entry *models.AdministratorIPAllowlist) error
+	Update(entry *models.AdministratorIPAllowlist) error
+	Delete(entryID string) error
+	GetByID(entryID string) (*models.AdministratorIPAllowlist, error)
+	GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorIPAllowlistSearch) (*database.PaginatedResult, error)
+	GetActive(now time.Time) ([]*models.AdministratorIPAllowlist, error)
+}
+
+type repoConfig struct {
+	ServicePrefix  string
+	AllowlistTable string
+}
+
+type adminAllowlistRepository struct {
+	ctx              context.Context
+	cancel           context.CancelFunc
+	database         *gorm.DB
+	repoConfig       *repoConfig
+	logger           *zap.Logger
+	builders         builders.BuilderService
+	dslSearchEnabled bool
+}
+
+func NewGORMAdminAllowlistRepository(database *gorm.DB, servicePrefix string, config *config.Config, builders builders.BuilderService) (AdminAllowlistRepository, error) {
+	repoConfig := &repoConfig{
+		ServicePrefix:  servicePrefix,
+		AllowlistTable: servicePrefix + "_ip_allowlist"}
+
+	database.Table(repoConfig.AllowlistTable).AutoMigrate(&models.AdministratorIPAllowlist{})
+
+	err := config.PrefixService.RegisterServiceTables(servicePrefix, []string{repoConfig.AllowlistTable})
+	if err != nil {
+		return nil, err
+	}
+
+	repository := &adminAllowlistRepository{database: database, repoConfig: repoConfig, logger: config.Logger, builders: builders, dslSearchEnabled: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	repository.ctx = ctx
+	repository.cancel = cancel
+
+	return repository, nil
+}
+
+func (r *adminAllowlistRepository) Create(entry *models.AdministratorIPAllowlist) error {
+	entry.EntryID = uuid.NewV4().String()
+	return r.database.Table(r.repoConfig.AllowlistTable).Create(entry).Error
+}
+
+func (r *adminAllowlistRepository) Update(entry *models.AdministratorIPAllowlist) error {
+	return r.database.Table(r.repoConfig.AllowlistTable).Where("entry_id = ?", entry.EntryID).Updates(map[string]interface{}{
+		"cidr":       entry.CIDR,
+		"scope":      entry.Scope,
+		"scope_id":   entry.ScopeID,
+		"reason":     entry.Reason,
+		"expires_at": entry.ExpiresAt,
+		"updated_by": entry.UpdatedBy,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+func (r *adminAllowlistRepository) Delete(entryID string) error {
+	return r.database.Table(r.repoConfig.AllowlistTable).Where("entry_id = ?", entryID).Delete(&models.AdministratorIPAllowlist{}).Error
+}
+
+func (r *adminAllowlistRepository) GetByID(entryID string) (*models.AdministratorIPAllowlist, error) {
+	var entry models.AdministratorIPAllowlist
+	if err := r.database.Table(r.repoConfig.AllowlistTable).Where("entry_id = ?", entryID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *adminAllowlistRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorIPAllowlistSearch) (*database.PaginatedResult, error) {
+	var data []*models.AdministratorIPAllowlist
+
+	db := r.database.Table(r.repoConfig.AllowlistTable)
+
+	whereScope := scopes.ApplySearchFilters(searchParams, r.repoConfig.AllowlistTable, r.dslSearchEnabled)
+
+	query := db.Scopes(
+		whereScope,
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: r.repoConfig.AllowlistTable, Key: "entry_id", Model: &models.AdministratorIPAllowlist{}}),
+	)
+
+	countQuery := r.database.Table(r.repoConfig.AllowlistTable).Scopes(whereScope)
+
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
+		r.logger.Error("error counting data:", zap.Error(err))
+		return nil, err
+	}
+
+	if err := query.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "entry_id")
+	if err != nil {
+		return nil, err
+	}
+
+	return paginatedResults, nil
+}
+
+func (r *adminAllowlistRepository) GetActive(now time.Time) ([]*models.AdministratorIPAllowlist, error) {
+	var entries []*models.AdministratorIPAllowlist
+	err := r.database.Table(r.repoConfig.AllowlistTable).Where("expires_at IS NULL OR expires_at > ?", now).Find(&entries).Error
+	return entries, err
+}
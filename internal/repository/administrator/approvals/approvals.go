@@ -0,0 +1,139 @@
+package approvals
+
+import (
+	"context"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/repository/scopes"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/twinj/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AdminApprovalsRepository interface {
+	Create(approval *models.AdministratorApproval) error
+	GetByID(approvalID string) (*models.AdministratorApproval, error)
+	GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorApprovalSearch) (*database.PaginatedResult, error)
+	Decide(approval *models.AdministratorApproval) (bool, error)
+	SaveResult(approval *models.AdministratorApproval) error
+	ExpirePending(now time.Time) (int64, error)
+}
+
+type repoConfig struct {
+	ServicePrefix  string
+	ApprovalsTable string
+}
+
+type adminApprovalsRepository struct {
+	ctx              context.Context
+	cancel           context.CancelFunc
+	database         *gorm.DB
+	repoConfig       *repoConfig
+	logger           *zap.Logger
+	builders         builders.BuilderService
+	dslSearchEnabled bool
+}
+
+func NewGORMAdminApprovalsRepository(database *gorm.DB, servicePrefix string, config *config.Config, builders builders.BuilderService) (AdminApprovalsRepository, error) {
+	database.AutoMigrate(&models.AdministratorApproval{})
+	repoConfig := &repoConfig{
+		ServicePrefix:  servicePrefix,
+		ApprovalsTable: servicePrefix + "_approvals"}
+
+	err := config.PrefixService.RegisterServiceTables(servicePrefix, []string{repoConfig.ApprovalsTable})
+	if err != nil {
+		return nil, err
+	}
+
+	repository := &adminApprovalsRepository{database: database, repoConfig: repoConfig, logger: config.Logger, builders: builders, dslSearchEnabled: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	repository.ctx = ctx
+	repository.cancel = cancel
+
+	return repository, nil
+}
+
+func (r *adminApprovalsRepository) Create(approval *models.AdministratorApproval) error {
+	approval.ApprovalID = uuid.NewV4().String()
+	approval.Status = models.ApprovalStatusPending
+	return r.database.Table(r.repoConfig.ApprovalsTable).Create(approval).Error
+}
+
+func (r *adminApprovalsRepository) GetByID(approvalID string) (*models.AdministratorApproval, error) {
+	var approval models.AdministratorApproval
+	if err := r.database.Table(r.repoConfig.ApprovalsTable).Where("approval_id = ?", approvalID).First(&approval).Error; err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+func (r *adminApprovalsRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorApprovalSearch) (*database.PaginatedResult, error) {
+	var data []*models.AdministratorApproval
+
+	db := r.database.Table(r.repoConfig.ApprovalsTable)
+
+	whereScope := scopes.ApplySearchFilters(searchParams, r.repoConfig.ApprovalsTable, r.dslSearchEnabled)
+
+	query := db.Scopes(
+		whereScope,
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: r.repoConfig.ApprovalsTable, Key: "approval_id", Model: &models.AdministratorApproval{}}),
+	)
+
+	countQuery := r.database.Table(r.repoConfig.ApprovalsTable).Scopes(whereScope)
+
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
+		r.logger.Error("error counting data:", zap.Error(err))
+		return nil, err
+	}
+
+	if err := query.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "approval_id")
+	if err != nil {
+		return nil, err
+	}
+
+	return paginatedResults, nil
+}
+
+// Decide moves a pending approval to its decided status. It reports false
+// when the approval was decided or expired in the meantime, so two approvers
+// cannot both act on the same request.
+func (r *adminApprovalsRepository) Decide(approval *models.AdministratorApproval) (bool, error) {
+	result := r.database.Table(r.repoConfig.ApprovalsTable).
+		Where("approval_id = ? AND status = ? AND expires_at > ?", approval.ApprovalID, models.ApprovalStatusPending, approval.DecidedAt).
+		Updates(map[string]interface{}{
+			"status":           approval.Status,
+			"approver_id":      approval.ApproverID,
+			"rejection_reason": approval.RejectionReason,
+			"decided_at":       approval.DecidedAt,
+			"updated_at":       time.Now(),
+		})
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *adminApprovalsRepository) SaveResult(approval *models.AdministratorApproval) error {
+	return r.database.Table(r.repoConfig.ApprovalsTable).Where("approval_id = ?", approval.ApprovalID).Updates(map[string]interface{}{
+		"status":        approval.Status,
+		"result_status": approval.ResultStatus,
+		"result_body":   approval.ResultBody,
+		"updated_at":    time.Now(),
+	}).Error
+}
+
+func (r *adminApprovalsRepository) ExpirePending(now time.Time) (int64, error) {
+	result := r.database.Table(r.repoConfig.ApprovalsTable).
+		Where("status = ? AND expires_at <= ?", models.ApprovalStatusPending, now).
+		Updates(map[string]interface{}{"status": models.ApprovalStatusExpired, "updated_at": now})
+
+	return result.RowsAffected, result.Error
+}
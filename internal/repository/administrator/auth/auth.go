@@ -2,8 +2,8 @@ package auth
 
 import (
 	"context"
-	"fmt"
-	"sync"
+	"errors"
+	"sort"
 	"time"
 
 	contextMessageService "github.com/denizumutdereli/stream-admin/internal/comm/message"
@@ -11,26 +11,47 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/notifier"
 	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/denizumutdereli/stream-admin/internal/types"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/twinj/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AdminAuthRepository interface {
-	GenerateAccessToken(user *models.AdministratorUser, userAgent string) (string, int64, string, int64, error)
-	GenerateRefreshToken(user *models.AdministratorUser, userAgent string) (string, int64, error)
+	GenerateAccessToken(user *models.AdministratorUser, session *types.AdminSession) (string, int64, string, int64, error)
+	ParseAccessToken(tokenString string) (*types.AccessTokenClaims, error)
+	JWKS() types.JWKS
+	UseRefreshToken(claims *types.RefreshTokenMetadata) (*types.AdminSession, error)
+	GetSessions(userID string) ([]types.AdminSession, error)
+	RevokeSession(userID, sessionID string) error
 	RevokeTokens(userID string) error
-	RevokeRefreshToken(userID string) error
 	GeneratePasswordResetToken(userID string) (string, error)
-	SendPasswordResetEmail(email, resetToken string) error // TODO: Rotate in TTL
-	IsAccessTokenValidAndExist(userID, accessToken, userAgent string) bool
-	IsRefreshTokenValidAndExist(userID, refreshToken, userAgent string) bool
+	ConsumePasswordResetToken(resetToken string) (string, error)
+	SendPasswordResetEmail(email, resetToken string) error
+	IsAccessTokenValidAndExist(claims *types.AccessTokenClaims, tokenString string) bool
 	CheckPasswordHash(password, hash string) bool
 	HashPassword(password string) (string, error)
-	HandleUserActivity(userID string)
+	HandleUserActivity(userID, sessionID string) error
+}
+
+var (
+	ErrSessionNotFound    = errors.New("session not found or expired")
+	ErrRefreshTokenReused = errors.New("refresh token was already used")
+	ErrSessionIdle        = errors.New("idle session timeout")
+	ErrResetTokenInvalid  = errors.New("password reset token is invalid or expired")
+)
+
+// sessionRecord is what is kept of a session in redis: the session itself and
+// the ids of the only access and refresh tokens it currently accepts.
+type sessionRecord struct {
+	types.AdminSession
+	AccessTokenID  string `json:"access_token_id"`
+	RefreshTokenID string `json:"refresh_token_id"`
 }
 
 type repoConfig struct {
@@ -39,18 +60,17 @@ type repoConfig struct {
 }
 
 type adminAuthRepository struct {
-	ctx                context.Context
-	cancel             context.CancelFunc
-	config             *config.Config
-	database           *gorm.DB
-	repoConfig         *repoConfig
-	mutex              sync.RWMutex
-	sessionTimers      map[string]*time.Timer
-	sessionTimersMutex sync.Mutex
-	logger             *zap.Logger
-	builders           builders.BuilderService
-	redisClient        *transport.RedisManager
-	contextMessage     contextMessageService.ContextMessages
+	ctx            context.Context
+	cancel         context.CancelFunc
+	config         *config.Config
+	database       *gorm.DB
+	repoConfig     *repoConfig
+	logger         *zap.Logger
+	builders       builders.BuilderService
+	redisClient    *transport.RedisManager
+	contextMessage contextMessageService.ContextMessages
+	keyRing        SigningKeyRing
+	notifier       notifier.Notifier
 }
 
 func NewAuthRepository(database *gorm.DB, servicePrefix string, config *config.Config, builders builders.BuilderService, redis *transport.RedisManager, contextMessages contextMessageService.ContextMessages) (AdminAuthRepository, error) {
@@ -65,86 +85,139 @@ func NewAuthRepository(database *gorm.DB, servicePrefix string, config *config.C
 		return nil, err
 	}
 
+	notifier, err := notifier.NewNotifier(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	keyRing, err := NewSigningKeyRing(ctx, database, config)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
 	repository := &adminAuthRepository{
+		ctx:            ctx,
+		cancel:         cancel,
 		database:       database,
 		repoConfig:     repoConfig,
 		config:         config,
 		logger:         config.Logger,
 		builders:       builders,
 		redisClient:    redis,
-		sessionTimers:  make(map[string]*time.Timer),
 		contextMessage: contextMessages,
+		keyRing:        keyRing,
+		notifier:       notifier,
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	repository.ctx = ctx
-	repository.cancel = cancel
+	go repository.reloadSigningKeys()
+	go repository.watchIdleSessions()
 
 	return repository, nil
 }
 
-func (a *adminAuthRepository) GenerateAccessToken(user *models.AdministratorUser, userAgent string) (string, int64, string, int64, error) {
-	expirationTime := time.Now().Add(time.Duration(a.config.DefaultPanelAccessTokenTimeOut) * time.Minute)
+// GenerateAccessToken issues a token pair for the session, opening the session
+// when it has no ID yet. Tokens issued to the session before are not accepted
+// anymore.
+func (a *adminAuthRepository) GenerateAccessToken(user *models.AdministratorUser, session *types.AdminSession) (string, int64, string, int64, error) {
+	now := time.Now()
+	if session.SessionID == "" {
+		session.SessionID = uuid.NewV4().String()
+		session.CreatedAt = now
+	}
+	session.UserID = user.UserID
+	session.LastSeenAt = now
+
+	expirationTime := now.Add(time.Duration(a.config.DefaultPanelAccessTokenTimeOut) * time.Minute)
 
 	roleName := "superAdmin"
 
 	claims := &types.AccessTokenClaims{
 		UserID:    user.UserID,
-		UserAgent: userAgent,
+		UserAgent: session.UserAgent,
 		UserRole:  roleName,
 		RoleID:    user.UserRole,
+		SessionID: session.SessionID,
 		TokenType: "access_token",
+
+		PasswordChangeRequired: user.MustChangePassword,
 		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewV4().String(),
 			ExpiresAt: expirationTime.Unix(),
 			Subject:   user.UserID,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(a.config.SecretJWTToken))
+	tokenString, err := a.keyRing.Sign(claims)
 	if err != nil {
 		return "", 0, "", 0, err
 	}
 
-	redisKey := fmt.Sprintf("access_token:%s", user.UserID)
-	tokenData := map[string]interface{}{
-		"token":      tokenString,
-		"user_agent": userAgent,
-		"issued_at":  time.Now().Unix(),
-	}
-
-	refreshToken, refreshTokenExpiresIn, err := a.GenerateRefreshToken(user, userAgent)
+	refreshToken, refreshTokenID, refreshTokenExpiresIn, err := a.generateRefreshToken(user, session)
 	if err != nil {
 		return "", 0, "", 0, err
 	}
 
-	err = a.redisClient.SetKeyValue(a.ctx, redisKey, tokenData,
-		time.Duration(a.config.DefaultPanelRefreshTokenTimeOut)*time.Minute)
-	if err != nil {
-		return "", 0, "", 0, err
+	record := &sessionRecord{
+		AdminSession:   *session,
+		AccessTokenID:  claims.Id,
+		RefreshTokenID: refreshTokenID,
 	}
 
-	//a.logger.Debug("user timer has started")
-	a.resetUserTimer(user.UserID)
+	if err := a.saveSession(record); err != nil {
+		return "", 0, "", 0, err
+	}
 
-	if err != nil {
-		a.logger.Error("failed to set context message", zap.Error(err))
+	if err := a.armIdleTimeout(user.UserID, session.SessionID); err != nil {
+		return "", 0, "", 0, err
 	}
 
 	return tokenString, expirationTime.Unix(), refreshToken, refreshTokenExpiresIn, nil
 }
 
-func (a *adminAuthRepository) GenerateRefreshToken(user *models.AdministratorUser, userAgent string) (string, int64, error) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
+func (a *adminAuthRepository) ParseAccessToken(tokenString string) (*types.AccessTokenClaims, error) {
+	return a.keyRing.ParseAccessToken(tokenString)
+}
 
-	expirationTime := time.Now().Add(time.Duration(a.config.DefaultPanelRefreshTokenTimeOut) * time.Minute) // TODO: from config
+func (a *adminAuthRepository) JWKS() types.JWKS {
+	return a.keyRing.JWKS()
+}
+
+// reloadSigningKeys picks up keys rotated by other instances or the rotation
+// command.
+func (a *adminAuthRepository) reloadSigningKeys() {
+	ticker := time.NewTicker(time.Duration(a.config.JWTRules.ReloadInSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.keyRing.Reload(); err != nil {
+				a.logger.Error("unable to reload signing keys", zap.Error(err))
+			}
+		}
+	}
+}
+
+// generateRefreshToken signs the refresh token with SECRET_REFRESH_TOKEN
+// rather than the key ring. Refresh tokens are only ever verified here, never
+// by the services trusting the JWKS, and are bound to a session record that
+// accepts each token id once, so a public key would only let others verify a
+// token that is worthless to them.
+func (a *adminAuthRepository) generateRefreshToken(user *models.AdministratorUser, session *types.AdminSession) (string, string, int64, error) {
+	expirationTime := time.Now().Add(time.Duration(a.config.DefaultPanelRefreshTokenTimeOut) * time.Minute)
 
 	claims := &types.RefreshTokenMetadata{
 		UserID:    user.UserID,
-		UserAgent: userAgent,
+		UserAgent: session.UserAgent,
+		SessionID: session.SessionID,
 		TokenType: "refresh_token",
 		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewV4().String(),
 			ExpiresAt: expirationTime.Unix(),
 			Subject:   user.UserID,
 		},
@@ -153,97 +226,141 @@ func (a *adminAuthRepository) GenerateRefreshToken(user *models.AdministratorUse
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(a.config.SecretRefreshToken))
 	if err != nil {
-		return "", 0, err
+		return "", "", 0, err
 	}
 
-	redisKey := fmt.Sprintf("refresh_token:%s", user.UserID)
-	tokenData := map[string]interface{}{
-		"token":      tokenString,
-		"user_agent": userAgent,
-		"token_type": "refresh_token",
-		"issued_at":  time.Now().Unix(),
-	}
-	err = a.redisClient.SetKeyValue(a.ctx, redisKey, tokenData,
-		time.Duration(a.config.DefaultPanelRefreshTokenTimeOut)*time.Minute)
+	return tokenString, claims.Id, expirationTime.Unix(), nil
+}
+
+// UseRefreshToken spends a refresh token of a session. Each refresh token is
+// accepted once; presenting one that was already rotated means it leaked, so
+// the whole session is revoked along with every token descending from it.
+func (a *adminAuthRepository) UseRefreshToken(claims *types.RefreshTokenMetadata) (*types.AdminSession, error) {
+	record, err := a.getSession(claims.SessionID)
 	if err != nil {
-		return "", 0, err
+		return nil, err
 	}
 
-	return tokenString, expirationTime.Unix(), nil
-}
-
-func (a *adminAuthRepository) RevokeTokens(userID string) error {
-	redisKey := fmt.Sprintf("access_token:%s", userID)
+	if record.UserID != claims.UserID {
+		return nil, ErrSessionNotFound
+	}
 
-	err := a.redisClient.DeleteKey(a.ctx, redisKey)
+	ttl := time.Duration(a.config.DefaultPanelRefreshTokenTimeOut) * time.Minute
+	fresh, err := a.redisClient.Client.SetNX(a.ctx, refreshTokenUsedKey(claims.Id), claims.SessionID, ttl).Result()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = a.RevokeRefreshToken(userID)
-	if err != nil {
-		return err
+	if !fresh || record.RefreshTokenID != claims.Id {
+		a.logger.Warn("refresh token reuse detected, revoking the session", zap.String("user_id", claims.UserID), zap.String("session_id", claims.SessionID))
+		if err := a.RevokeSession(claims.UserID, claims.SessionID); err != nil {
+			a.logger.Error("failed to revoke the session of a reused refresh token", zap.Error(err))
+		}
+		return nil, ErrRefreshTokenReused
 	}
-	return nil
+
+	return &record.AdminSession, nil
 }
 
-func (a *adminAuthRepository) RevokeRefreshToken(userID string) error {
-	redisKey := fmt.Sprintf("refresh_token:%s", userID)
+func (a *adminAuthRepository) GetSessions(userID string) ([]types.AdminSession, error) {
+	sessionIDs, err := a.redisClient.Client.SMembers(a.ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
 
-	err := a.redisClient.DeleteKey(a.ctx, redisKey)
-	return err
-}
+	sessions := make([]types.AdminSession, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		record, err := a.getSession(sessionID)
+		if err == ErrSessionNotFound {
+			a.redisClient.Client.SRem(a.ctx, userSessionsKey(userID), sessionID)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, record.AdminSession)
+	}
 
-// Mock
-func (a *adminAuthRepository) GeneratePasswordResetToken(userID string) (string, error) {
-	resetToken := fmt.Sprintf("reset_%s.%d", userID, time.Now().UnixNano())
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
 
-	expiration := time.Duration(1) * time.Hour
+	return sessions, nil
+}
 
-	err := a.redisClient.SetKeyValue(a.ctx, resetToken, userID,
-		expiration) // TODO: config
+// RevokeSession signs a session out. Legacy tokens have no session and were
+// kept one per user, so their sign out drops the per-user key instead.
+func (a *adminAuthRepository) RevokeSession(userID, sessionID string) error {
+	if sessionID == "" {
+		return a.redisClient.DeleteKey(a.ctx, legacyAccessTokenKey(userID))
+	}
 
+	deleted, err := a.redisClient.Client.Del(a.ctx, sessionKey(sessionID)).Result()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return resetToken, nil
-}
+	if err := a.redisClient.Client.SRem(a.ctx, userSessionsKey(userID), sessionID).Err(); err != nil {
+		return err
+	}
 
-// Mock
-func (a *adminAuthRepository) SendPasswordResetEmail(email, resetToken string) error {
+	a.disarmIdleTimeout(userID, sessionID)
+
+	if deleted == 0 {
+		return ErrSessionNotFound
+	}
 	return nil
 }
 
-func (a *adminAuthRepository) IsAccessTokenValidAndExist(userID, accessToken, userAgent string) bool {
-	var storedAccessToken types.AccessTokenClaims
-	redisKey := fmt.Sprintf("access_token:%s", userID)
-
-	err := a.redisClient.GetKeyValue(a.ctx, redisKey, &storedAccessToken)
+// RevokeTokens signs the user out of every session.
+func (a *adminAuthRepository) RevokeTokens(userID string) error {
+	sessionIDs, err := a.redisClient.Client.SMembers(a.ctx, userSessionsKey(userID)).Result()
 	if err != nil {
-		return false
+		return err
 	}
-	isTokenExist := storedAccessToken.Token == accessToken
-	isUserAgentMatching := storedAccessToken.UserAgent == userAgent
 
-	return isTokenExist && isUserAgentMatching
+	for _, sessionID := range sessionIDs {
+		if err := a.redisClient.DeleteKey(a.ctx, sessionKey(sessionID)); err != nil {
+			return err
+		}
+		a.disarmIdleTimeout(userID, sessionID)
+	}
+
+	if err := a.redisClient.DeleteKey(a.ctx, legacyAccessTokenKey(userID)); err != nil {
+		return err
+	}
+
+	return a.redisClient.DeleteKey(a.ctx, userSessionsKey(userID))
 }
 
-func (a *adminAuthRepository) IsRefreshTokenValidAndExist(userID, refreshToken, userAgent string) bool {
-	var storedRefreshToken types.RefreshTokenMetadata
-	redisKey := fmt.Sprintf("refresh_token:%s", userID)
+// IsAccessTokenValidAndExist checks the token against the session it was
+// issued to. Legacy tokens have no session and are checked against the
+// per-user key they were stored under, which the key ring accepts only until
+// they could have expired.
+func (a *adminAuthRepository) IsAccessTokenValidAndExist(claims *types.AccessTokenClaims, tokenString string) bool {
+	if claims.SessionID == "" {
+		return a.isLegacyAccessTokenValid(claims, tokenString)
+	}
 
-	err := a.redisClient.GetKeyValue(a.ctx, redisKey, &storedRefreshToken)
+	record, err := a.getSession(claims.SessionID)
 	if err != nil {
 		return false
 	}
 
-	isTokenExist := storedRefreshToken.Token == refreshToken
-	isUserAgentMatching := storedRefreshToken.UserAgent == userAgent
+	isTokenExist := record.UserID == claims.UserID && record.AccessTokenID == claims.Id
+	isUserAgentMatching := record.UserAgent == claims.UserAgent
 
 	return isTokenExist && isUserAgentMatching
 }
 
+func (a *adminAuthRepository) isLegacyAccessTokenValid(claims *types.AccessTokenClaims, tokenString string) bool {
+	var stored types.TokenMetadata
+	if err := a.redisClient.GetKeyValue(a.ctx, legacyAccessTokenKey(claims.UserID), &stored); err != nil {
+		return false
+	}
+
+	return stored.Token == tokenString && stored.UserAgent == claims.UserAgent
+}
+
 func (a *adminAuthRepository) CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
@@ -257,36 +374,75 @@ func (a *adminAuthRepository) HashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-func (a *adminAuthRepository) resetUserTimer(userID string) {
-	a.sessionTimersMutex.Lock()
-	defer a.sessionTimersMutex.Unlock()
+func (a *adminAuthRepository) saveSession(record *sessionRecord) error {
+	ttl := time.Duration(a.config.DefaultPanelRefreshTokenTimeOut) * time.Minute
 
-	if timer, exists := a.sessionTimers[userID]; exists {
-		timer.Stop()
+	if err := a.redisClient.SetKeyValue(a.ctx, sessionKey(record.SessionID), record, ttl); err != nil {
+		return err
 	}
 
-	//a.logger.Debug("user timer triggered...")
+	if err := a.redisClient.Client.SAdd(a.ctx, userSessionsKey(record.UserID), record.SessionID).Err(); err != nil {
+		return err
+	}
 
-	a.sessionTimers[userID] = time.AfterFunc(time.Duration(a.config.DefaultPanelIdleSessionTimeOut*int(time.Minute)), func() {
-		a.logger.Debug("user timout, logging out")
-		var redisTimeout int = 1
-		err := a.contextMessage.SetContextualMessage(&types.ContextualMessage{
-			UserId:                userID,
-			MessageType:           "token",
-			Message:               "idle session timeout",
-			RedisDelivery:         true,
-			NatsDelivery:          true,
-			RedisTimeoutInMinutes: &redisTimeout,
-		})
+	return a.redisClient.Client.Expire(a.ctx, userSessionsKey(record.UserID), ttl).Err()
+}
 
-		if err != nil {
-			a.logger.Error("contextual message error", zap.Error(err))
-		}
+func (a *adminAuthRepository) getSession(sessionID string) (*sessionRecord, error) {
+	var record sessionRecord
+	err := a.redisClient.GetKeyValue(a.ctx, sessionKey(sessionID), &record)
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
 
-		a.RevokeTokens(userID)
-	})
+// touchSessionScript sets last_seen_at of a session record and leaves the rest
+// of it as it is in redis, so a refresh rotating the token ids in between is
+// not undone. A revoked session is not recreated.
+var touchSessionScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if not value then
+	return 0
+end
+
+local record = cjson.decode(value)
+record["last_seen_at"] = ARGV[1]
+redis.call("SET", KEYS[1], cjson.encode(record), "KEEPTTL")
+
+return 1
+`)
+
+// touchSession records the activity of a session, at most once a minute so
+// requests do not all write to redis.
+func (a *adminAuthRepository) touchSession(sessionID string) {
+	record, err := a.getSession(sessionID)
+	if err != nil || time.Since(record.LastSeenAt) < time.Minute {
+		return
+	}
+
+	lastSeenAt := time.Now().Format(time.RFC3339Nano)
+	if err := touchSessionScript.Run(a.ctx, a.redisClient.Client, []string{sessionKey(sessionID)}, lastSeenAt).Err(); err != nil {
+		a.logger.Error("failed to update the session activity", zap.String("session_id", sessionID), zap.Error(err))
+	}
+}
+
+func sessionKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+func userSessionsKey(userID string) string {
+	return "sessions:" + userID
+}
+
+// legacyAccessTokenKey holds the only access token of a user from before
+// sessions.
+func legacyAccessTokenKey(userID string) string {
+	return "access_token:" + userID
 }
 
-func (a *adminAuthRepository) HandleUserActivity(userID string) {
-	a.resetUserTimer(userID)
+func refreshTokenUsedKey(tokenID string) string {
+	return "refresh_used:" + tokenID
 }
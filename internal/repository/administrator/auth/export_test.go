@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+)
+
+// NewTestAuthRepository builds the repository on a key ring held in memory,
+// for the tests that cannot import the package from the inside.
+func NewTestAuthRepository(config *config.Config, redis *transport.RedisManager, legacyUntil int64) (AdminAuthRepository, error) {
+	record, err := newSigningKeyRecord(config.JWTRules.Algorithm, config.SecretJWTToken)
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &signingKeyRing{config: config, logger: config.Logger, legacyUntil: legacyUntil}
+
+	key, err := ring.decodeSigningKey(record)
+	if err != nil {
+		return nil, err
+	}
+
+	ring.active = key
+	ring.keys = map[string]*signingKey{key.id: key}
+	ring.order = []string{key.id}
+
+	return &adminAuthRepository{
+		ctx:         context.Background(),
+		config:      config,
+		logger:      config.Logger,
+		redisClient: redis,
+		keyRing:     ring,
+	}, nil
+}
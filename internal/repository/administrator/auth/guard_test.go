@@ -0,0 +1,225 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/middleware"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	administratorAuthRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	testSecret    = "test-secret-of-the-shared-jwt-key"
+	testUserID    = "user-1"
+	testUserAgent = "test-agent"
+)
+
+// noContextMessages has no reason to give for a rejected token.
+type noContextMessages struct{}
+
+func (noContextMessages) SetContextualMessage(*types.ContextualMessage) error { return nil }
+func (noContextMessages) GetContextualMessage(string, string, ...bool) (types.ContextualMessage, error) {
+	return types.ContextualMessage{}, nil
+}
+func (noContextMessages) BuildTopicName(string) string                            { return "" }
+func (noContextMessages) SendToNats(string, []byte) error                         { return nil }
+func (noContextMessages) SetListener(string) chan types.ContextualMessage         { return nil }
+func (noContextMessages) GetListener(string) (chan types.ContextualMessage, bool) { return nil, false }
+func (noContextMessages) RemoveListener(string)                                   {}
+func (noContextMessages) CleanupListeners()                                       {}
+
+func newTestConfig(algorithm string) *config.Config {
+	return &config.Config{
+		Logger:                          zap.NewNop(),
+		SecretJWTToken:                  testSecret,
+		DefaultTickerInterval:           1000,
+		DefaultPanelIdleSessionTimeOut:  15,
+		DefaultPanelAccessTokenTimeOut:  15,
+		DefaultPanelRefreshTokenTimeOut: 60,
+		JWTRules:                        &config.JWTRules{Algorithm: algorithm},
+		PolicyRules:                     &config.PolicyRules{},
+	}
+}
+
+func newTestRepository(t *testing.T, algorithm string, legacyUntil time.Time) (administratorAuthRepo.AdminAuthRepository, *miniredis.Miniredis, *config.Config) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	cnf := newTestConfig(algorithm)
+
+	redis, err := transport.NewRedisManager("redis://"+server.Addr(), cnf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := administratorAuthRepo.NewTestAuthRepository(cnf, redis, legacyUntil.Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repository, server, cnf
+}
+
+// legacyToken signs a token the way access tokens were signed before the key
+// ring: HS256 with the shared secret, no kid and no session.
+func legacyToken(t *testing.T, expiresAt time.Time) string {
+	t.Helper()
+
+	claims := &types.AccessTokenClaims{
+		UserID:    testUserID,
+		UserAgent: testUserAgent,
+		TokenType: "access_token",
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+			Subject:   testUserID,
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func storeLegacyToken(t *testing.T, server *miniredis.Miniredis, token, userAgent string) {
+	t.Helper()
+
+	value := `{"token":"` + token + `","user_agent":"` + userAgent + `","issued_at":0}`
+	if err := server.Set("access_token:"+testUserID, value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func guard(repository administratorAuthRepo.AdminAuthRepository, cnf *config.Config, token string) (int, string) {
+	gin.SetMode(gin.TestMode)
+
+	var sessionID string
+	router := gin.New()
+	router.GET("/", middleware.NewGuardMiddleware(cnf, repository, noContextMessages{}).Guard(), func(c *gin.Context) {
+		sessionID = c.GetString(string(types.ContextSessionID))
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder.Code, sessionID
+}
+
+func TestGuardSigningKeyTokens(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "ES256"} {
+		t.Run(algorithm, func(t *testing.T) {
+			repository, _, cnf := newTestRepository(t, algorithm, time.Now())
+
+			user := &models.AdministratorUser{UserID: testUserID}
+			session := &types.AdminSession{UserAgent: testUserAgent}
+
+			token, _, _, _, err := repository.GenerateAccessToken(user, session)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			code, sessionID := guard(repository, cnf, token)
+			if code != http.StatusOK || sessionID != session.SessionID {
+				t.Fatalf("Guard() = %d with session %q, want 200 with session %q", code, sessionID, session.SessionID)
+			}
+
+			if err := repository.RevokeSession(testUserID, session.SessionID); err != nil {
+				t.Fatal(err)
+			}
+
+			if code, _ := guard(repository, cnf, token); code != http.StatusUnauthorized {
+				t.Errorf("Guard() after revoking the session = %d, want 401", code)
+			}
+		})
+	}
+}
+
+func TestGuardLegacyTokens(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		legacyUntil time.Time
+		expiresAt   time.Time
+		stored      bool
+		userAgent   string
+		want        int
+	}{
+		{
+			name:        "stored token is accepted",
+			legacyUntil: now.Add(15 * time.Minute),
+			expiresAt:   now.Add(10 * time.Minute),
+			stored:      true,
+			userAgent:   testUserAgent,
+			want:        http.StatusOK,
+		},
+		{
+			name:        "revoked token is rejected",
+			legacyUntil: now.Add(15 * time.Minute),
+			expiresAt:   now.Add(10 * time.Minute),
+			want:        http.StatusUnauthorized,
+		},
+		{
+			name:        "token stored for another user agent is rejected",
+			legacyUntil: now.Add(15 * time.Minute),
+			expiresAt:   now.Add(10 * time.Minute),
+			stored:      true,
+			userAgent:   "other-agent",
+			want:        http.StatusUnauthorized,
+		},
+		{
+			name:        "token outliving the migration is rejected",
+			legacyUntil: now.Add(5 * time.Minute),
+			expiresAt:   now.Add(10 * time.Minute),
+			stored:      true,
+			userAgent:   testUserAgent,
+			want:        http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, server, cnf := newTestRepository(t, "ES256", tt.legacyUntil)
+
+			token := legacyToken(t, tt.expiresAt)
+			if tt.stored {
+				storeLegacyToken(t, server, token, tt.userAgent)
+			}
+
+			if code, _ := guard(repository, cnf, token); code != tt.want {
+				t.Errorf("Guard() = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokeTokensSignsLegacyTokensOut(t *testing.T) {
+	repository, server, cnf := newTestRepository(t, "ES256", time.Now().Add(15*time.Minute))
+
+	token := legacyToken(t, time.Now().Add(10*time.Minute))
+	storeLegacyToken(t, server, token, testUserAgent)
+
+	if err := repository.RevokeTokens(testUserID); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _ := guard(repository, cnf, token); code != http.StatusUnauthorized {
+		t.Errorf("Guard() after RevokeTokens = %d, want 401", code)
+	}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	idleDeadlinesKey = "idle_session_deadlines"
+	idleWarningsKey  = "idle_session_warnings"
+
+	idleSweepInterval = 5 * time.Second
+)
+
+// HandleUserActivity slides the idle deadline of the session. A session whose
+// deadline has passed before the sweeper got to it is signed out here.
+func (a *adminAuthRepository) HandleUserActivity(userID, sessionID string) error {
+	member := idleMember(userID, sessionID)
+
+	deadline, err := a.redisClient.Client.ZScore(a.ctx, idleDeadlinesKey, member).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	if err == nil && int64(deadline) <= time.Now().UnixMilli() {
+		// the sweeper may be claiming it at the same time
+		if removed, err := a.redisClient.Client.ZRem(a.ctx, idleDeadlinesKey, member).Result(); err == nil && removed > 0 {
+			a.expireIdleSession(userID, sessionID)
+		}
+		return ErrSessionIdle
+	}
+
+	if err := a.armIdleTimeout(userID, sessionID); err != nil {
+		return err
+	}
+
+	a.touchSession(sessionID)
+
+	return nil
+}
+
+// armIdleTimeout sets when the session is warned and when it is signed out if
+// no further activity comes in. The deadlines live in redis so any instance
+// can act on them.
+func (a *adminAuthRepository) armIdleTimeout(userID, sessionID string) error {
+	now := time.Now()
+	idleTimeout := time.Duration(a.config.DefaultPanelIdleSessionTimeOut) * time.Minute
+	deadline := now.Add(idleTimeout)
+	member := idleMember(userID, sessionID)
+
+	pipe := a.redisClient.Client.TxPipeline()
+	pipe.ZAdd(a.ctx, idleDeadlinesKey, &redis.Z{Score: float64(deadline.UnixMilli()), Member: member})
+
+	if warnBefore := a.idleWarningLead(); warnBefore > 0 {
+		pipe.ZAdd(a.ctx, idleWarningsKey, &redis.Z{Score: float64(deadline.Add(-warnBefore).UnixMilli()), Member: member})
+	} else {
+		pipe.ZRem(a.ctx, idleWarningsKey, member)
+	}
+
+	_, err := pipe.Exec(a.ctx)
+	return err
+}
+
+func (a *adminAuthRepository) disarmIdleTimeout(userID, sessionID string) {
+	member := idleMember(userID, sessionID)
+
+	pipe := a.redisClient.Client.TxPipeline()
+	pipe.ZRem(a.ctx, idleDeadlinesKey, member)
+	pipe.ZRem(a.ctx, idleWarningsKey, member)
+
+	if _, err := pipe.Exec(a.ctx); err != nil {
+		a.logger.Error("unable to clear the idle timeout", zap.String("session_id", sessionID), zap.Error(err))
+	}
+}
+
+// idleWarningLead is how long before the idle sign-out the dashboard is
+// warned, never more than the idle timeout itself.
+func (a *adminAuthRepository) idleWarningLead() time.Duration {
+	lead := a.config.PolicyRules.IdleWarning
+	if lead >= a.config.DefaultPanelIdleSessionTimeOut {
+		lead = a.config.DefaultPanelIdleSessionTimeOut - 1
+	}
+	if lead <= 0 {
+		return 0
+	}
+
+	return time.Duration(lead) * time.Minute
+}
+
+// watchIdleSessions sends the due warnings and signs idle sessions out, also
+// when their users send no further request.
+func (a *adminAuthRepository) watchIdleSessions() {
+	ticker := time.NewTicker(idleSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.sweepIdle(idleWarningsKey, a.warnIdleSession)
+			a.sweepIdle(idleDeadlinesKey, a.expireIdleSession)
+		}
+	}
+}
+
+// sweepIdle handles the sessions of a set that are due. Removing a member
+// claims it, so each session is handled by a single instance.
+func (a *adminAuthRepository) sweepIdle(key string, handle func(userID, sessionID string)) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	members, err := a.redisClient.Client.ZRangeByScore(a.ctx, key, &redis.ZRangeBy{Min: "-inf", Max: now}).Result()
+	if err != nil {
+		a.logger.Error("unable to read idle sessions", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	for _, member := range members {
+		removed, err := a.redisClient.Client.ZRem(a.ctx, key, member).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+
+		userID, sessionID, ok := splitIdleMember(member)
+		if !ok {
+			continue
+		}
+
+		handle(userID, sessionID)
+	}
+}
+
+func (a *adminAuthRepository) warnIdleSession(userID, sessionID string) {
+	err := a.contextMessage.SetContextualMessage(&types.ContextualMessage{
+		UserId:       userID,
+		SessionId:    sessionID,
+		MessageType:  "idle_warning",
+		Message:      fmt.Sprintf("session will be signed out in %d minutes without activity", int(a.idleWarningLead().Minutes())),
+		NatsDelivery: true,
+		IssuedAt:     time.Now().Unix(),
+	})
+	if err != nil {
+		a.logger.Error("contextual message error", zap.Error(err))
+	}
+}
+
+// expireIdleSession signs the session out and leaves the reason for the next
+// request of the user to find.
+func (a *adminAuthRepository) expireIdleSession(userID, sessionID string) {
+	a.logger.Debug("idle session timeout, signing out", zap.String("user_id", userID), zap.String("session_id", sessionID))
+
+	var redisTimeout int = 1
+	err := a.contextMessage.SetContextualMessage(&types.ContextualMessage{
+		UserId:                userID,
+		SessionId:             sessionID,
+		MessageType:           "token",
+		Message:               ErrSessionIdle.Error(),
+		RedisDelivery:         true,
+		NatsDelivery:          true,
+		RedisTimeoutInMinutes: &redisTimeout,
+		IssuedAt:              time.Now().Unix(),
+	})
+	if err != nil {
+		a.logger.Error("contextual message error", zap.Error(err))
+	}
+
+	if err := a.RevokeSession(userID, sessionID); err != nil && err != ErrSessionNotFound {
+		a.logger.Error("unable to revoke the idle session", zap.String("session_id", sessionID), zap.Error(err))
+	}
+}
+
+func idleMember(userID, sessionID string) string {
+	return userID + ":" + sessionID
+}
+
+func splitIdleMember(member string) (string, string, bool) {
+	parts := strings.SplitN(member, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
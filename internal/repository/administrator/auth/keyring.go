@@ -0,0 +1,450 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/caesar"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/twinj/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// signingKeyLockID serializes key ring changes between instances
+	signingKeyLockID = 72016017
+
+	// unknown key ids reload the ring at most this often
+	signingKeyMissReload = 5 * time.Second
+)
+
+var (
+	ErrNoSigningKey      = errors.New("no active signing key")
+	ErrUnknownSigningKey = errors.New("token is signed with an unknown key")
+)
+
+// SigningKeyRing signs access tokens with the active key and verifies them
+// against the active and previous keys, picked by the kid header.
+type SigningKeyRing interface {
+	Sign(claims jwt.Claims) (string, error)
+	ParseAccessToken(tokenString string) (*types.AccessTokenClaims, error)
+	Rotate() (*models.AdministratorSigningKey, error)
+	Reload() error
+	JWKS() types.JWKS
+}
+
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	jwk     types.JWK
+}
+
+type signingKeyRing struct {
+	ctx      context.Context
+	database *gorm.DB
+	config   *config.Config
+	logger   *zap.Logger
+
+	mutex      sync.RWMutex
+	active     *signingKey
+	keys       map[string]*signingKey
+	order      []string
+	lastReload time.Time
+
+	// HS256 tokens issued before the key ring expire before legacyUntil
+	legacyUntil int64
+}
+
+// NewSigningKeyRing loads the signing keys, creating the first one when the
+// ring is empty.
+func NewSigningKeyRing(ctx context.Context, database *gorm.DB, config *config.Config) (SigningKeyRing, error) {
+	database.AutoMigrate(&models.AdministratorSigningKey{})
+
+	if _, err := signingMethod(config.JWTRules.Algorithm); err != nil {
+		return nil, err
+	}
+
+	ring := &signingKeyRing{
+		ctx:      ctx,
+		database: database,
+		config:   config,
+		logger:   config.Logger,
+		keys:     make(map[string]*signingKey),
+	}
+
+	if err := ring.bootstrap(); err != nil {
+		return nil, err
+	}
+
+	if err := ring.Reload(); err != nil {
+		return nil, err
+	}
+
+	return ring, nil
+}
+
+func (r *signingKeyRing) Sign(claims jwt.Claims) (string, error) {
+	r.mutex.RLock()
+	active := r.active
+	r.mutex.RUnlock()
+
+	if active == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(active.method, claims)
+	token.Header["kid"] = active.id
+
+	return token.SignedString(active.private)
+}
+
+// ParseAccessToken verifies an access token. Tokens without a kid are the
+// HS256 tokens of before the key ring and are only accepted while those can
+// still be unexpired.
+func (r *signingKeyRing) ParseAccessToken(tokenString string) (*types.AccessTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &types.AccessTokenClaims{}, r.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*types.AccessTokenClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid access token")
+	}
+
+	return claims, nil
+}
+
+func (r *signingKeyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return r.legacyKey(token)
+	}
+
+	key, err := r.key(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.private.Public(), nil
+}
+
+func (r *signingKeyRing) legacyKey(token *jwt.Token) (interface{}, error) {
+	if token.Method != jwt.SigningMethodHS256 {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	claims, ok := token.Claims.(*types.AccessTokenClaims)
+	if !ok || claims.ExpiresAt == 0 || claims.ExpiresAt > r.legacyUntil {
+		return nil, errors.New("legacy tokens are not accepted anymore")
+	}
+
+	return []byte(r.config.SecretJWTToken), nil
+}
+
+func (r *signingKeyRing) key(kid string) (*signingKey, error) {
+	r.mutex.RLock()
+	key, ok := r.keys[kid]
+	stale := time.Since(r.lastReload) > signingKeyMissReload
+	r.mutex.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	// another instance may have rotated since the last reload
+	if stale {
+		if err := r.Reload(); err != nil {
+			r.logger.Error("unable to reload signing keys", zap.Error(err))
+		}
+
+		r.mutex.RLock()
+		key, ok = r.keys[kid]
+		r.mutex.RUnlock()
+
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, ErrUnknownSigningKey
+}
+
+// Rotate makes a new key active. The active key becomes a previous key and
+// keeps verifying until the tokens it signed have expired.
+func (r *signingKeyRing) Rotate() (*models.AdministratorSigningKey, error) {
+	record, err := newSigningKeyRecord(r.config.JWTRules.Algorithm, r.config.SecretJWTToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	retiresAt := now.Add(r.previousKeyRetention())
+
+	err = r.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.AdministratorSigningKey{}).
+			Where("status = ? AND retires_at <= ?", models.SigningKeyPrevious, now).
+			Update("status", models.SigningKeyRetired).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.AdministratorSigningKey{}).
+			Where("status = ?", models.SigningKeyActive).
+			Updates(map[string]interface{}{"status": models.SigningKeyPrevious, "retires_at": retiresAt}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Reload reads the keys that still verify tokens from the database.
+func (r *signingKeyRing) Reload() error {
+	var records []models.AdministratorSigningKey
+	err := r.database.WithContext(r.ctx).
+		Where("status = ? OR (status = ? AND retires_at > ?)", models.SigningKeyActive, models.SigningKeyPrevious, time.Now()).
+		Order("created_at DESC").
+		Find(&records).Error
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*signingKey, len(records))
+	order := make([]string, 0, len(records))
+	var active *signingKey
+
+	for i := range records {
+		key, err := r.decodeSigningKey(&records[i])
+		if err != nil {
+			r.logger.Error("unable to load signing key", zap.String("kid", records[i].KeyID), zap.Error(err))
+			continue
+		}
+
+		keys[key.id] = key
+		order = append(order, key.id)
+
+		// the newest active key wins should two instances have created one
+		if active == nil && records[i].Status == models.SigningKeyActive {
+			active = key
+		}
+	}
+
+	if active == nil {
+		return ErrNoSigningKey
+	}
+
+	r.mutex.Lock()
+	r.active = active
+	r.keys = keys
+	r.order = order
+	r.lastReload = time.Now()
+	r.mutex.Unlock()
+
+	return nil
+}
+
+// JWKS lists the public keys of the ring, the active key first.
+func (r *signingKeyRing) JWKS() types.JWKS {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	jwks := types.JWKS{Keys: make([]types.JWK, 0, len(r.order))}
+	for _, kid := range r.order {
+		jwks.Keys = append(jwks.Keys, r.keys[kid].jwk)
+	}
+
+	return jwks
+}
+
+// bootstrap creates the first key of an empty ring and fixes until when
+// tokens signed with the shared secret can still be valid.
+func (r *signingKeyRing) bootstrap() error {
+	err := r.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockID).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&models.AdministratorSigningKey{}).Where("status = ?", models.SigningKeyActive).Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return nil
+		}
+
+		record, err := newSigningKeyRecord(r.config.JWTRules.Algorithm, r.config.SecretJWTToken)
+		if err != nil {
+			return err
+		}
+
+		r.logger.Info("creating the first access token signing key", zap.String("kid", record.KeyID), zap.String("alg", record.Algorithm))
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	var first models.AdministratorSigningKey
+	if err := r.database.Order("created_at ASC").First(&first).Error; err != nil {
+		return err
+	}
+
+	r.legacyUntil = first.CreatedAt.Add(time.Duration(r.config.DefaultPanelAccessTokenTimeOut) * time.Minute).Unix()
+
+	return nil
+}
+
+// previousKeyRetention covers the tokens instances keep signing with the old
+// key until their next reload.
+func (r *signingKeyRing) previousKeyRetention() time.Duration {
+	minutes := r.config.JWTRules.PreviousKeyInMinutes
+	if minutes < r.config.DefaultPanelAccessTokenTimeOut {
+		minutes = r.config.DefaultPanelAccessTokenTimeOut
+	}
+
+	return time.Duration(minutes)*time.Minute + time.Duration(r.config.JWTRules.ReloadInSeconds)*time.Second
+}
+
+func (r *signingKeyRing) decodeSigningKey(record *models.AdministratorSigningKey) (*signingKey, error) {
+	method, err := signingMethod(record.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	privatePEM, err := caesar.Open(r.config.SecretJWTToken, record.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, errors.New("private key is not pem encoded")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{
+		id:     record.KeyID,
+		method: method,
+		jwk: types.JWK{
+			Kid: record.KeyID,
+			Use: "sig",
+			Alg: record.Algorithm,
+		},
+	}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if _, ok := method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("rsa key can not sign %s", record.Algorithm)
+		}
+		key.private = private
+		key.jwk.Kty = "RSA"
+		key.jwk.N = base64.RawURLEncoding.EncodeToString(private.N.Bytes())
+		key.jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(private.E)).Bytes())
+
+	case *ecdsa.PrivateKey:
+		if _, ok := method.(*jwt.SigningMethodECDSA); !ok || private.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ecdsa key can not sign %s", record.Algorithm)
+		}
+		key.private = private
+		key.jwk.Kty = "EC"
+		key.jwk.Crv = "P-256"
+		key.jwk.X = base64.RawURLEncoding.EncodeToString(private.X.FillBytes(make([]byte, 32)))
+		key.jwk.Y = base64.RawURLEncoding.EncodeToString(private.Y.FillBytes(make([]byte, 32)))
+
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+
+	return key, nil
+}
+
+func newSigningKeyRecord(algorithm, secret string) (*models.AdministratorSigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodES256.Alg():
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := caesar.Seal(secret, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil {
+		return nil, err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AdministratorSigningKey{
+		KeyID:      uuid.NewV4().String(),
+		Algorithm:  algorithm,
+		PrivateKey: sealed,
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		Status:     models.SigningKeyActive,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.SigningMethodRS256, nil
+	case jwt.SigningMethodES256.Alg():
+		return jwt.SigningMethodES256, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
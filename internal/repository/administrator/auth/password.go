@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/notifier"
+	"github.com/go-redis/redis/v8"
+)
+
+// GeneratePasswordResetToken issues a reset token for the user. Only its hash
+// is stored and a new token replaces the one issued before.
+func (a *adminAuthRepository) GeneratePasswordResetToken(userID string) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	resetToken := hex.EncodeToString(tokenBytes)
+
+	var previous string
+	err := a.redisClient.GetKeyValue(a.ctx, userPasswordResetKey(userID), &previous)
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	if previous != "" {
+		if err := a.redisClient.DeleteKey(a.ctx, passwordResetKey(previous)); err != nil {
+			return "", err
+		}
+	}
+
+	expiration := time.Duration(a.config.PasswordRules.ResetTokenInMinutes) * time.Minute
+	tokenHash := hashResetToken(resetToken)
+
+	if err := a.redisClient.SetKeyValue(a.ctx, passwordResetKey(tokenHash), userID, expiration); err != nil {
+		return "", err
+	}
+	if err := a.redisClient.SetKeyValue(a.ctx, userPasswordResetKey(userID), tokenHash, expiration); err != nil {
+		return "", err
+	}
+
+	return resetToken, nil
+}
+
+// ConsumePasswordResetToken returns the user a reset token was issued to and
+// invalidates it, so each token resets a password once.
+func (a *adminAuthRepository) ConsumePasswordResetToken(resetToken string) (string, error) {
+	tokenHash := hashResetToken(resetToken)
+
+	var userID string
+	err := a.redisClient.GetKeyValue(a.ctx, passwordResetKey(tokenHash), &userID)
+	if err == redis.Nil {
+		return "", ErrResetTokenInvalid
+	} else if err != nil {
+		return "", err
+	}
+
+	// only the request that actually deletes the token may use it
+	deleted, err := a.redisClient.Client.Del(a.ctx, passwordResetKey(tokenHash)).Result()
+	if err != nil {
+		return "", err
+	}
+	if deleted == 0 {
+		return "", ErrResetTokenInvalid
+	}
+
+	if err := a.redisClient.DeleteKey(a.ctx, userPasswordResetKey(userID)); err != nil {
+		a.logger.Warn("unable to delete the password reset marker")
+	}
+
+	return userID, nil
+}
+
+func (a *adminAuthRepository) SendPasswordResetEmail(email, resetToken string) error {
+	link := a.config.PasswordRules.ResetURL + "?token=" + url.QueryEscape(resetToken)
+
+	ctx, cancel := context.WithTimeout(a.ctx, time.Duration(a.config.DefaultFuncsTimeOutInSeconds)*time.Second)
+	defer cancel()
+
+	return a.notifier.Notify(ctx, &notifier.Message{
+		To:      email,
+		Subject: fmt.Sprintf("%s password reset", a.config.AppName),
+		Body: fmt.Sprintf("A password reset was requested for your %s account.\n\nReset your password within %d minutes at:\n%s\n\nIgnore this message if you did not request it.",
+			a.config.AppName, a.config.PasswordRules.ResetTokenInMinutes, link),
+	})
+}
+
+func hashResetToken(resetToken string) string {
+	sum := sha256.Sum256([]byte(resetToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func passwordResetKey(tokenHash string) string {
+	return "password_reset:" + tokenHash
+}
+
+func userPasswordResetKey(userID string) string {
+	return "password_reset_user:" + userID
+}
@@ -0,0 +1,124 @@
+package logs
+
+import (
+	"errors"
+	"time"
+
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultChainBatchSize = 1000
+
+// ChainDay sums up the entries a node chained within a day.
+type ChainDay struct {
+	FirstSeq int64
+	LastSeq  int64
+	Entries  int64
+	HeadHash string
+}
+
+func (z *adminLogsRepository) GetChainHead(nodeID string) (int64, string, error) {
+	var head models.AdministratorLogs
+
+	err := z.database.Table(z.repoConfig.LogsTable).
+		Select("chain_seq", "hash").
+		Where("node_id = ?", nodeID).
+		Order("chain_seq DESC").
+		Take(&head).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", nil
+	}
+
+	return head.ChainSeq, head.Hash, err
+}
+
+func (z *adminLogsRepository) GetChainNodes() ([]string, error) {
+	var nodes []string
+	err := z.database.Table(z.repoConfig.LogsTable).Where("node_id <> ''").Distinct().Pluck("node_id", &nodes).Error
+	return nodes, err
+}
+
+// WalkChain hands the entries of a node chain to handle in sequence order,
+// one batch per query. toSeq of zero walks to the end of the chain.
+func (z *adminLogsRepository) WalkChain(nodeID string, fromSeq, toSeq int64, batchSize int, handle func(entry *models.AdministratorLogs) bool) error {
+	if batchSize <= 0 {
+		batchSize = defaultChainBatchSize
+	}
+
+	lastSeq := fromSeq - 1
+	for {
+		var batch []*models.AdministratorLogs
+
+		query := z.database.Table(z.repoConfig.LogsTable).Where("node_id = ? AND chain_seq > ?", nodeID, lastSeq)
+		if toSeq > 0 {
+			query = query.Where("chain_seq <= ?", toSeq)
+		}
+
+		if err := query.Order("chain_seq ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+
+		for _, entry := range batch {
+			if !handle(entry) {
+				return nil
+			}
+			lastSeq = entry.ChainSeq
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (z *adminLogsRepository) CreateAnchor(anchor *models.AdministratorLogAnchor) error {
+	return z.database.Table(z.repoConfig.AnchorsTable).Create(anchor).Error
+}
+
+func (z *adminLogsRepository) GetAnchors(nodeID string, fromSeq, toSeq int64) ([]*models.AdministratorLogAnchor, error) {
+	var anchors []*models.AdministratorLogAnchor
+
+	query := z.database.Table(z.repoConfig.AnchorsTable).Where("node_id = ? AND chain_seq >= ?", nodeID, fromSeq)
+	if toSeq > 0 {
+		query = query.Where("chain_seq <= ?", toSeq)
+	}
+
+	err := query.Order("chain_seq ASC").Find(&anchors).Error
+	return anchors, err
+}
+
+func (z *adminLogsRepository) GetChainDay(nodeID string, start, end time.Time) (*ChainDay, error) {
+	var day ChainDay
+
+	err := z.database.Table(z.repoConfig.LogsTable).
+		Select("COALESCE(MIN(chain_seq), 0) AS first_seq, COALESCE(MAX(chain_seq), 0) AS last_seq, COUNT(*) AS entries").
+		Where("node_id = ? AND timestamps >= ? AND timestamps < ?", nodeID, start, end).
+		Scan(&day).Error
+	if err != nil || day.Entries == 0 {
+		return &day, err
+	}
+
+	var hashes []string
+	err = z.database.Table(z.repoConfig.LogsTable).
+		Where("node_id = ? AND chain_seq = ?", nodeID, day.LastSeq).
+		Pluck("hash", &hashes).Error
+	if len(hashes) > 0 {
+		day.HeadHash = hashes[0]
+	}
+
+	return &day, err
+}
+
+func (z *adminLogsRepository) HasDigest(nodeID, day string) (bool, error) {
+	var count int64
+	err := z.database.Table(z.repoConfig.DigestsTable).Where("node_id = ? AND day = ?", nodeID, day).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateDigest reports false when the digest of the day was already stored.
+func (z *adminLogsRepository) CreateDigest(digest *models.AdministratorLogDigest) (bool, error) {
+	result := z.database.Table(z.repoConfig.DigestsTable).Clauses(clause.OnConflict{DoNothing: true}).Create(digest)
+	return result.RowsAffected == 1, result.Error
+}
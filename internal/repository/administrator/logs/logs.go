@@ -2,6 +2,8 @@ package logs
 
 import (
 	"context"
+	"reflect"
+	"time"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
@@ -16,11 +18,35 @@ import (
 type RepoConfig struct {
 	ServicePrefix string
 	LogsTable     string
+	AnchorsTable  string
+	DigestsTable  string
 }
 
 type AdminLogsRepository interface {
 	Create(adminlog *models.AdministratorLogs) error
 	GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorLogsSearch) (*database.PaginatedResult, error)
+	Export(exportParams *types.ExportParams, searchParams *models.AdministratorLogsSearch, handle database.RowHandler) (int, error)
+
+	GetChainHead(nodeID string) (int64, string, error)
+	GetChainNodes() ([]string, error)
+	WalkChain(nodeID string, fromSeq, toSeq int64, batchSize int, handle func(entry *models.AdministratorLogs) bool) error
+	CreateAnchor(anchor *models.AdministratorLogAnchor) error
+	GetAnchors(nodeID string, fromSeq, toSeq int64) ([]*models.AdministratorLogAnchor, error)
+	GetChainDay(nodeID string, start, end time.Time) (*ChainDay, error)
+	HasDigest(nodeID, day string) (bool, error)
+	CreateDigest(digest *models.AdministratorLogDigest) (bool, error)
+
+	LogsTable() string
+	MonthlyPartitionName(month time.Time) string
+	IsPartitioned() (bool, error)
+	EnsurePartitioned() error
+	EnsurePartition(month time.Time) error
+	GetPartitions() ([]*LogPartition, error)
+	WalkPartition(name string, batchSize int, handle func(entry *models.AdministratorLogs) error) (int64, error)
+	DropPartition(name string, expectedRows int64) error
+	CreateDetachedPartition(name string) error
+	InsertIntoPartition(name string, entries []*models.AdministratorLogs) error
+	AttachPartition(name string, from, to time.Time) error
 }
 
 type adminLogsRepository struct {
@@ -37,9 +63,14 @@ func NewGORMAdminLogs(database *gorm.DB, servicePrefix string, config *config.Co
 	database.AutoMigrate(&models.AdministratorLogs{})
 	repoConfig := &RepoConfig{
 		ServicePrefix: servicePrefix,
-		LogsTable:     servicePrefix + "_logs"}
+		LogsTable:     servicePrefix + "_logs",
+		AnchorsTable:  servicePrefix + "_log_anchors",
+		DigestsTable:  servicePrefix + "_log_digests"}
 
-	err := config.PrefixService.RegisterServiceTables(servicePrefix, []string{repoConfig.LogsTable})
+	database.Table(repoConfig.AnchorsTable).AutoMigrate(&models.AdministratorLogAnchor{})
+	database.Table(repoConfig.DigestsTable).AutoMigrate(&models.AdministratorLogDigest{})
+
+	err := config.PrefixService.RegisterServiceTables(servicePrefix, []string{repoConfig.LogsTable, repoConfig.AnchorsTable, repoConfig.DigestsTable})
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +89,6 @@ func (z *adminLogsRepository) Create(adminlog *models.AdministratorLogs) error {
 
 func (z *adminLogsRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorLogsSearch) (*database.PaginatedResult, error) {
 	var adminLogs []*models.AdministratorLogs
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.LogsTable)
 
@@ -66,23 +96,44 @@ func (z *adminLogsRepository) GetAll(paginationParams *types.PaginationParams, s
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.LogsTable, Key: "id", Model: &models.AdministratorLogs{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.LogsTable).Where("deleted_at IS NULL").Scopes(whereScope)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting admin user logs:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&adminLogs).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(adminLogs, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(adminLogs, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
+
+func (z *adminLogsRepository) Export(exportParams *types.ExportParams, searchParams *models.AdministratorLogsSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.LogsTable, z.dslSearchEnabled)
+
+	selectFields, err := z.builders.SelectFields(database.KeysetFields(exportParams.Fields, "id"), z.repoConfig.LogsTable, reflect.TypeOf(models.AdministratorLogs{}))
+	if err != nil {
+		z.logger.Error("select fields failed", zap.Error(err))
+	}
+
+	newQuery := func() *gorm.DB {
+		return z.database.Table(z.repoConfig.LogsTable).Scopes(whereScope).Select(selectFields)
+	}
+
+	return database.StreamByKeyset(newQuery, database.KeysetOptions{
+		KeyColumn: z.repoConfig.LogsTable + ".id",
+		KeyField:  "id",
+		BatchSize: exportParams.BatchSize,
+		MaxRows:   exportParams.MaxRows,
+	}, handle)
+}
@@ -0,0 +1,318 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	partitionBoundLayout = "2006-01-02 15:04:05"
+
+	// RestoredPartitionSuffix marks partitions re-attached from an archive,
+	// retention leaves them alone until they are dropped by hand.
+	RestoredPartitionSuffix = "_restored"
+)
+
+var partitionBoundPattern = regexp.MustCompile(`FROM \('([^']+)'\) TO \('([^']+)'\)`)
+
+// LogPartition is a monthly partition of the logs table. The default
+// partition catches entries no monthly partition was made for yet.
+type LogPartition struct {
+	Name    string
+	From    time.Time
+	To      time.Time
+	Default bool
+}
+
+func (p *LogPartition) Restored() bool {
+	return strings.HasSuffix(p.Name, RestoredPartitionSuffix)
+}
+
+func (z *adminLogsRepository) LogsTable() string {
+	return z.repoConfig.LogsTable
+}
+
+func (z *adminLogsRepository) MonthlyPartitionName(month time.Time) string {
+	return fmt.Sprintf("%s_p%s", z.repoConfig.LogsTable, month.Format("200601"))
+}
+
+func (z *adminLogsRepository) defaultPartitionName() string {
+	return z.repoConfig.LogsTable + "_default"
+}
+
+func (z *adminLogsRepository) IsPartitioned() (bool, error) {
+	var relkind string
+	err := z.database.Raw("SELECT relkind FROM pg_class WHERE oid = to_regclass(?)", z.repoConfig.LogsTable).Scan(&relkind).Error
+	return relkind == "p", err
+}
+
+// EnsurePartitioned turns the logs table into one partitioned by month on
+// timestamps. The rows are copied into their monthly partitions in a single
+// transaction holding the table, which only runs once.
+func (z *adminLogsRepository) EnsurePartitioned() error {
+	partitioned, err := z.IsPartitioned()
+	if err != nil || partitioned {
+		return err
+	}
+
+	table := z.repoConfig.LogsTable
+	legacy := table + "_legacy"
+
+	err = z.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("LOCK TABLE %s IN ACCESS EXCLUSIVE MODE", table)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", table, legacy)).Error; err != nil {
+			return err
+		}
+
+		// index names are unique per schema, the new table takes them over
+		var indexes []string
+		if err := tx.Raw("SELECT indexname FROM pg_indexes WHERE tablename = ? AND schemaname = CURRENT_SCHEMA()", legacy).Scan(&indexes).Error; err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if err := tx.Exec(fmt.Sprintf("ALTER INDEX %s RENAME TO %s", index, index+"_legacy")).Error; err != nil {
+				return err
+			}
+		}
+
+		statements := []string{
+			fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY RANGE (timestamps)", table, legacy),
+			fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (id, timestamps)", table),
+			fmt.Sprintf("CREATE TABLE %s PARTITION OF %s DEFAULT", z.defaultPartitionName(), table),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		var span struct {
+			First *time.Time
+			Last  *time.Time
+		}
+		if err := tx.Raw(fmt.Sprintf("SELECT MIN(timestamps) AS first, MAX(timestamps) AS last FROM %s", legacy)).Scan(&span).Error; err != nil {
+			return err
+		}
+
+		if span.First != nil && span.Last != nil {
+			for month := monthStart(*span.First); !month.After(*span.Last); month = month.AddDate(0, 1, 0) {
+				if err := z.createPartition(tx, month); err != nil {
+					return err
+				}
+			}
+		}
+
+		var sequence *string
+		if err := tx.Raw("SELECT pg_get_serial_sequence(?, 'id')", legacy).Scan(&sequence).Error; err != nil {
+			return err
+		}
+
+		statements = []string{fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", table, legacy)}
+		if sequence != nil {
+			statements = append(statements, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.id", *sequence, table))
+		}
+		statements = append(statements, fmt.Sprintf("DROP TABLE %s", legacy))
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	z.logger.Info("admin logs table is partitioned by month", zap.String("table", table))
+
+	// the indexes of the model are created on the partitioned table again
+	return z.database.AutoMigrate(&models.AdministratorLogs{})
+}
+
+// EnsurePartition creates the partition of the month unless it exists. Rows
+// the default partition caught for the month are moved into it.
+func (z *adminLogsRepository) EnsurePartition(month time.Time) error {
+	month = monthStart(month)
+
+	var exists bool
+	if err := z.database.Raw("SELECT to_regclass(?) IS NOT NULL", z.MonthlyPartitionName(month)).Scan(&exists).Error; err != nil || exists {
+		return err
+	}
+
+	var stray int64
+	err := z.database.Table(z.defaultPartitionName()).
+		Where("timestamps >= ? AND timestamps < ?", month, month.AddDate(0, 1, 0)).
+		Count(&stray).Error
+	if err != nil {
+		return err
+	}
+
+	if stray == 0 {
+		return z.createPartition(z.database, month)
+	}
+
+	return z.database.Transaction(func(tx *gorm.DB) error {
+		table, defaultPartition := z.repoConfig.LogsTable, z.defaultPartitionName()
+		bounds := fmt.Sprintf("timestamps >= '%s' AND timestamps < '%s'", month.Format(partitionBoundLayout), month.AddDate(0, 1, 0).Format(partitionBoundLayout))
+
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", table, defaultPartition)).Error; err != nil {
+			return err
+		}
+		if err := z.createPartition(tx, month); err != nil {
+			return err
+		}
+
+		statements := []string{
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s WHERE %s", table, defaultPartition, bounds),
+			fmt.Sprintf("DELETE FROM %s WHERE %s", defaultPartition, bounds),
+			fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s DEFAULT", table, defaultPartition),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (z *adminLogsRepository) createPartition(db *gorm.DB, month time.Time) error {
+	return db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+		z.MonthlyPartitionName(month), z.repoConfig.LogsTable,
+		month.Format(partitionBoundLayout), month.AddDate(0, 1, 0).Format(partitionBoundLayout))).Error
+}
+
+func (z *adminLogsRepository) GetPartitions() ([]*LogPartition, error) {
+	var rows []struct {
+		Name  string
+		Bound string
+	}
+
+	err := z.database.Raw(`SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+		FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass(?) ORDER BY c.relname`, z.repoConfig.LogsTable).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make([]*LogPartition, 0, len(rows))
+	for _, row := range rows {
+		partition := &LogPartition{Name: row.Name}
+
+		if row.Bound == "DEFAULT" {
+			partition.Default = true
+		} else {
+			bounds := partitionBoundPattern.FindStringSubmatch(row.Bound)
+			if bounds == nil {
+				z.logger.Warn("unexpected admin logs partition bound", zap.String("partition", row.Name), zap.String("bound", row.Bound))
+				continue
+			}
+			if partition.From, err = time.Parse(partitionBoundLayout, bounds[1]); err != nil {
+				return nil, err
+			}
+			if partition.To, err = time.Parse(partitionBoundLayout, bounds[2]); err != nil {
+				return nil, err
+			}
+		}
+
+		partitions = append(partitions, partition)
+	}
+
+	return partitions, nil
+}
+
+// WalkPartition hands the entries of a partition to handle in id order, one
+// batch per query.
+func (z *adminLogsRepository) WalkPartition(name string, batchSize int, handle func(entry *models.AdministratorLogs) error) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultChainBatchSize
+	}
+
+	var walked int64
+	var lastID int64 = -1
+	for {
+		var batch []*models.AdministratorLogs
+		if err := z.database.Table(name).Where("id > ?", lastID).Order("id ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return walked, err
+		}
+
+		for _, entry := range batch {
+			if err := handle(entry); err != nil {
+				return walked, err
+			}
+			lastID = entry.ID
+			walked++
+		}
+
+		if len(batch) < batchSize {
+			return walked, nil
+		}
+	}
+}
+
+// DropPartition detaches the partition and drops it, provided it still holds
+// the expected number of rows.
+func (z *adminLogsRepository) DropPartition(name string, expectedRows int64) error {
+	return z.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", z.repoConfig.LogsTable, name)).Error; err != nil {
+			return err
+		}
+
+		var rows int64
+		if err := tx.Table(name).Count(&rows).Error; err != nil {
+			return err
+		}
+		if rows != expectedRows {
+			return fmt.Errorf("partition %s holds %d rows, %d were archived", name, rows, expectedRows)
+		}
+
+		return tx.Exec(fmt.Sprintf("DROP TABLE %s", name)).Error
+	})
+}
+
+// CreateDetachedPartition creates an empty table shaped like the logs table
+// for the entries of an archive.
+func (z *adminLogsRepository) CreateDetachedPartition(name string) error {
+	var exists bool
+	if err := z.database.Raw("SELECT to_regclass(?) IS NOT NULL", name).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("table %s already exists", name)
+	}
+
+	return z.database.Exec(fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS)", name, z.repoConfig.LogsTable)).Error
+}
+
+func (z *adminLogsRepository) InsertIntoPartition(name string, entries []*models.AdministratorLogs) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return z.database.Table(name).Create(entries).Error
+}
+
+func (z *adminLogsRepository) AttachPartition(name string, from, to time.Time) error {
+	if from.IsZero() || to.IsZero() {
+		return errors.New("partition bounds are required")
+	}
+
+	return z.database.Exec(fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')",
+		z.repoConfig.LogsTable, name, from.Format(partitionBoundLayout), to.Format(partitionBoundLayout))).Error
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	"github.com/denizumutdereli/stream-admin/internal/database"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
 	rolePolicyModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/policy"
 	"github.com/denizumutdereli/stream-admin/internal/repository/scopes"
 	"github.com/denizumutdereli/stream-admin/internal/types"
@@ -20,7 +21,9 @@ type AdminRolePolicyRepository interface {
 	CreateAdminRolePolicy(adminRolePolicy *rolePolicyModels.AdministratorRolePolicy) (bool, error)
 	UpdateAdminRolePolicy(adminRolePolicy *rolePolicyModels.AdministratorRolePolicy) (*rolePolicyModels.AdministratorRolePolicyResponse, error)
 	DeleteAdminRolePolicy(policyID string) (bool, error)
+	GetAdminRolePolicyByID(policyID string) (*rolePolicyModels.AdministratorRolePolicyResponse, error)
 	GetAdminRolePolicies(paginationParams *types.PaginationParams, searchParams *rolePolicyModels.AdministratorRolePolicySearch) (*database.PaginatedResult, error)
+	GetRoleRules(roleID string) (*types.RoleRules, error)
 }
 
 type repoConfig struct {
@@ -113,22 +116,38 @@ func (u *adminRolePolicyRepository) UpdateAdminRolePolicy(adminRolePolicy *roleP
 		return nil, result.Error
 	}
 
+	return u.policyResponse(&existingPolicy)
+}
+
+func (u *adminRolePolicyRepository) GetAdminRolePolicyByID(policyID string) (*rolePolicyModels.AdministratorRolePolicyResponse, error) {
+	var existingPolicy rolePolicyModels.AdministratorRolePolicy
+	result := u.database.First(&existingPolicy, "policy_id = ?", policyID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("policy not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return u.policyResponse(&existingPolicy)
+}
+
+func (u *adminRolePolicyRepository) policyResponse(policy *rolePolicyModels.AdministratorRolePolicy) (*rolePolicyModels.AdministratorRolePolicyResponse, error) {
 	var subPolicies []types.SubRolePolicies
-	err := json.Unmarshal([]byte(existingPolicy.SubPolicies), &subPolicies)
+	err := json.Unmarshal([]byte(policy.SubPolicies), &subPolicies)
 	if err != nil {
 		u.logger.Error("error unmarshalling sub policies:", zap.Error(err))
 		return nil, err
 	}
 
 	response := &rolePolicyModels.AdministratorRolePolicyResponse{
-		PolicyID:       existingPolicy.PolicyID,
-		Readonly:       existingPolicy.Readonly,
-		Target:         existingPolicy.Target,
-		Title:          existingPolicy.Title,
+		PolicyID:       policy.PolicyID,
+		Readonly:       policy.Readonly,
+		Target:         policy.Target,
+		Title:          policy.Title,
 		SubPolicyRules: subPolicies,
-		Status:         existingPolicy.Status,
-		CreatedAt:      existingPolicy.CreatedAt,
-		UpdatedAt:      existingPolicy.UpdatedAt,
+		Status:         policy.Status,
+		CreatedAt:      policy.CreatedAt,
+		UpdatedAt:      policy.UpdatedAt,
 	}
 
 	return response, nil
@@ -157,7 +176,6 @@ func (u *adminRolePolicyRepository) DeleteAdminRolePolicy(policyID string) (bool
 
 func (u *adminRolePolicyRepository) GetAdminRolePolicies(paginationParams *types.PaginationParams, searchParams *rolePolicyModels.AdministratorRolePolicySearch) (*database.PaginatedResult, error) {
 	var data []*rolePolicyModels.AdministratorRolePolicy
-	var count int64
 
 	db := u.database.Debug().Table(u.repoConfig.AdminRolePolicyTable)
 
@@ -165,18 +183,16 @@ func (u *adminRolePolicyRepository) GetAdminRolePolicies(paginationParams *types
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: u.repoConfig.AdminRolePolicyTable, Key: "policy_id", Model: &rolePolicyModels.AdministratorRolePolicy{}}),
 	)
 
 	countQuery := u.database.Table(u.repoConfig.AdminRolePolicyTable).Where("deleted_at IS NULL").Scopes(whereScope)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		u.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
@@ -204,7 +220,40 @@ func (u *adminRolePolicyRepository) GetAdminRolePolicies(paginationParams *types
 		responseData = append(responseData, responseItem)
 	}
 
-	paginatedResults := database.PaginateTheResults(responseData, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(responseData, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "policy_id")
+	if err != nil {
+		return nil, err
+	}
 	return paginatedResults, nil
 
 }
+
+// GetRoleRules collects the sub-policies of every active policy attached to
+// the role. Paused policies do not take part in enforcement.
+func (u *adminRolePolicyRepository) GetRoleRules(roleID string) (*types.RoleRules, error) {
+	var role models.AdministratorRole
+	result := u.database.First(&role, "role_id = ?", roleID)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("role not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var policies []rolePolicyModels.AdministratorRolePolicy
+	if err := u.database.Model(&role).Where("status = ?", rolePolicyModels.RoleStatusActive).Association("Policies").Find(&policies); err != nil {
+		return nil, err
+	}
+
+	rules := &types.RoleRules{RoleID: role.RoleID, RoleName: role.RoleName}
+
+	for _, policy := range policies {
+		var subPolicies []types.SubRolePolicies
+		if err := json.Unmarshal([]byte(policy.SubPolicies), &subPolicies); err != nil {
+			u.logger.Error("error unmarshalling sub policies:", zap.String("policy_id", policy.PolicyID), zap.Error(err))
+			continue
+		}
+		rules.Rules = append(rules.Rules, subPolicies...)
+	}
+
+	return rules, nil
+}
@@ -2,40 +2,55 @@ package query
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/config"
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	"github.com/denizumutdereli/stream-admin/internal/repository/scopes"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type QueryRepository interface {
-	GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorDashboardQuery) (*database.PaginatedResult, error)
-	Create(template *models.AdministratorDashboardQuery) error
+	GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorDashboardQuerySearch, userID, roleID string) (*database.PaginatedResult, error)
+	Create(query *models.AdministratorDashboardQuery) error
 	GetByID(id uint) (*models.AdministratorDashboardQuery, error)
-	Update(template *models.AdministratorDashboardQuery) error
+	Update(query *models.AdministratorDashboardQuery) error
 	Delete(id uint) error
+	MarkRun(id uint, userID string, runAt time.Time) error
 }
 
 type repoConfig struct {
-	QueriesTable string
+	ServicePrefix string
+	QueriesTable  string
 }
 
 type queryRepository struct {
-	ctx        context.Context
-	cancel     context.CancelFunc
-	database   *gorm.DB
-	repoConfig *repoConfig
-	logger     *zap.Logger
+	ctx              context.Context
+	cancel           context.CancelFunc
+	database         *gorm.DB
+	repoConfig       *repoConfig
+	logger           *zap.Logger
+	builders         builders.BuilderService
+	dslSearchEnabled bool
 }
 
-func NewGORMQueryRepository(database *gorm.DB, servicePrefix string, logger *zap.Logger) (QueryRepository, error) {
+func NewGORMQueryRepository(database *gorm.DB, servicePrefix string, config *config.Config, builders builders.BuilderService) (QueryRepository, error) {
 	database.AutoMigrate(&models.AdministratorDashboardQuery{})
 	repoConfig := &repoConfig{
-		QueriesTable: servicePrefix + "_dashboard_queries"}
+		ServicePrefix: servicePrefix,
+		QueriesTable:  servicePrefix + "_dashboard_queries"}
 
-	repository := &queryRepository{database: database, repoConfig: repoConfig, logger: logger}
+	err := config.PrefixService.RegisterServiceTables(servicePrefix, []string{repoConfig.QueriesTable})
+	if err != nil {
+		return nil, err
+	}
+
+	repository := &queryRepository{database: database, repoConfig: repoConfig, logger: config.Logger, builders: builders, dslSearchEnabled: true}
 	ctx, cancel := context.WithCancel(context.Background())
 	repository.ctx = ctx
 	repository.cancel = cancel
@@ -43,35 +58,43 @@ func NewGORMQueryRepository(database *gorm.DB, servicePrefix string, logger *zap
 	return repository, nil
 }
 
-func (r *queryRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorDashboardQuery) (*database.PaginatedResult, error) {
+// GetAll lists the searches visible to the admin, see IsVisibleTo.
+func (r *queryRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorDashboardQuerySearch, userID, roleID string) (*database.PaginatedResult, error) {
 	var data []*models.AdministratorDashboardQuery
-	var count int64
 
 	db := r.database.Debug().Table(r.repoConfig.QueriesTable)
 
-	query := db.Scopes()
+	whereScope := scopes.ApplySearchFilters(searchParams, r.repoConfig.QueriesTable, r.dslSearchEnabled)
+	visibleScope := visibleTo(userID, roleID)
+
+	query := db.Scopes(
+		whereScope,
+		visibleScope,
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: r.repoConfig.QueriesTable, Key: "id", Model: &models.AdministratorDashboardQuery{}}),
+	)
 
-	countQuery := r.database.Table(r.repoConfig.QueriesTable).Where("deleted_at IS NULL").Scopes()
+	countQuery := r.database.Table(r.repoConfig.QueriesTable).Scopes(whereScope, visibleScope)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		r.logger.Error("error counting data:", zap.Error(err))
 		return nil, err
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
 
-func (r *queryRepository) Create(template *models.AdministratorDashboardQuery) error {
-	return r.database.Table(r.repoConfig.QueriesTable).Create(template).Error
+func (r *queryRepository) Create(query *models.AdministratorDashboardQuery) error {
+	return r.database.Table(r.repoConfig.QueriesTable).Create(query).Error
 }
 
 func (r *queryRepository) GetByID(id uint) (*models.AdministratorDashboardQuery, error) {
@@ -89,3 +112,20 @@ func (r *queryRepository) Update(query *models.AdministratorDashboardQuery) erro
 func (r *queryRepository) Delete(id uint) error {
 	return r.database.Table(r.repoConfig.QueriesTable).Where("id = ?", id).Delete(&models.AdministratorDashboardQuery{}).Error
 }
+
+// MarkRun records the last runner without touching updated_at, so running a
+// search does not look like an edit.
+func (r *queryRepository) MarkRun(id uint, userID string, runAt time.Time) error {
+	return r.database.Table(r.repoConfig.QueriesTable).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"last_run_by": userID,
+		"last_run_at": runAt,
+	}).Error
+}
+
+func visibleTo(userID, roleID string) func(db *gorm.DB) *gorm.DB {
+	sharedRole, _ := json.Marshal([]string{roleID})
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_predefined OR created_by = ? OR shared_roles @> ?::jsonb", userID, string(sharedRole))
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	"github.com/denizumutdereli/stream-admin/internal/repository/scopes"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -45,27 +46,27 @@ func NewGORMDashboardTemplateRepository(database *gorm.DB, servicePrefix string,
 
 func (r *dashboardTemplateRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.AdministratorDashboardTemplate) (*database.PaginatedResult, error) {
 	var data []*models.AdministratorDashboardTemplate
-	var count int64
 
 	db := r.database.Debug().Table(r.repoConfig.TemplateTable)
 
-	query := db.Scopes()
+	query := db.Scopes(scopes.Paginate(paginationParams, scopes.SortTarget{Table: r.repoConfig.TemplateTable, Key: "id", Model: &models.AdministratorDashboardTemplate{}}))
 
 	countQuery := r.database.Table(r.repoConfig.TemplateTable).Where("deleted_at IS NULL").Scopes()
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		r.logger.Error("error counting dashboard templates:", zap.Error(err))
 		return nil, err
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
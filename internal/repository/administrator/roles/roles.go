@@ -26,6 +26,7 @@ type AdminUserRolesRepository interface {
 	GetAdminRoles(paginationParams *types.PaginationParams, searchParams *models.AdministratorRoleSearch) (*database.PaginatedResult, error)
 	AttachPoliciesToRole(roleID string, policyIDs []string) (*models.RoleWithPolicyTitles, error)
 	GetAdminRoleByID(roleID string) (models.AdministratorRole, error)
+	GetRoleWithPolicyTitles(roleID string) (*models.RoleWithPolicyTitles, error)
 }
 
 type AdministratorRolesOutboxMessage struct {
@@ -125,7 +126,6 @@ func (u *adminUserRolesRepository) CreateAdminRole(adminRole *models.Administrat
 
 func (u *adminUserRolesRepository) GetAdminRoles(paginationParams *types.PaginationParams, searchParams *models.AdministratorRoleSearch) (*database.PaginatedResult, error) {
 	var data []*models.AdministratorRole
-	var count int64
 
 	db := u.database.Debug().Table(u.repoConfig.AdminUserRolesTable)
 
@@ -133,25 +133,26 @@ func (u *adminUserRolesRepository) GetAdminRoles(paginationParams *types.Paginat
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: u.repoConfig.AdminUserRolesTable, Key: "role_id", Model: &models.AdministratorRole{}}),
 	)
 
 	countQuery := u.database.Table(u.repoConfig.AdminUserRolesTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		u.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Preload("Policies").Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "role_id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 
@@ -208,3 +209,19 @@ func (u *adminUserRolesRepository) GetAdminRoleByID(roleID string) (models.Admin
 
 	return admin_role, result.Error
 }
+
+func (u *adminUserRolesRepository) GetRoleWithPolicyTitles(roleID string) (*models.RoleWithPolicyTitles, error) {
+	var role models.AdministratorRole
+	if err := u.database.Preload("Policies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("policy_id")
+	}).First(&role, "role_id = ?", roleID).Error; err != nil {
+		return nil, errors.New("role not found")
+	}
+
+	policyTitles := make([]models.PolicyTitle, 0, len(role.Policies))
+	for _, policy := range role.Policies {
+		policyTitles = append(policyTitles, models.PolicyTitle{PolicyID: policy.PolicyID, Title: policy.Title})
+	}
+
+	return &models.RoleWithPolicyTitles{RoleID: role.RoleID, RoleName: role.RoleName, Policies: policyTitles}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/caesar"
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
@@ -33,6 +34,15 @@ type AdminUsersRepository interface {
 	GetAdminUserByVerificationCode(verificationCode string) (models.AdministratorUser, error)
 	FindAdminUserByID(userid string) (models.AdministratorUser, error)
 	GetAdminActiveUsersVPNAddresses() ([]string, error)
+	GetVerifiedAdminUsers() ([]models.AdministratorUser, error)
+
+	EnableTOTP(userID, secret string, recoveryCodeHashes []string) error
+	OpenTOTPSecret(user *models.AdministratorUser) (string, error)
+	DisableTOTP(userID string) error
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+
+	GetPasswordHistory(userID string, limit int) ([]string, error)
+	ChangePassword(userID, passwordHash string, historySize int) error
 }
 
 type AdministratorUsersOutboxMessage struct {
@@ -65,7 +75,7 @@ type adminUserRepository struct {
 }
 
 func NewGORMAdminUsersRepository(database *gorm.DB, servicePrefix string, config *config.Config, builders builders.BuilderService) (AdminUsersRepository, error) {
-	database.AutoMigrate(&models.AdministratorUser{}, &models.AdministratorRole{}, &AdministratorUsersOutboxMessage{})
+	database.AutoMigrate(&models.AdministratorUser{}, &models.AdministratorRole{}, &models.AdministratorRecoveryCode{}, &models.AdministratorPasswordHistory{}, &AdministratorUsersOutboxMessage{})
 	repoConfig := &repoConfig{
 		ServicePrefix:   servicePrefix,
 		AdminUsersTable: servicePrefix + "_users",
@@ -110,6 +120,10 @@ func NewGORMAdminUsersRepository(database *gorm.DB, servicePrefix string, config
 		repository.outboxManager.ProcessMessages()
 	}()
 
+	if err := repository.sealPlainTOTPSecrets(); err != nil {
+		return nil, err
+	}
+
 	return repository, nil
 }
 
@@ -219,7 +233,7 @@ func (u *adminUserRepository) UpdateAdminUser(adminUser *models.AdministratorUse
 	// 	return nil, errors.New("super admin users can not be modified or added")
 	// }
 
-	result = u.database.Model(&existingUser).Preload("Role").Updates(adminUser)
+	result = u.database.Model(&existingUser).Preload("Role").Omit("PasswordChangedAt").Updates(adminUser)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -265,7 +279,6 @@ func (u *adminUserRepository) DeleteAdminUser(userID string) (bool, error) {
 
 func (u *adminUserRepository) GetAdminUsers(paginationParams *types.PaginationParams, searchParams *models.AdministratorUserSearch) (*database.PaginatedResult, error) {
 	var data []*models.AdministratorUser
-	var count int64
 
 	db := u.database.Debug().Table(u.repoConfig.AdminUsersTable).
 		Preload("Role")
@@ -274,25 +287,26 @@ func (u *adminUserRepository) GetAdminUsers(paginationParams *types.PaginationPa
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: u.repoConfig.AdminUsersTable, Key: "user_id", Model: &models.AdministratorUser{}}),
 	)
 
 	countQuery := u.database.Table(u.repoConfig.AdminUsersTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		u.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "user_id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
@@ -315,6 +329,169 @@ func (u *adminUserRepository) FindAdminUserByID(userid string) (models.Administr
 	return admin_user, result.Error
 }
 
+func (u *adminUserRepository) GetVerifiedAdminUsers() ([]models.AdministratorUser, error) {
+	var adminUsers []models.AdministratorUser
+	result := u.database.Where("status = ?", models.UserStatusVerified).Find(&adminUsers)
+	return adminUsers, result.Error
+}
+
+// EnableTOTP switches the user to authenticator codes and replaces any
+// recovery codes left from an earlier enrollment. The secret is sealed at
+// rest, OpenTOTPSecret gives it back.
+func (u *adminUserRepository) EnableTOTP(userID, secret string, recoveryCodeHashes []string) error {
+	sealed, err := caesar.Seal(u.config.SecretJWTToken, []byte(secret))
+	if err != nil {
+		return err
+	}
+
+	return u.database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AdministratorUser{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_method": models.TwoFactorTOTP,
+			"totp_secret":       sealed,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("admin user not found")
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.AdministratorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.AdministratorRecoveryCode, 0, len(recoveryCodeHashes))
+		for _, hash := range recoveryCodeHashes {
+			codes = append(codes, models.AdministratorRecoveryCode{CodeID: uuid.NewV4().String(), UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (u *adminUserRepository) OpenTOTPSecret(user *models.AdministratorUser) (string, error) {
+	secret, err := caesar.Open(u.config.SecretJWTToken, user.TOTPSecret)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// sealPlainTOTPSecrets seals the secrets stored before they were sealed at
+// rest. A secret that opens is already sealed.
+func (u *adminUserRepository) sealPlainTOTPSecrets() error {
+	var adminUsers []models.AdministratorUser
+	if err := u.database.Select("user_id", "totp_secret").Where("totp_secret <> ''").Find(&adminUsers).Error; err != nil {
+		return err
+	}
+
+	for _, adminUser := range adminUsers {
+		if _, err := caesar.Open(u.config.SecretJWTToken, adminUser.TOTPSecret); err == nil {
+			continue
+		}
+
+		sealed, err := caesar.Seal(u.config.SecretJWTToken, []byte(adminUser.TOTPSecret))
+		if err != nil {
+			return err
+		}
+
+		if err := u.database.Model(&models.AdministratorUser{}).Where("user_id = ? AND totp_secret = ?", adminUser.UserID, adminUser.TOTPSecret).
+			Update("totp_secret", sealed).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *adminUserRepository) DisableTOTP(userID string) error {
+	return u.database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AdministratorUser{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_method": models.TwoFactorSMS,
+			"totp_secret":       "",
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&models.AdministratorRecoveryCode{}).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It reports false when
+// no such code is left, so a code can only be used once.
+func (u *adminUserRepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	result := u.database.Model(&models.AdministratorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetPasswordHistory returns the hashes of the latest passwords the user had
+// before the current one, newest first.
+func (u *adminUserRepository) GetPasswordHistory(userID string, limit int) ([]string, error) {
+	var hashes []string
+	if limit <= 0 {
+		return hashes, nil
+	}
+
+	result := u.database.Model(&models.AdministratorPasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("password_hash", &hashes)
+
+	return hashes, result.Error
+}
+
+// ChangePassword sets the new hash and moves the current one to the history,
+// keeping only as many entries as the reuse check looks at.
+func (u *adminUserRepository) ChangePassword(userID, passwordHash string, historySize int) error {
+	return u.database.Transaction(func(tx *gorm.DB) error {
+		var user models.AdministratorUser
+		if err := tx.Select("user_id", "password").First(&user, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.AdministratorUser{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"password":             passwordHash,
+			"must_change_password": false,
+			"password_changed_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if historySize <= 1 {
+			return tx.Where("user_id = ?", userID).Delete(&models.AdministratorPasswordHistory{}).Error
+		}
+
+		if err := tx.Create(&models.AdministratorPasswordHistory{
+			HistoryID:    uuid.NewV4().String(),
+			UserID:       userID,
+			PasswordHash: user.Password,
+			CreatedAt:    now,
+		}).Error; err != nil {
+			return err
+		}
+
+		// the current password counts towards the history size
+		var keep []string
+		if err := tx.Model(&models.AdministratorPasswordHistory{}).
+			Where("user_id = ?", userID).
+			Order("created_at DESC").
+			Limit(historySize-1).
+			Pluck("history_id", &keep).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ? AND history_id NOT IN ?", userID, keep).Delete(&models.AdministratorPasswordHistory{}).Error
+	})
+}
+
 func (u *adminUserRepository) GetAdminActiveUsersVPNAddresses() ([]string, error) {
 	u.vpnAddrsCache.RLock()
 	cached, exists := u.vpnAddrsCache.data["vpnAddresses"]
@@ -19,6 +19,9 @@ type AssetsRepository interface {
 	GetCoins(paginationParams *types.PaginationParams, searchParams *models.AssetsCoinsSearch) (*database.PaginatedResult, error)
 	GetAssets(paginationParams *types.PaginationParams, searchParams *models.AssetsSearch) (*database.PaginatedResult, error)
 	GetNetworks(paginationParams *types.PaginationParams, searchParams *models.AssetsNetworksSearch) (*database.PaginatedResult, error)
+	ExportCoins(exportParams *types.ExportParams, searchParams *models.AssetsCoinsSearch, handle database.RowHandler) (int, error)
+	ExportAssets(exportParams *types.ExportParams, searchParams *models.AssetsSearch, handle database.RowHandler) (int, error)
+	ExportNetworks(exportParams *types.ExportParams, searchParams *models.AssetsNetworksSearch, handle database.RowHandler) (int, error)
 }
 
 type RepoConfig struct {
@@ -86,7 +89,6 @@ func (z *assetsRepository) GetSearchParameters() ([]types.SearchParameters, erro
 
 func (z *assetsRepository) GetCoins(paginationParams *types.PaginationParams, searchParams *models.AssetsCoinsSearch) (*database.PaginatedResult, error) {
 	var data []*models.AssetsCoins
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.CoinsTable)
 
@@ -99,32 +101,32 @@ func (z *assetsRepository) GetCoins(paginationParams *types.PaginationParams, se
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.CoinsTable, Key: "id", Model: &models.AssetsCoins{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.CoinsTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
 
 func (z *assetsRepository) GetAssets(paginationParams *types.PaginationParams, searchParams *models.AssetsSearch) (*database.PaginatedResult, error) {
 	var data []*models.Assets
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.AssetsTable)
 
@@ -137,32 +139,32 @@ func (z *assetsRepository) GetAssets(paginationParams *types.PaginationParams, s
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.AssetsTable, Key: "id", Model: &models.Assets{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.AssetsTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
 
 func (z *assetsRepository) GetNetworks(paginationParams *types.PaginationParams, searchParams *models.AssetsNetworksSearch) (*database.PaginatedResult, error) {
 	var data []*models.AssetsNetworks
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.NetworkTable)
 
@@ -175,25 +177,59 @@ func (z *assetsRepository) GetNetworks(paginationParams *types.PaginationParams,
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.NetworkTable, Key: "id", Model: &models.AssetsNetworks{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.NetworkTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
+
+func (z *assetsRepository) ExportCoins(exportParams *types.ExportParams, searchParams *models.AssetsCoinsSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.CoinsTable, z.dslSearchEnabled)
+	return z.streamTable(z.repoConfig.CoinsTable, whereScope, exportParams, reflect.TypeOf(models.AssetsCoins{}), handle)
+}
+
+func (z *assetsRepository) ExportAssets(exportParams *types.ExportParams, searchParams *models.AssetsSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.AssetsTable, z.dslSearchEnabled)
+	return z.streamTable(z.repoConfig.AssetsTable, whereScope, exportParams, reflect.TypeOf(models.Assets{}), handle)
+}
+
+func (z *assetsRepository) ExportNetworks(exportParams *types.ExportParams, searchParams *models.AssetsNetworksSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.NetworkTable, z.dslSearchEnabled)
+	return z.streamTable(z.repoConfig.NetworkTable, whereScope, exportParams, reflect.TypeOf(models.AssetsNetworks{}), handle)
+}
+
+func (z *assetsRepository) streamTable(table string, whereScope func(db *gorm.DB) *gorm.DB, exportParams *types.ExportParams, targetStruct interface{}, handle database.RowHandler) (int, error) {
+	selectFields, err := z.builders.SelectFields(database.KeysetFields(exportParams.Fields, "id"), table, targetStruct)
+	if err != nil {
+		z.logger.Error("select fields failed", zap.Error(err))
+	}
+
+	newQuery := func() *gorm.DB {
+		return z.database.Table(table).Scopes(whereScope).Select(selectFields)
+	}
+
+	return database.StreamByKeyset(newQuery, database.KeysetOptions{
+		KeyColumn: table + ".id",
+		KeyField:  "id",
+		BatchSize: exportParams.BatchSize,
+		MaxRows:   exportParams.MaxRows,
+	}, handle)
+}
@@ -3,12 +3,17 @@ package interpreters
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/denizumutdereli/stream-admin/internal/dsl"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"gorm.io/gorm"
 )
 
+var columnPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 func ProcessFields(db *gorm.DB, params interface{}, omitFields []string, tableName string) *gorm.DB {
 	val := reflect.ValueOf(params).Elem()
 
@@ -48,6 +53,12 @@ func ApplyFilters(db *gorm.DB, field string, value interface{}, operator string,
 	if val.Kind() == reflect.Ptr && val.IsNil() {
 		return db
 	}
+
+	if !columnPattern.MatchString(field) {
+		db.AddError(fmt.Errorf("invalid search field %q", field))
+		return db
+	}
+
 	return db.Debug().Where(fmt.Sprintf("%s.%s %s ?", tableName, field, operator), value)
 }
 
@@ -59,19 +70,24 @@ func DSLSearchOperator(db *gorm.DB, condition *types.QueryCondition, tableName s
 		condition.Value = strings.TrimSuffix(condition.Value, "\\i")
 	}
 
+	var value interface{} = condition.Value
+	if condition.TypedValue != nil {
+		value = condition.TypedValue
+	}
+
 	switch condition.Operator {
 	case "=", "eq":
-		db = ApplyFilters(db, condition.Field, condition.Value, "=", tableName)
+		db = ApplyFilters(db, condition.Field, value, "=", tableName)
 	case ">", "gt":
-		db = ApplyFilters(db, condition.Field, condition.Value, ">", tableName)
+		db = ApplyFilters(db, condition.Field, value, ">", tableName)
 	case ">=", "gte":
-		db = ApplyFilters(db, condition.Field, condition.Value, ">=", tableName)
+		db = ApplyFilters(db, condition.Field, value, ">=", tableName)
 	case "<", "lt":
-		db = ApplyFilters(db, condition.Field, condition.Value, "<", tableName)
+		db = ApplyFilters(db, condition.Field, value, "<", tableName)
 	case "<=", "lte":
-		db = ApplyFilters(db, condition.Field, condition.Value, "<=", tableName)
+		db = ApplyFilters(db, condition.Field, value, "<=", tableName)
 	case "!=", "neq":
-		db = ApplyFilters(db, condition.Field, condition.Value, "!=", tableName)
+		db = ApplyFilters(db, condition.Field, value, "!=", tableName)
 	case "contains", "cont", "inc":
 		if caseInsensitive {
 			db = ApplyFilters(db, condition.Field, "%"+strings.ToLower(condition.Value)+"%", "ILIKE", tableName)
@@ -86,20 +102,113 @@ func DSLSearchOperator(db *gorm.DB, condition *types.QueryCondition, tableName s
 		}
 	case "start":
 		if caseInsensitive {
-			db = ApplyFilters(db, condition.Field, "%"+condition.Value, "LIKE", tableName)
+			db = ApplyFilters(db, condition.Field, condition.Value+"%", "ILIKE", tableName)
 		} else {
-			db = ApplyFilters(db, condition.Field, "%"+condition.Value, "ILIKE", tableName)
+			db = ApplyFilters(db, condition.Field, condition.Value+"%", "LIKE", tableName)
 		}
-		db = ApplyFilters(db, condition.Field, "%"+condition.Value, "LIKE", tableName)
 	case "end":
-
 		if caseInsensitive {
-			db = ApplyFilters(db, condition.Field, condition.Value+"%", "ILIKE", tableName)
+			db = ApplyFilters(db, condition.Field, "%"+condition.Value, "ILIKE", tableName)
 		} else {
-			db = ApplyFilters(db, condition.Field, condition.Value+"%", "LIKE", tableName)
+			db = ApplyFilters(db, condition.Field, "%"+condition.Value, "LIKE", tableName)
 		}
-
+	case "regex", "~":
+		if caseInsensitive {
+			db = ApplyFilters(db, condition.Field, condition.Value, "~*", tableName)
+		} else {
+			db = ApplyFilters(db, condition.Field, condition.Value, "~", tableName)
+		}
+	case "iregex", "~*":
+		db = ApplyFilters(db, condition.Field, condition.Value, "~*", tableName)
+	case "in":
+		db = ApplyFilters(db, condition.Field, listValues(condition), "IN", tableName)
+	case "nin":
+		db = ApplyFilters(db, condition.Field, listValues(condition), "NOT IN", tableName)
+	case "between":
+		if values := listValues(condition); len(values) == 2 {
+			db = applyExpression(db, condition.Field, "BETWEEN ? AND ?", tableName, values...)
+		}
+	case "isnull":
+		db = applyExpression(db, condition.Field, "IS NULL", tableName)
+	case "notnull":
+		db = applyExpression(db, condition.Field, "IS NOT NULL", tableName)
+	case "last", "since":
+		if condition.TypedValue == nil {
+			bound, err := dsl.RelativeTime(condition.Operator, condition.Value, time.Now())
+			if err != nil {
+				db.AddError(err)
+				return db
+			}
+			value = bound.Unix()
+		}
+		db = ApplyFilters(db, condition.Field, value, ">=", tableName)
 	default:
 	}
 	return db
 }
+
+func listValues(condition *types.QueryCondition) []interface{} {
+	if typedValues, ok := condition.TypedValue.([]interface{}); ok {
+		return typedValues
+	}
+
+	values := make([]interface{}, len(condition.Values))
+	for i, value := range condition.Values {
+		values[i] = value
+	}
+	return values
+}
+
+func applyExpression(db *gorm.DB, field string, expression string, tableName string, args ...interface{}) *gorm.DB {
+	if !columnPattern.MatchString(field) {
+		db.AddError(fmt.Errorf("invalid search field %q", field))
+		return db
+	}
+
+	return db.Where(fmt.Sprintf("%s.%s %s", tableName, field, expression), args...)
+}
+
+func DSLSearchGroup(db *gorm.DB, conditions []types.QueryCondition, tableName string) *gorm.DB {
+	group := newGroup(db)
+
+	for i := range conditions {
+		condition := conditions[i]
+
+		var expr *gorm.DB
+		if len(condition.Group) > 0 {
+			expr = DSLSearchGroup(db, condition.Group, tableName)
+		} else {
+			expr = DSLSearchOperator(newGroup(db), &condition, tableName)
+		}
+
+		if expr.Error != nil {
+			db.AddError(expr.Error)
+			continue
+		}
+
+		if !HasConditions(expr) {
+			continue
+		}
+
+		if condition.Negate {
+			expr = newGroup(db).Not(expr)
+		}
+
+		if i > 0 && condition.Logic == types.LogicOr {
+			group = group.Or(expr)
+		} else {
+			group = group.Where(expr)
+		}
+	}
+
+	return group
+}
+
+func HasConditions(db *gorm.DB) bool {
+	_, ok := db.Statement.Clauses["WHERE"]
+	return ok
+}
+
+func newGroup(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true, Initialized: true})
+}
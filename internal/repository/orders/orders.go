@@ -2,8 +2,8 @@ package orders
 
 import (
 	"context"
+	"fmt"
 	"reflect"
-	"strings"
 
 	"github.com/denizumutdereli/stream-admin/internal/builders"
 	"github.com/denizumutdereli/stream-admin/internal/config"
@@ -17,12 +17,23 @@ import (
 
 type OrdersRepository interface {
 	GetAll(paginationParams *types.PaginationParams, searchParams *models.OrderSearch) (*database.PaginatedResult, error)
+	Export(exportParams *types.ExportParams, searchParams *models.OrderSearch, handle database.RowHandler) (int, error)
+	Aggregate(aggregateParams *types.AggregateParams, searchParams *models.OrderSearch) (*database.AggregatedResult, error)
 	ExceptExchangeBotUser(db *gorm.DB) *gorm.DB
 	JoinWithTradeOrders(db *gorm.DB) *gorm.DB
+	JoinWithTradeCommissions(db *gorm.DB) *gorm.DB
 	GroupByOrderID(db *gorm.DB) *gorm.DB
 	SelectFieldsWithCommission(fields []string, targetStruct interface{}) func(db *gorm.DB) *gorm.DB
 }
 
+// commission columns are served from the trade join, so sorting on them
+// targets the computed select aliases
+var commissionSortAliases = map[string]string{
+	"commission":      "calculated_commission",
+	"commission_try":  "calculated_commission_try",
+	"commission_usdt": "calculated_commission_usdt",
+}
+
 type RepoConfig struct {
 	ServicePrefix    string
 	OrdersTable      string
@@ -61,12 +72,9 @@ func NewGORMOrdersRepository(database *gorm.DB, servicePrefix string, config *co
 
 func (z *ordersRepository) GetAll(paginationParams *types.PaginationParams, searchParams *models.OrderSearch) (*database.PaginatedResult, error) {
 	var data []*models.Order
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.OrdersTable)
 
-	paginationParams.SortOrder = strings.Replace(paginationParams.SortOrder, "commission", "calculated_commission", -1)
-
 	// temporary date format fix. Will be removed when dbs are ready and synchronized with the date types -->
 	// dateFields := []string{"created_at", "updated_at", "deleted_at"}
 	// z.builders.ConvertDateFields(dateFields, z.builders.StructToMap(searchParams), "toUnix")
@@ -80,7 +88,7 @@ func (z *ordersRepository) GetAll(paginationParams *types.PaginationParams, sear
 		z.JoinWithTradeOrders,
 		z.GroupByOrderID,
 		z.SelectFieldsWithCommission(nil, reflect.TypeOf(models.Order{})),
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.OrdersTable, Key: "id", Model: &models.Order{}, Aliases: commissionSortAliases}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.OrdersTable).Scopes(
@@ -88,30 +96,50 @@ func (z *ordersRepository) GetAll(paginationParams *types.PaginationParams, sear
 		z.ExceptExchangeBotUser,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting orders:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
 
+func (z *ordersRepository) Export(exportParams *types.ExportParams, searchParams *models.OrderSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.OrdersTable, z.dslSearchEnabled)
+	fields := database.KeysetFields(exportParams.Fields, "id")
+
+	newQuery := func() *gorm.DB {
+		return z.database.Table(z.repoConfig.OrdersTable).Scopes(
+			whereScope,
+			z.ExceptExchangeBotUser,
+			z.JoinWithTradeOrders,
+			z.GroupByOrderID,
+			z.SelectFieldsWithCommission(fields, reflect.TypeOf(models.Order{})),
+		)
+	}
+
+	return database.StreamByKeyset(newQuery, database.KeysetOptions{
+		KeyColumn: z.repoConfig.OrdersTable + ".id",
+		KeyField:  "id",
+		BatchSize: exportParams.BatchSize,
+		MaxRows:   exportParams.MaxRows,
+	}, handle)
+}
+
 func (z *ordersRepository) GetUserOrders(paginationParams *types.PaginationParams, searchParams *models.OrderSearch) (*database.PaginatedResult, error) {
 	var data []*models.Order
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.OrdersTable)
 
-	paginationParams.SortOrder = strings.Replace(paginationParams.SortOrder, "commission", "calculated_commission", -1)
-
 	// temporary date format fix. Will be removed when dbs are ready and synchronized with the date types -->
 	// dateFields := []string{"created_at", "updated_at", "deleted_at"}
 	// z.builders.ConvertDateFields(dateFields, z.builders.StructToMap(searchParams), "toUnix")
@@ -125,7 +153,7 @@ func (z *ordersRepository) GetUserOrders(paginationParams *types.PaginationParam
 		z.JoinWithTradeOrders,
 		z.GroupByOrderID,
 		z.SelectFieldsWithCommission(nil, reflect.TypeOf(models.Order{})),
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.OrdersTable, Key: "id", Model: &models.Order{}, Aliases: commissionSortAliases}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.OrdersTable).Scopes(
@@ -133,18 +161,58 @@ func (z *ordersRepository) GetUserOrders(paginationParams *types.PaginationParam
 		z.ExceptExchangeBotUser,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting orders:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
+
+// Aggregate groups orders in a single query. Commission metrics use the same
+// trade based commission as the listing, through trades summed per order.
+func (z *ordersRepository) Aggregate(aggregateParams *types.AggregateParams, searchParams *models.OrderSearch) (*database.AggregatedResult, error) {
+	var data []map[string]interface{}
+
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.OrdersTable, z.dslSearchEnabled)
+
+	target := scopes.AggregateTarget{
+		Table:       z.repoConfig.OrdersTable,
+		Model:       &models.Order{},
+		TimeColumns: []string{"created_at", "updated_at", "cancelled_at"},
+		Measures: map[string]string{
+			"commission":      fmt.Sprintf("COALESCE(t.taker_commission, %s.commission)", z.repoConfig.OrdersTable),
+			"commission_try":  fmt.Sprintf("COALESCE(t.taker_commission_try, %s.commission_try)", z.repoConfig.OrdersTable),
+			"commission_usdt": fmt.Sprintf("COALESCE(t.taker_commission_usdt, %s.commission_usdt)", z.repoConfig.OrdersTable),
+		},
+	}
+
+	query := z.database.Table(z.repoConfig.OrdersTable).Scopes(
+		whereScope,
+		z.ExceptExchangeBotUser,
+	)
+
+	for _, metric := range aggregateParams.Metrics {
+		if _, ok := target.Measures[metric.Column]; ok {
+			query = query.Scopes(z.JoinWithTradeCommissions)
+			break
+		}
+	}
+
+	if err := query.Scopes(scopes.Aggregate(aggregateParams, target)).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	groupBy, metrics := aggregateParams.Aliases()
+
+	return database.AggregateTheResults(data, groupBy, metrics, aggregateParams.MaxGroups), nil
+}
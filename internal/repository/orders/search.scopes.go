@@ -18,6 +18,13 @@ func (z *ordersRepository) JoinWithTradeOrders(db *gorm.DB) *gorm.DB {
 	return db.Joins(query)
 }
 
+// JoinWithTradeCommissions joins the taker commissions summed per maker order,
+// so an order is not repeated once per trade in grouped queries.
+func (z *ordersRepository) JoinWithTradeCommissions(db *gorm.DB) *gorm.DB {
+	query := fmt.Sprintf("LEFT JOIN (SELECT maker_order_id, SUM(taker_commission) AS taker_commission, SUM(taker_commission_try) AS taker_commission_try, SUM(taker_commission_usdt) AS taker_commission_usdt FROM %s GROUP BY maker_order_id) t ON %s.client_order_id = t.maker_order_id", z.repoConfig.TradeOrdersTable, z.repoConfig.OrdersTable)
+	return db.Joins(query)
+}
+
 func (z *ordersRepository) GroupByOrderID(db *gorm.DB) *gorm.DB {
 	return db.Group(fmt.Sprintf("%s.id", z.repoConfig.OrdersTable))
 }
@@ -0,0 +1,142 @@
+package scopes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"gorm.io/gorm"
+)
+
+// AggregateTarget describes what a resource may be aggregated on: the columns
+// of Model in Table, TimeColumns that accept hour and day buckets, and
+// Measures for metric fields computed from joins.
+type AggregateTarget struct {
+	Table       string
+	Model       interface{}
+	TimeColumns []string
+	Measures    map[string]string
+}
+
+// Aggregate selects the requested groups and metrics as one grouped query.
+// Groups come back ordered by their values; one extra group is fetched past
+// MaxGroups so the caller can tell the result was cut. Unknown fields fail
+// the query with a types.AggregateFieldError.
+func Aggregate(aggregateParams *types.AggregateParams, target AggregateTarget) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		details := make(map[string]string)
+		var selects, groups []string
+
+		for _, group := range aggregateParams.GroupBy {
+			column, err := target.groupColumn(group)
+			if err != nil {
+				details[group.Alias()] = err.Error()
+				continue
+			}
+			selects = append(selects, fmt.Sprintf("%s AS %s", column, group.Alias()))
+			groups = append(groups, column)
+		}
+
+		for _, metric := range aggregateParams.Metrics {
+			column, err := target.metricColumn(metric)
+			if err != nil {
+				details[metric.Alias()] = err.Error()
+				continue
+			}
+			selects = append(selects, fmt.Sprintf("%s AS %s", column, metric.Alias()))
+		}
+
+		if len(details) > 0 {
+			db.AddError(&types.AggregateFieldError{Details: details})
+			return db
+		}
+
+		db = db.Select(strings.Join(selects, ", "))
+
+		if len(groups) > 0 {
+			db = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", "))
+		}
+
+		if aggregateParams.MaxGroups > 0 {
+			db = db.Limit(aggregateParams.MaxGroups + 1)
+		}
+
+		return db
+	}
+}
+
+func (t AggregateTarget) groupColumn(group types.AggregateGroup) (string, error) {
+	field, err := modelField(t.Model, group.Column)
+	if err != nil {
+		return "", fmt.Errorf("unknown group_by field")
+	}
+
+	column := fmt.Sprintf("%s.%s", t.Table, field.DBName)
+	if group.Bucket == "" {
+		return column, nil
+	}
+
+	if group.Bucket != "hour" && group.Bucket != "day" {
+		return "", fmt.Errorf("unknown time bucket %s", group.Bucket)
+	}
+
+	isTimeColumn := false
+	for _, timeColumn := range t.TimeColumns {
+		if timeColumn == field.DBName {
+			isTimeColumn = true
+			break
+		}
+	}
+	if !isTimeColumn {
+		return "", fmt.Errorf("field does not support time buckets")
+	}
+
+	// time columns are stored as unix seconds or text until the tables are
+	// migrated to timestamptz
+	switch field.IndirectFieldType.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("date_trunc('%s', to_timestamp(%s))", group.Bucket, column), nil
+	case reflect.String:
+		return fmt.Sprintf("date_trunc('%s', %s::timestamptz)", group.Bucket, column), nil
+	}
+
+	return fmt.Sprintf("date_trunc('%s', %s)", group.Bucket, column), nil
+}
+
+func (t AggregateTarget) metricColumn(metric types.AggregateMetric) (string, error) {
+	if metric.Column == "" {
+		if metric.Func != "count" {
+			return "", fmt.Errorf("metric requires a field")
+		}
+		return "COUNT(*)", nil
+	}
+
+	column, ok := t.Measures[metric.Column]
+	if !ok {
+		field, err := modelField(t.Model, metric.Column)
+		if err != nil {
+			return "", fmt.Errorf("unknown metric field")
+		}
+		column = fmt.Sprintf("%s.%s", t.Table, field.DBName)
+
+		if metric.Func != "count" {
+			switch field.IndirectFieldType.Kind() {
+			case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			default:
+				return "", fmt.Errorf("%s requires a numeric field", metric.Func)
+			}
+		}
+	}
+
+	switch metric.Func {
+	case "count":
+		return fmt.Sprintf("COUNT(%s)", column), nil
+	case "sum", "avg", "min", "max":
+		// numeric columns come back as text from the driver, double precision
+		// keeps the values as json numbers
+		return fmt.Sprintf("CAST(%s(%s) AS double precision)", strings.ToUpper(metric.Func), column), nil
+	}
+
+	return "", fmt.Errorf("unknown metric function %s", metric.Func)
+}
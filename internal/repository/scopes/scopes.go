@@ -5,6 +5,7 @@ import (
 
 	"github.com/denizumutdereli/stream-admin/internal/dsl"
 	"github.com/denizumutdereli/stream-admin/internal/repository/interpreters"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"gorm.io/gorm"
 )
 
@@ -14,19 +15,48 @@ type FilterParam struct {
 	Value      interface{}
 }
 
-func Paginate(page int, pageSize int) func(db *gorm.DB) *gorm.DB {
+// Paginate applies the page window. Offset mode orders and skips as before;
+// cursor mode seeks past the (sort column, key) position of the cursor and
+// fetches one extra row so the caller knows whether another page exists.
+// Rows with a NULL sort value are not reachable through a cursor.
+func Paginate(paginationParams *types.PaginationParams, target SortTarget) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		offset := (page - 1) * pageSize
-		return db.Offset(offset).Limit(pageSize)
-	}
-}
+		cursor := paginationParams.Cursor
+		if cursor == nil {
+			offset := (paginationParams.Page - 1) * paginationParams.Limit
+			return OrderBy(paginationParams.Sort, target)(db).Offset(offset).Limit(paginationParams.Limit)
+		}
 
-func OrderBy(sortBy string, sortOrder string) func(db *gorm.DB) *gorm.DB {
-	return func(db *gorm.DB) *gorm.DB {
-		if sortBy != "" && sortOrder != "" {
-			db = db.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
+		key := fmt.Sprintf("%s.%s", target.Table, target.Key)
+
+		direction, comparison := "ASC", ">"
+		if cursor.Descending() != cursor.Backward {
+			direction, comparison = "DESC", "<"
 		}
-		return db
+
+		sortColumn := ""
+		if cursor.SortBy != "" && cursor.SortBy != target.Key {
+			column, err := target.sortColumn(cursor.SortBy, false)
+			if err != nil {
+				db.AddError(&types.SortFieldError{Details: map[string]string{cursor.SortBy: err.Error()}})
+				return db
+			}
+			sortColumn = column
+		}
+
+		if cursor.HasPosition() {
+			if sortColumn != "" {
+				db = db.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", sortColumn, key, comparison), cursor.Value, cursor.Key)
+			} else {
+				db = db.Where(fmt.Sprintf("%s %s ?", key, comparison), cursor.Key)
+			}
+		}
+
+		if sortColumn != "" {
+			db = db.Order(fmt.Sprintf("%s %s", sortColumn, direction))
+		}
+
+		return db.Order(fmt.Sprintf("%s %s", key, direction)).Limit(paginationParams.Limit + 1)
 	}
 }
 
@@ -59,9 +89,9 @@ func ApplySearchFilters(params interface{}, tableName string, isDslEnable bool)
 		db = interpreters.ProcessFields(db, params, omitFields, tableName)
 
 		if searcher, ok := params.(dsl.DSLSearcher); ok && isDslEnable {
-			if ops := searcher.GetDSLSearchOperator(); ops != nil {
-				for _, condition := range *ops {
-					db = interpreters.DSLSearchOperator(db, &condition, tableName)
+			if ops := searcher.GetDSLSearchOperator(); ops != nil && len(*ops) > 0 {
+				if group := interpreters.DSLSearchGroup(db, *ops, tableName); interpreters.HasConditions(group) {
+					db = db.Where(group)
 				}
 			}
 		}
@@ -0,0 +1,76 @@
+package scopes
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var modelSchemas = &sync.Map{}
+
+// SortTarget describes what a list query may be sorted on: the columns of
+// Model in Table, and Aliases for computed select expressions, which are
+// only usable in offset mode.
+type SortTarget struct {
+	Table   string
+	Key     string
+	Model   interface{}
+	Aliases map[string]string
+}
+
+// OrderBy orders by the given fields after checking each one against the
+// model schema. Unknown fields fail the query with a types.SortFieldError.
+func OrderBy(sort []types.SortField, target SortTarget) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		details := make(map[string]string)
+
+		for _, field := range sort {
+			column, err := target.sortColumn(field.Column, true)
+			if err != nil {
+				details[field.Column] = err.Error()
+				continue
+			}
+			db = db.Order(fmt.Sprintf("%s %s", column, field.Order))
+		}
+
+		if len(details) > 0 {
+			db.AddError(&types.SortFieldError{Details: details})
+		}
+
+		return db
+	}
+}
+
+func (t SortTarget) sortColumn(field string, allowAliases bool) (string, error) {
+	if alias, ok := t.Aliases[field]; ok {
+		if !allowAliases {
+			return "", fmt.Errorf("computed field is not available with cursor pagination")
+		}
+		return alias, nil
+	}
+
+	column, err := modelField(t.Model, field)
+	if err != nil {
+		return "", fmt.Errorf("unknown sort field")
+	}
+
+	return fmt.Sprintf("%s.%s", t.Table, column.DBName), nil
+}
+
+// modelField looks up a readable column of model by its database name.
+func modelField(model interface{}, column string) (*schema.Field, error) {
+	modelSchema, err := schema.Parse(model, modelSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+
+	field, ok := modelSchema.FieldsByDBName[column]
+	if !ok || !field.Readable {
+		return nil, fmt.Errorf("unknown field %s", column)
+	}
+
+	return field, nil
+}
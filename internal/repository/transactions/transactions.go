@@ -19,6 +19,11 @@ type TransactionRepository interface {
 	GetFiatTransactions(paginationParams *types.PaginationParams, searchParams *models.FiatTransactionsSearch) (*database.PaginatedResult, error)
 	GetCryptoTransactions(paginationParams *types.PaginationParams, searchParams *models.CryptoTransactionsSearch) (*database.PaginatedResult, error)
 	GetCryptoWallets(paginationParams *types.PaginationParams, searchParams *models.CryptoWalletsSearch) (*database.PaginatedResult, error)
+	ExportFiatTransactions(exportParams *types.ExportParams, searchParams *models.FiatTransactionsSearch, handle database.RowHandler) (int, error)
+	ExportCryptoTransactions(exportParams *types.ExportParams, searchParams *models.CryptoTransactionsSearch, handle database.RowHandler) (int, error)
+	ExportCryptoWallets(exportParams *types.ExportParams, searchParams *models.CryptoWalletsSearch, handle database.RowHandler) (int, error)
+	AggregateFiatTransactions(aggregateParams *types.AggregateParams, searchParams *models.FiatTransactionsSearch) (*database.AggregatedResult, error)
+	AggregateCryptoTransactions(aggregateParams *types.AggregateParams, searchParams *models.CryptoTransactionsSearch) (*database.AggregatedResult, error)
 }
 
 type RepoConfig struct {
@@ -90,7 +95,6 @@ func (z *transactionRepository) GetSearchParameters() ([]types.SearchParameters,
 
 func (z *transactionRepository) GetFiatTransactions(paginationParams *types.PaginationParams, searchParams *models.FiatTransactionsSearch) (*database.PaginatedResult, error) {
 	var data []models.FiatTransactions
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.FiatTransactionsTable)
 
@@ -103,32 +107,32 @@ func (z *transactionRepository) GetFiatTransactions(paginationParams *types.Pagi
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.FiatTransactionsTable, Key: "id", Model: &models.FiatTransactions{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.FiatTransactionsTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
 
 func (z *transactionRepository) GetCryptoTransactions(paginationParams *types.PaginationParams, searchParams *models.CryptoTransactionsSearch) (*database.PaginatedResult, error) {
 	var data []models.CryptoTransactions
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.CryptoTransactionsTable)
 
@@ -141,32 +145,32 @@ func (z *transactionRepository) GetCryptoTransactions(paginationParams *types.Pa
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.CryptoTransactionsTable, Key: "id", Model: &models.CryptoTransactions{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.CryptoTransactionsTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
 
 func (z *transactionRepository) GetCryptoWallets(paginationParams *types.PaginationParams, searchParams *models.CryptoWalletsSearch) (*database.PaginatedResult, error) {
 	var data []models.CryptoWallets
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.CryptoWalletsTable)
 
@@ -179,25 +183,94 @@ func (z *transactionRepository) GetCryptoWallets(paginationParams *types.Paginat
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.CryptoWalletsTable, Key: "id", Model: &models.CryptoWallets{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.CryptoTransactionsTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
+
+func (z *transactionRepository) ExportFiatTransactions(exportParams *types.ExportParams, searchParams *models.FiatTransactionsSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.FiatTransactionsTable, z.dslSearchEnabled)
+	return z.streamTable(z.repoConfig.FiatTransactionsTable, whereScope, exportParams, reflect.TypeOf(models.FiatTransactions{}), handle)
+}
+
+func (z *transactionRepository) ExportCryptoTransactions(exportParams *types.ExportParams, searchParams *models.CryptoTransactionsSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.CryptoTransactionsTable, z.dslSearchEnabled)
+	return z.streamTable(z.repoConfig.CryptoTransactionsTable, whereScope, exportParams, reflect.TypeOf(models.CryptoTransactions{}), handle)
+}
+
+func (z *transactionRepository) ExportCryptoWallets(exportParams *types.ExportParams, searchParams *models.CryptoWalletsSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.CryptoWalletsTable, z.dslSearchEnabled)
+	return z.streamTable(z.repoConfig.CryptoWalletsTable, whereScope, exportParams, reflect.TypeOf(models.CryptoWallets{}), handle)
+}
+
+func (z *transactionRepository) streamTable(table string, whereScope func(db *gorm.DB) *gorm.DB, exportParams *types.ExportParams, targetStruct interface{}, handle database.RowHandler) (int, error) {
+	selectFields, err := z.builders.SelectFields(database.KeysetFields(exportParams.Fields, "id"), table, targetStruct)
+	if err != nil {
+		z.logger.Error("select fields failed", zap.Error(err))
+	}
+
+	newQuery := func() *gorm.DB {
+		return z.database.Table(table).Scopes(whereScope).Select(selectFields)
+	}
+
+	return database.StreamByKeyset(newQuery, database.KeysetOptions{
+		KeyColumn: table + ".id",
+		KeyField:  "id",
+		BatchSize: exportParams.BatchSize,
+		MaxRows:   exportParams.MaxRows,
+	}, handle)
+}
+
+func (z *transactionRepository) AggregateFiatTransactions(aggregateParams *types.AggregateParams, searchParams *models.FiatTransactionsSearch) (*database.AggregatedResult, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.FiatTransactionsTable, z.dslSearchEnabled)
+	return z.aggregateTable(whereScope, aggregateParams, scopes.AggregateTarget{
+		Table:       z.repoConfig.FiatTransactionsTable,
+		Model:       &models.FiatTransactions{},
+		TimeColumns: []string{"created_at", "updated_at", "timestamp"},
+	})
+}
+
+func (z *transactionRepository) AggregateCryptoTransactions(aggregateParams *types.AggregateParams, searchParams *models.CryptoTransactionsSearch) (*database.AggregatedResult, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.CryptoTransactionsTable, z.dslSearchEnabled)
+	return z.aggregateTable(whereScope, aggregateParams, scopes.AggregateTarget{
+		Table:       z.repoConfig.CryptoTransactionsTable,
+		Model:       &models.CryptoTransactions{},
+		TimeColumns: []string{"created_at", "updated_at"},
+	})
+}
+
+func (z *transactionRepository) aggregateTable(whereScope func(db *gorm.DB) *gorm.DB, aggregateParams *types.AggregateParams, target scopes.AggregateTarget) (*database.AggregatedResult, error) {
+	var data []map[string]interface{}
+
+	query := z.database.Table(target.Table).Scopes(
+		whereScope,
+		scopes.Aggregate(aggregateParams, target),
+	)
+
+	if err := query.Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	groupBy, metrics := aggregateParams.Aliases()
+
+	return database.AggregateTheResults(data, groupBy, metrics, aggregateParams.MaxGroups), nil
+}
@@ -1,16 +1,18 @@
 package users
 
 import (
+	"reflect"
+
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	models "github.com/denizumutdereli/stream-admin/internal/models/users"
 	"github.com/denizumutdereli/stream-admin/internal/repository/scopes"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 func (z *usersRepository) GetKYC(paginationParams *types.PaginationParams, searchParams *models.UserKYCSearch) (*database.PaginatedResult, error) {
 	var data []models.UserKYCSearch
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.KycTable)
 
@@ -23,20 +25,18 @@ func (z *usersRepository) GetKYC(paginationParams *types.PaginationParams, searc
 
 	query := db.Scopes(
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.KycTable, Key: "id", Model: &models.UserKYC{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.KycTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
@@ -68,7 +68,32 @@ func (z *usersRepository) GetKYC(paginationParams *types.PaginationParams, searc
 		data[i].KYCFiles = &kycFiles
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
+
+// ExportKYC streams the KYC rows only; the file listings GetKYC attaches per
+// record are left out of exports.
+func (z *usersRepository) ExportKYC(exportParams *types.ExportParams, searchParams *models.UserKYCSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.KycTable, z.dslSearchEnabled)
+
+	selectFields, err := z.builders.SelectFields(database.KeysetFields(exportParams.Fields, "id"), z.repoConfig.KycTable, reflect.TypeOf(models.UserKYCSearch{}))
+	if err != nil {
+		z.logger.Error("select fields failed", zap.Error(err))
+	}
+
+	newQuery := func() *gorm.DB {
+		return z.database.Table(z.repoConfig.KycTable).Scopes(whereScope).Select(selectFields)
+	}
+
+	return database.StreamByKeyset(newQuery, database.KeysetOptions{
+		KeyColumn: z.repoConfig.KycTable + ".id",
+		KeyField:  "id",
+		BatchSize: exportParams.BatchSize,
+		MaxRows:   exportParams.MaxRows,
+	}, handle)
+}
@@ -29,6 +29,8 @@ type UsersRepository interface {
 	GetUsers(paginationParams *types.PaginationParams, searchParams *models.UserSearch) (*database.PaginatedResult, error)
 	GetUserDetailsBuilder(userId int, includeDetails *userTypes.UserDetailsIncluding, paginationParams *types.PaginationParams, orderRepo ordersRepos.OrdersRepository, transactionRepo transactionsRepos.TransactionRepository) (*database.DataResult, error)
 	GetKYC(paginationParams *types.PaginationParams, searchParams *models.UserKYCSearch) (*database.PaginatedResult, error)
+	ExportUsers(exportParams *types.ExportParams, searchParams *models.UserSearch, handle database.RowHandler) (int, error)
+	ExportKYC(exportParams *types.ExportParams, searchParams *models.UserKYCSearch, handle database.RowHandler) (int, error)
 }
 
 type RepoConfig struct {
@@ -161,7 +163,6 @@ func (z *usersRepository) GetSearchKYCParameters() ([]types.SearchParameters, er
 
 func (z *usersRepository) GetUsers(paginationParams *types.PaginationParams, searchParams *models.UserSearch) (*database.PaginatedResult, error) {
 	var data []models.SearchWithSettings
-	var count int64
 
 	db := z.database.Debug().Table(z.repoConfig.UserTable)
 
@@ -176,25 +177,53 @@ func (z *usersRepository) GetUsers(paginationParams *types.PaginationParams, sea
 		z.JoinWithUserSettings,
 		z.SelectFieldsWithSettings([]string{"user_id,language,theme,currency,favorite_pairs"}, reflect.TypeOf(models.UserSettings{})),
 		whereScope,
-		scopes.OrderBy(paginationParams.SortBy, paginationParams.SortOrder),
+		scopes.Paginate(paginationParams, scopes.SortTarget{Table: z.repoConfig.UserTable, Key: "id", Model: &models.User{}}),
 	)
 
 	countQuery := z.database.Table(z.repoConfig.UserTable).Scopes(
 		whereScope,
 	)
 
-	if err := countQuery.Count(&count).Error; err != nil {
+	total, err := database.CountRows(countQuery, paginationParams.CountMode)
+	if err != nil {
 		z.logger.Error("error counting data:", zap.Error(err))
 	}
 
-	offset := (paginationParams.Page - 1) * paginationParams.Limit
-	query = query.Offset(offset).Limit(paginationParams.Limit)
-
 	if err := query.Find(&data).Error; err != nil {
 		return nil, err
 	}
 
-	paginatedResults := database.PaginateTheResults(data, count, offset, paginationParams.Page, paginationParams.Limit)
+	paginatedResults, err := database.PaginateThePage(data, total, paginationParams.Page, paginationParams.Limit, paginationParams.Cursor, "id")
+	if err != nil {
+		return nil, err
+	}
 
 	return paginatedResults, nil
 }
+
+func (z *usersRepository) ExportUsers(exportParams *types.ExportParams, searchParams *models.UserSearch, handle database.RowHandler) (int, error) {
+	whereScope := scopes.ApplySearchFilters(searchParams, z.repoConfig.UserTable, z.dslSearchEnabled)
+	fields := database.KeysetFields(exportParams.Fields, "id")
+
+	selectScope := z.SelectFieldsWithSettings([]string{"user_id,language,theme,currency,favorite_pairs"}, reflect.TypeOf(models.UserSettings{}))
+	if len(fields) > 0 {
+		selectFields, err := z.builders.SelectFields(fields, z.repoConfig.UserTable, reflect.TypeOf(models.User{}))
+		if err != nil {
+			z.logger.Error("select fields failed", zap.Error(err))
+		}
+		selectScope = func(db *gorm.DB) *gorm.DB {
+			return db.Select(selectFields)
+		}
+	}
+
+	newQuery := func() *gorm.DB {
+		return z.database.Table(z.repoConfig.UserTable).Scopes(z.JoinWithUserSettings, selectScope, whereScope)
+	}
+
+	return database.StreamByKeyset(newQuery, database.KeysetOptions{
+		KeyColumn: z.repoConfig.UserTable + ".id",
+		KeyField:  "id",
+		BatchSize: exportParams.BatchSize,
+		MaxRows:   exportParams.MaxRows,
+	}, handle)
+}
@@ -3,6 +3,8 @@ package router
 import (
 	"net/http"
 
+	"github.com/denizumutdereli/stream-admin/internal/middleware"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -24,23 +26,85 @@ func (rc *routerController) adminAuthRoutes(defaultGroup *gin.RouterGroup) {
 			Method:      http.MethodPost,
 			Path:        "/auth",
 			HandlerFunc: serviceHandler.Login,
+			RateLimit:   &middleware.RateLimit{Requests: 10},
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/verify",
 			HandlerFunc: serviceHandler.VerifyOTPAndLogin,
 			Middlewares: rc.attachMiddlewaresDirect(rc.optGuardMiddleware),
+			RateLimit:   &middleware.RateLimit{Requests: 10},
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/refresh",
 			HandlerFunc: serviceHandler.RefreshToken,
+			RateLimit:   &middleware.RateLimit{Requests: 30},
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/logout",
 			HandlerFunc: serviceHandler.Logout,
-			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware),
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/stepup",
+			HandlerFunc: serviceHandler.StepUp,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.passwordChanged, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/totp/enroll",
+			HandlerFunc: serviceHandler.EnrollTOTP,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.passwordChanged, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/totp/confirm",
+			HandlerFunc: serviceHandler.ConfirmTOTP,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.passwordChanged, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/totp/disable",
+			HandlerFunc: serviceHandler.DisableTOTP,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.passwordChanged, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/auth/sessions",
+			HandlerFunc: serviceHandler.GetSessions,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.passwordChanged, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/auth/sessions/:session_id",
+			HandlerFunc: serviceHandler.RevokeSession,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.passwordChanged, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/password/forgot",
+			HandlerFunc: serviceHandler.ForgotPassword,
+			RateLimit:   &middleware.RateLimit{Requests: 5},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/password/reset",
+			HandlerFunc: serviceHandler.ResetPassword,
+			RateLimit:   &middleware.RateLimit{Requests: 5},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/auth/password/change",
+			HandlerFunc: serviceHandler.ChangePassword,
+			Middlewares: rc.attachMiddlewaresDirect(rc.guardMiddleware, rc.audit, rc.userIPAllowed, rc.sessionTimeout, rc.checkUserLock),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/.well-known/jwks.json",
+			HandlerFunc: serviceHandler.JWKS,
 		},
 	}
 
@@ -64,22 +128,25 @@ func (rc *routerController) adminServiceRoutes(adminGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "/live",
 			HandlerFunc: serviceHandler.Live,
+			Source:      types.SourceSystem,
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/read",
 			HandlerFunc: serviceHandler.Read,
+			Source:      types.SourceSystem,
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/metrics",
 			HandlerFunc: serviceHandler.Metrics,
-			Middlewares: rc.attachMiddlewaresDirect(rc.isSuperAdmin),
+			Source:      types.SourceSystem,
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/configs",
 			HandlerFunc: serviceHandler.Configs,
+			Source:      types.SourceSystem,
 		},
 	}
 
@@ -132,19 +199,21 @@ func (rc *routerController) setupAdminRolesRoutes(adminGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "/",
 			HandlerFunc: serviceHandler.GetAdminRoles,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourceRoles,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/create",
 			HandlerFunc: serviceHandler.CreateAdminRole,
-			Middlewares: rc.attachMiddlewaresDirect(rc.isSuperAdmin),
+			Source:      types.SourceRoles,
 		},
 		{
 			Method:      http.MethodPut,
 			Path:        "/attach",
 			HandlerFunc: serviceHandler.AttachPoliciesToRole,
-			Middlewares: rc.attachMiddlewaresDirect(rc.isSuperAdmin),
+			Source:      types.SourceRoles,
+			StepUp:      true,
 		},
 	}
 
@@ -171,31 +240,183 @@ func (rc *routerController) setupAdminPolicyRoutes(adminGroup *gin.RouterGroup)
 			Method:      http.MethodGet,
 			Path:        "/",
 			HandlerFunc: serviceHandler.GetAdminRolePolicies,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourcePolicies,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/create",
 			HandlerFunc: serviceHandler.CreateAdminRolePolicy,
-			Middlewares: rc.attachMiddlewaresDirect(rc.isSuperAdmin),
+			Source:      types.SourcePolicies,
+			StepUp:      true,
 		},
 		{
 			Method:      http.MethodPut,
 			Path:        "/update",
 			HandlerFunc: serviceHandler.UpdateAdminRolePolicy,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourcePolicies,
+			StepUp:      true,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 		{
 			Method:      http.MethodDelete,
 			Path:        "/delete/:policy_id",
 			HandlerFunc: serviceHandler.DeleteAdminRolePolicy,
-			Middlewares: rc.attachMiddlewaresDirect(rc.isSuperAdmin),
+			Source:      types.SourcePolicies,
+			StepUp:      true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/compile",
+			HandlerFunc: serviceHandler.CompileAdminRolePolicy,
+			Source:      types.SourcePolicies,
+			Action:      types.ActionRead,
 		},
 	}
 
 	rc.registerRoutesToGroup(adminPolicy, routes)
 }
 
+func (rc *routerController) setupAdminSavedSearchRoutes(adminGroup *gin.RouterGroup) {
+
+	serviceHandler, err := rc.handlers.GetAdminSavedSearchHandler()
+	if err != nil {
+		rc.logger.Error("unable to get admin saved search handler", zap.Error(err))
+		return
+	}
+
+	if serviceHandler == nil {
+		rc.logger.Error("service adminSavedSearch handler is nil", zap.Error(err))
+		return
+	}
+
+	adminSearches := adminGroup.Group("/searches")
+
+	routes := []RouteDefinition{
+		{
+			Method:      http.MethodGet,
+			Path:        "/",
+			HandlerFunc: serviceHandler.GetSavedSearches,
+			Source:      types.SourceSearches,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:search_id",
+			HandlerFunc: serviceHandler.GetSavedSearch,
+			Source:      types.SourceSearches,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/create",
+			HandlerFunc: serviceHandler.CreateSavedSearch,
+			Source:      types.SourceSearches,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/update",
+			HandlerFunc: serviceHandler.UpdateSavedSearch,
+			Source:      types.SourceSearches,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/delete/:search_id",
+			HandlerFunc: serviceHandler.DeleteSavedSearch,
+			Source:      types.SourceSearches,
+		},
+	}
+
+	rc.registerRoutesToGroup(adminSearches, routes)
+}
+
+func (rc *routerController) setupAdminAllowlistRoutes(adminGroup *gin.RouterGroup) {
+
+	serviceHandler, err := rc.handlers.GetAdminAllowlistHandler()
+	if err != nil {
+		rc.logger.Error("unable to get admin allowlist handler", zap.Error(err))
+		return
+	}
+
+	if serviceHandler == nil {
+		rc.logger.Error("service adminAllowlist handler is nil", zap.Error(err))
+		return
+	}
+
+	adminAllowlist := adminGroup.Group("/allowlist")
+
+	routes := []RouteDefinition{
+		{
+			Method:      http.MethodGet,
+			Path:        "/",
+			HandlerFunc: serviceHandler.GetEntries,
+			Source:      types.SourceAllowlist,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:entry_id",
+			HandlerFunc: serviceHandler.GetEntry,
+			Source:      types.SourceAllowlist,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/create",
+			HandlerFunc: serviceHandler.CreateEntry,
+			Source:      types.SourceAllowlist,
+			StepUp:      true,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/update",
+			HandlerFunc: serviceHandler.UpdateEntry,
+			Source:      types.SourceAllowlist,
+			StepUp:      true,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/delete/:entry_id",
+			HandlerFunc: serviceHandler.DeleteEntry,
+			Source:      types.SourceAllowlist,
+			StepUp:      true,
+		},
+	}
+
+	rc.registerRoutesToGroup(adminAllowlist, routes)
+}
+
+func (rc *routerController) setupAdminSessionsRoutes(adminGroup *gin.RouterGroup) {
+	serviceHandler, err := rc.handlers.GetAdminAuthHandler()
+	if err != nil {
+		rc.logger.Error("unable to get admin auth handler", zap.Error(err))
+		return
+	}
+
+	if serviceHandler == nil {
+		rc.logger.Error("service adminAuth handler is nil", zap.Error(err))
+		return
+	}
+
+	adminSessions := adminGroup.Group("/sessions")
+
+	routes := []RouteDefinition{
+		{
+			Method:      http.MethodGet,
+			Path:        "/:user_id",
+			HandlerFunc: serviceHandler.GetUserSessions,
+			Source:      types.SourceAdminUsers,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/:user_id",
+			HandlerFunc: serviceHandler.RevokeUserSessions,
+			Source:      types.SourceAdminUsers,
+			Action:      types.ActionUpdate,
+		},
+	}
+
+	rc.registerRoutesToGroup(adminSessions, routes)
+}
+
 func (rc *routerController) setupAdminUsersRoutes(adminGroup *gin.RouterGroup) {
 	serviceHandler, err := rc.handlers.GetAdminUsersHandler()
 	if err != nil {
@@ -215,31 +436,38 @@ func (rc *routerController) setupAdminUsersRoutes(adminGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "/",
 			HandlerFunc: serviceHandler.GetAdminUsers,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourceAdminUsers,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/verify",
 			HandlerFunc: serviceHandler.VerifyAdminUser,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.optGuardMiddleware, rc.isSuperAdmin),
+			Source:      types.SourceAdminUsers,
+			Action:      types.ActionUpdate,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.optGuardMiddleware),
 		},
 		{
 			Method:      http.MethodPost,
 			Path:        "/create",
 			HandlerFunc: serviceHandler.CreateAdminUser,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourceAdminUsers,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 		{
 			Method:      http.MethodPut,
 			Path:        "/update",
 			HandlerFunc: serviceHandler.UpdateAdminUser,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourceAdminUsers,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 		{
 			Method:      http.MethodDelete,
 			Path:        "/delete/:user_id",
 			HandlerFunc: serviceHandler.DeleteAdminUser,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.notDeleteOwnUser, rc.isSuperAdmin),
+			Source:      types.SourceAdminUsers,
+			StepUp:      true,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.notDeleteOwnUser),
 		},
 	}
 
@@ -264,9 +492,63 @@ func (rc *routerController) setupAdminLogsRoutes(adminGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "/logs",
 			HandlerFunc: serviceHandler.GetAll,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.isSuperAdmin),
+			Source:      types.SourceLogs,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/logs/verify",
+			HandlerFunc: serviceHandler.Verify,
+			Source:      types.SourceLogs,
 		},
 	}
 
 	rc.registerRoutesToGroup(adminGroup, routes)
 }
+
+func (rc *routerController) setupApprovalsRoutes(approvalsGroup *gin.RouterGroup) {
+
+	serviceHandler, err := rc.handlers.GetAdminApprovalsHandler()
+	if err != nil {
+		rc.logger.Error("unable to get admin approvals handler", zap.Error(err))
+		return
+	}
+
+	if serviceHandler == nil {
+		rc.logger.Error("service adminApprovals handler is nil", zap.Error(err))
+		return
+	}
+
+	routes := []RouteDefinition{
+		{
+			Method:      http.MethodGet,
+			Path:        "/",
+			HandlerFunc: serviceHandler.GetApprovals,
+			Source:      types.SourceApprovals,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/:approval_id",
+			HandlerFunc: serviceHandler.GetApproval,
+			Source:      types.SourceApprovals,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/approve/:approval_id",
+			HandlerFunc: serviceHandler.ApproveRequest,
+			Source:      types.SourceApprovals,
+			Action:      types.ActionUpdate,
+			StepUp:      true,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/reject/:approval_id",
+			HandlerFunc: serviceHandler.RejectRequest,
+			Source:      types.SourceApprovals,
+			Action:      types.ActionUpdate,
+		},
+	}
+
+	rc.registerRoutesToGroup(approvalsGroup, routes)
+}
@@ -3,8 +3,13 @@ package router
 import (
 	"github.com/denizumutdereli/stream-admin/internal/comm/message"
 	"github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/allowlist"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/approvals"
+	authService "github.com/denizumutdereli/stream-admin/internal/service/administrator/auth"
 	"github.com/denizumutdereli/stream-admin/internal/service/administrator/logs"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
 	"github.com/denizumutdereli/stream-admin/internal/service/administrator/roles"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/searches"
 	"github.com/denizumutdereli/stream-admin/internal/service/administrator/users"
 	"go.uber.org/zap"
 )
@@ -36,6 +41,30 @@ func (rc *routerController) adminRoleService() roles.AdminUserRolesService {
 	return adminRoleService
 }
 
+func (rc *routerController) adminPolicyService() policy.AdminPolicyService {
+	adminPolicyService, err := rc.services.GetAdminPolicyService()
+	if err != nil {
+		rc.logger.Error("error getting admin policy service", zap.Error(err))
+	}
+
+	if adminPolicyService == nil {
+		rc.logger.Error("no admin policy service found", zap.Error(err))
+	}
+	return adminPolicyService
+}
+
+func (rc *routerController) adminAuthService() authService.AdminAuthService {
+	adminAuthService, err := rc.services.GetAdminAuthService()
+	if err != nil {
+		rc.logger.Error("error getting admin auth service", zap.Error(err))
+	}
+
+	if adminAuthService == nil {
+		rc.logger.Error("no admin auth service found", zap.Error(err))
+	}
+	return adminAuthService
+}
+
 func (rc *routerController) adminLogService() logs.AdminLogsService {
 	adminLogs, err := rc.services.GetAdminLogsService()
 	if err != nil {
@@ -48,6 +77,42 @@ func (rc *routerController) adminLogService() logs.AdminLogsService {
 	return adminLogs
 }
 
+func (rc *routerController) savedSearchService() searches.AdminSavedSearchService {
+	savedSearchService, err := rc.services.GetAdminSavedSearchService()
+	if err != nil {
+		rc.logger.Error("error getting admin saved search service", zap.Error(err))
+	}
+
+	if savedSearchService == nil {
+		rc.logger.Error("no admin saved search service found", zap.Error(err))
+	}
+	return savedSearchService
+}
+
+func (rc *routerController) approvalsService() approvals.AdminApprovalsService {
+	approvalsService, err := rc.services.GetAdminApprovalsService()
+	if err != nil {
+		rc.logger.Error("error getting admin approvals service", zap.Error(err))
+	}
+
+	if approvalsService == nil {
+		rc.logger.Error("no admin approvals service found", zap.Error(err))
+	}
+	return approvalsService
+}
+
+func (rc *routerController) allowlistService() allowlist.AdminAllowlistService {
+	allowlistService, err := rc.services.GetAdminAllowlistService()
+	if err != nil {
+		rc.logger.Error("error getting admin allowlist service", zap.Error(err))
+	}
+
+	if allowlistService == nil {
+		rc.logger.Error("no admin allowlist service found", zap.Error(err))
+	}
+	return allowlistService
+}
+
 func (rc *routerController) contextMessageService() message.ContextMessages {
 	contextMessagesService, err := rc.services.GetAdminContextMessageService()
 
@@ -4,7 +4,10 @@ import (
 	"fmt"
 
 	"github.com/denizumutdereli/stream-admin/internal/middleware"
+	reportModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 /* direct middlewares ------------------------------------------------------------------------------ */
@@ -14,42 +17,80 @@ func (rc *routerController) paginationMiddleware() gin.HandlerFunc {
 	return paginationMiddleware
 }
 
+func (rc *routerController) clientIPMiddleware() gin.HandlerFunc {
+	resolver, err := utils.NewClientIPResolver(rc.config.TrustedProxies, rc.config.ForwardedHeader)
+	if err != nil {
+		rc.logger.Fatal("invalid trusted proxies", zap.Error(err))
+	}
+	return middleware.ClientIP(resolver)
+}
+
 func (rc *routerController) ipLimiterMiddleware() middleware.IpController {
-	ipControllerMiddleware := middleware.NewIPController(rc.config, rc.adminUserService())
+	ipControllerMiddleware := middleware.NewIPController(rc.config, rc.allowlistService(), rc.adminLogService())
 	return ipControllerMiddleware
 }
 
 func (rc *routerController) rateLimiterMiddleware() gin.HandlerFunc {
 	fmt.Println("rate.... --->")
-	rateimiterMiddleware := middleware.NewRateLimiter(rc.config)
+	rateimiterMiddleware := middleware.NewRateLimiter(rc.config, rc.redis)
 	return rateimiterMiddleware.RateLimitMiddleware()
 }
 
+func (rc *routerController) rateLimit(group string, limit middleware.RateLimit) gin.HandlerFunc {
+	return middleware.NewRateLimiter(rc.config, rc.redis).Limit(group, limit)
+}
+
+// groupRateLimit applies the RATE_LIMIT_RULES limit of a route group, so it
+// belongs after the guard to be counted per user.
+func (rc *routerController) groupRateLimit(group string) gin.HandlerFunc {
+	return rc.rateLimit(group, middleware.RateLimit{Requests: rc.config.RateLimitRules.Groups[group]})
+}
+
 func (rc *routerController) guardMiddleware() gin.HandlerFunc {
 	fmt.Println("guarding...")
 	guardMiddleware := middleware.NewGuardMiddleware(rc.config, rc.authRepository(), rc.contextMessageService())
 	return guardMiddleware.Guard()
 }
 
+// audit logs the request once it is handled, so it belongs right after the
+// guard to see every middleware that may end the request.
+func (rc *routerController) audit() gin.HandlerFunc {
+	return middleware.NewAuditMiddleware(rc.config, rc.adminLogService()).Audit()
+}
+
 func (rc *routerController) sessionMiddleware() middleware.SessionMiddleware {
-	sessionMiddleware := middleware.NewSessionMiddleware(rc.config, rc.redis, rc.adminUserService(), rc.contextMessageService())
+	sessionMiddleware := middleware.NewSessionMiddleware(rc.config, rc.redis, rc.adminUserService(), rc.authRepository(), rc.contextMessageService())
 	return sessionMiddleware
 }
 
-/* child middlewares ------------------------------------------------------------------------------- */
-
-func (rc *routerController) ipRangeAllowedMiddleware() gin.HandlerFunc {
-	return rc.ipLimiterMiddleware().IsRangeOfIPAllowed()
+func (rc *routerController) savedSearchMiddleware(resource reportModels.SearchResource) func() gin.HandlerFunc {
+	return func() gin.HandlerFunc {
+		return middleware.NewSavedSearchMiddleware(rc.config, rc.savedSearchService()).ResolveSavedSearch(resource)
+	}
 }
 
+/* child middlewares ------------------------------------------------------------------------------- */
+
 func (rc *routerController) ipAllowedMiddleware() gin.HandlerFunc {
 	return rc.ipLimiterMiddleware().IPAllowedMiddleware()
 }
 
+func (rc *routerController) userIPAllowed() gin.HandlerFunc {
+	return rc.ipLimiterMiddleware().UserIPAllowedMiddleware()
+}
+
 func (rc *routerController) checkUserLock() gin.HandlerFunc {
 	return rc.sessionMiddleware().CheckUserLock()
 }
 
+func (rc *routerController) sessionTimeout() gin.HandlerFunc {
+	return rc.sessionMiddleware().RefreshTimeout()
+}
+
+func (rc *routerController) passwordChanged() gin.HandlerFunc {
+	return rc.sessionMiddleware().RequirePasswordChanged()
+}
+
 func (rc *routerController) optGuardMiddleware() gin.HandlerFunc {
 	return rc.sessionMiddleware().LimitOTPAttempts()
 }
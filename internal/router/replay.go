@@ -0,0 +1,44 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+// replayApproval runs an approved request through the router again. The
+// replay marker in the request context stands in for the requester's token,
+// so the request passes the same middlewares and policies as the original.
+func (rc *routerController) replayApproval(ctx context.Context, approval *models.AdministratorApproval, replay *types.ApprovalReplay) (int, []byte, error) {
+	target := approval.Path
+	if approval.Query != "" {
+		target += "?" + approval.Query
+	}
+
+	request, err := http.NewRequestWithContext(types.WithApprovalReplay(ctx, replay), approval.Method, target, strings.NewReader(approval.Body))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if approval.ContentType != "" {
+		request.Header.Set("Content-Type", approval.ContentType)
+	}
+	request.Header.Set("User-Agent", approval.UserAgent)
+	request.RemoteAddr = net.JoinHostPort(approval.Ip, "0")
+
+	recorder := httptest.NewRecorder()
+	rc.router.ServeHTTP(recorder, request)
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		return recorder.Code, nil, err
+	}
+
+	return recorder.Code, body, nil
+}
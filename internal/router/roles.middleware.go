@@ -1,23 +1,20 @@
 package router
 
 import (
-	"fmt"
-
 	"github.com/denizumutdereli/stream-admin/internal/middleware"
-	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/gin-gonic/gin"
 )
 
-func (rc *routerController) rolesMiddleware() middleware.RolesMiddleware {
-	rolesMiddleware := middleware.NewRolesMiddleware(rc.config, rc.adminLogService(), rc.adminRoleService())
-	return rolesMiddleware
+func (rc *routerController) policyMiddleware() middleware.PolicyMiddleware {
+	policyMiddleware := middleware.NewPolicyMiddleware(rc.config, rc.adminPolicyService(), rc.adminAuthService(), rc.approvalsService())
+	return policyMiddleware
 }
 
-func (rc *routerController) isDefaultUser() gin.HandlerFunc {
-	return rc.rolesMiddleware().AuthorizeRole(string(models.RegularUser))
+func (rc *routerController) requireStepUp() gin.HandlerFunc {
+	return rc.policyMiddleware().RequireStepUp()
 }
 
-func (rc *routerController) isSuperAdmin() gin.HandlerFunc {
-	fmt.Println(string(models.SuperAdmin), "--->")
-	return rc.rolesMiddleware().AuthorizeRole(string(models.SuperAdmin))
+func (rc *routerController) enforcePolicy(source types.PolicySource, action types.PolicyAction) gin.HandlerFunc {
+	return rc.policyMiddleware().Enforce(source, action)
 }
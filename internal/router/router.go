@@ -4,8 +4,10 @@ import (
 	"fmt"
 
 	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/middleware"
 	"github.com/denizumutdereli/stream-admin/internal/registry"
 	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/denizumutdereli/stream-admin/internal/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -16,13 +18,28 @@ type RouteInfo struct {
 	Method  string
 	Path    string
 	Handler string
+	Source  types.PolicySource
+	Action  types.PolicyAction
 }
 
+// RouteDefinition describes a route to register. Routes with a Source are
+// enforced against the caller's role policies; Action defaults to the one
+// implied by the http method. StepUp routes always ask for a second factor.
+// RateLimit adds a limit of the route's own on top of its group's.
 type RouteDefinition struct {
 	Method      string
 	Path        string
 	HandlerFunc gin.HandlerFunc
 	Middlewares []gin.HandlerFunc
+	Source      types.PolicySource
+	Action      types.PolicyAction
+	StepUp      bool
+	RateLimit   *middleware.RateLimit
+}
+
+type routePolicy struct {
+	Source types.PolicySource
+	Action types.PolicyAction
 }
 
 type RouterController interface {
@@ -49,6 +66,7 @@ type routerController struct {
 	groups      map[string]*gin.RouterGroup
 	groupNames  map[string]string
 	middlewares map[string]gin.HandlerFunc
+	policies    map[string]routePolicy
 }
 
 func NewRouterController(cfg *config.Config, redis *transport.RedisManager, handlers registry.HandlersRegistry, services registry.ServiceRegistry, repos registry.RepositoryRegistry) RouterController {
@@ -63,6 +81,7 @@ func NewRouterController(cfg *config.Config, redis *transport.RedisManager, hand
 		groups:      make(map[string]*gin.RouterGroup),
 		groupNames:  make(map[string]string),
 		middlewares: make(map[string]gin.HandlerFunc),
+		policies:    make(map[string]routePolicy),
 	}
 
 	// Configure CORS middleware
@@ -77,13 +96,21 @@ func NewRouterController(cfg *config.Config, redis *transport.RedisManager, hand
 	gin.ForceConsoleColor()
 	//router settings
 
-	rc.router.SetTrustedProxies(nil)
+	// gin's own ClientIP agrees with the resolver the middlewares use
+	if err := rc.router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		rc.logger.Fatal("invalid trusted proxies", zap.Error(err))
+	}
+	// gin cannot read Forwarded, it falls back to the peer address then
+	rc.router.RemoteIPHeaders = nil
+	if cfg.ForwardedHeader == utils.ForwardedHeaderXFF {
+		rc.router.RemoteIPHeaders = []string{"X-Forwarded-For"}
+	}
 	rc.router.RemoveExtraSlash = true
 	rc.router.RedirectTrailingSlash = true
 
 	rc.router.Use(
+		rc.clientIPMiddleware(),
 		rc.rateLimiterMiddleware(),
-		rc.ipRangeAllowedMiddleware(),
 		rc.ipAllowedMiddleware(),
 	)
 
@@ -94,6 +121,10 @@ func NewRouterController(cfg *config.Config, redis *transport.RedisManager, hand
 	// register routes for furher using
 	rc.registerRoutes()
 
+	if approvalsService := rc.approvalsService(); approvalsService != nil {
+		approvalsService.SetReplayer(rc.replayApproval)
+	}
+
 	fmt.Println(rc.GetRoutes(), "--------->>>")
 
 	return rc
@@ -110,18 +141,28 @@ func (rc *routerController) setupDefaultInterface() {
 func (rc *routerController) setupAdminInterface() {
 	adminGroup := rc.router.Group("/admin")
 
-	rc.attachMiddlewaresToGroup(adminGroup, rc.guardMiddleware(), rc.checkUserLock(), rc.isSuperAdmin())
+	rc.attachMiddlewaresToGroup(adminGroup, rc.guardMiddleware(), rc.audit(), rc.userIPAllowed(), rc.groupRateLimit("admin"), rc.sessionTimeout(), rc.passwordChanged(), rc.checkUserLock())
 
 	// administrator interface
 	rc.adminServiceRoutes(adminGroup)
 	rc.setupAdminRolesRoutes(adminGroup)
 	rc.setupAdminUsersRoutes(adminGroup)
+	rc.setupAdminSessionsRoutes(adminGroup)
 	rc.setupAdminLogsRoutes(adminGroup)
 	rc.setupAdminPolicyRoutes(adminGroup)
+	rc.setupAdminSavedSearchRoutes(adminGroup)
+	rc.setupAdminAllowlistRoutes(adminGroup)
+
+	approvalsGroup := rc.router.Group("/approvals")
+
+	rc.attachMiddlewaresToGroup(approvalsGroup, rc.guardMiddleware(), rc.audit(), rc.userIPAllowed(), rc.groupRateLimit("approvals"), rc.sessionTimeout(), rc.passwordChanged(), rc.checkUserLock())
+
+	// approvals of actions held back by "ask permission" policies
+	rc.setupApprovalsRoutes(approvalsGroup)
 
 	servicesGroup := rc.router.Group("/service")
 
-	rc.attachMiddlewaresToGroup(servicesGroup, rc.guardMiddleware(), rc.checkUserLock(), rc.isDefaultUser())
+	rc.attachMiddlewaresToGroup(servicesGroup, rc.guardMiddleware(), rc.audit(), rc.userIPAllowed(), rc.groupRateLimit("service"), rc.sessionTimeout(), rc.passwordChanged(), rc.checkUserLock())
 
 	// service interface
 	rc.serviceOrdersRoutes(servicesGroup)
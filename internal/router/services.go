@@ -3,6 +3,8 @@ package router
 import (
 	"net/http"
 
+	reportModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -27,12 +29,21 @@ func (rc *routerController) serviceOrdersRoutes(servicesGroup *gin.RouterGroup)
 			Method:      http.MethodGet,
 			Path:        "",
 			HandlerFunc: serviceHandler.GetAll,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceOrders,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceOrders)),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/aggregate",
+			HandlerFunc: serviceHandler.Aggregate,
+			Source:      types.SourceOrders,
+			Middlewares: rc.attachMiddlewaresDirect(rc.savedSearchMiddleware(reportModels.SearchResourceOrders)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/params",
 			HandlerFunc: serviceHandler.GetAll, // TODO: params func
+			Source:      types.SourceOrders,
 		},
 	}
 	rc.registerRoutesToGroup(serviceGroup, routes)
@@ -59,7 +70,8 @@ func (rc *routerController) serviceUsersRoutes(servicesGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "",
 			HandlerFunc: serviceHandler.GetUsers,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceUsers,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceUsers)),
 		},
 		// {
 		// 	Method:      http.MethodGet,
@@ -71,6 +83,7 @@ func (rc *routerController) serviceUsersRoutes(servicesGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "/params",
 			HandlerFunc: serviceHandler.GetSearchUserParameters,
+			Source:      types.SourceUsers,
 		},
 	}
 	rc.registerRoutesToGroup(serviceGroup, routes)
@@ -97,12 +110,14 @@ func (rc *routerController) serviceKYCRoutes(servicesGroup *gin.RouterGroup) {
 			Method:      http.MethodGet,
 			Path:        "",
 			HandlerFunc: serviceHandler.GetKYC,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceKYC,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceKYC)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/params",
 			HandlerFunc: serviceHandler.GetSearchKYCParameters,
+			Source:      types.SourceKYC,
 		},
 	}
 	rc.registerRoutesToGroup(serviceGroup, routes)
@@ -129,24 +144,42 @@ func (rc *routerController) serviceTransactionRoutes(servicesGroup *gin.RouterGr
 			Method:      http.MethodGet,
 			Path:        "/fiat",
 			HandlerFunc: serviceHandler.GetFiatTransactions,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceTransactions,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceFiat)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/crypto",
 			HandlerFunc: serviceHandler.GetCryptoTransactions,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceTransactions,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceCrypto)),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/fiat/aggregate",
+			HandlerFunc: serviceHandler.AggregateFiatTransactions,
+			Source:      types.SourceTransactions,
+			Middlewares: rc.attachMiddlewaresDirect(rc.savedSearchMiddleware(reportModels.SearchResourceFiat)),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/crypto/aggregate",
+			HandlerFunc: serviceHandler.AggregateCryptoTransactions,
+			Source:      types.SourceTransactions,
+			Middlewares: rc.attachMiddlewaresDirect(rc.savedSearchMiddleware(reportModels.SearchResourceCrypto)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/wallets",
 			HandlerFunc: serviceHandler.GetCryptoWallets,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceTransactions,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceWallets)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/params",
 			HandlerFunc: serviceHandler.GetSearchParameters,
+			Source:      types.SourceTransactions,
 		},
 	}
 	rc.registerRoutesToGroup(serviceGroup, routes)
@@ -173,24 +206,28 @@ func (rc *routerController) serviceAssetsRoutes(servicesGroup *gin.RouterGroup)
 			Method:      http.MethodGet,
 			Path:        "/coins",
 			HandlerFunc: serviceHandler.GetCoins,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceAssets,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceCoins)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/assets",
 			HandlerFunc: serviceHandler.GetAssets,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceAssets,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceAssets)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/networks",
 			HandlerFunc: serviceHandler.GetNetworks,
-			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
+			Source:      types.SourceAssets,
+			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware, rc.savedSearchMiddleware(reportModels.SearchResourceNetworks)),
 		},
 		{
 			Method:      http.MethodGet,
 			Path:        "/params",
 			HandlerFunc: serviceHandler.GetSearchParameters,
+			Source:      types.SourceAssets,
 			Middlewares: rc.attachMiddlewaresDirect(rc.paginationMiddleware),
 		},
 	}
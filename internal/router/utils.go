@@ -2,7 +2,10 @@ package router
 
 import (
 	"net/http"
+	"path"
 
+	"github.com/denizumutdereli/stream-admin/internal/middleware"
+	"github.com/denizumutdereli/stream-admin/internal/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -18,10 +21,13 @@ func (rc *routerController) GetRoutes() []RouteInfo {
 func (rc *routerController) registerRoutes() {
 	var routes []RouteInfo
 	for _, route := range rc.router.Routes() {
+		policy := rc.policies[route.Method+" "+route.Path]
 		routes = append(routes, RouteInfo{
 			Method:  route.Method,
 			Path:    route.Path,
 			Handler: route.Handler,
+			Source:  policy.Source,
+			Action:  policy.Action,
 		})
 	}
 	rc.routes = routes
@@ -70,7 +76,25 @@ func (rc *routerController) registerRoutesToGroup(group *gin.RouterGroup, routes
 	}
 
 	for _, route := range routes {
-		handlers := append(route.Middlewares, route.HandlerFunc)
+		var handlers []gin.HandlerFunc
+		if route.Source != "" {
+			handlers = append(handlers, middleware.AuditResource(string(route.Source)))
+			action := route.Action
+			if action == "" {
+				action = types.PolicyActionForMethod(route.Method)
+			}
+			rc.policies[route.Method+" "+joinRoutePath(group.BasePath(), route.Path)] = routePolicy{Source: route.Source, Action: action}
+			handlers = append(handlers, rc.enforcePolicy(route.Source, action))
+		}
+		if route.StepUp {
+			handlers = append(handlers, rc.requireStepUp())
+		}
+		handlers = append(handlers, route.Middlewares...)
+		if route.RateLimit != nil {
+			handlers = append(handlers, rc.rateLimit(route.Method+" "+joinRoutePath(group.BasePath(), route.Path), *route.RateLimit))
+		}
+		handlers = append(handlers, route.HandlerFunc)
+
 		if handlerFunc, ok := methodToHandler[route.Method]; ok {
 			handlerFunc(route.Path, handlers...)
 		} else {
@@ -78,3 +102,17 @@ func (rc *routerController) registerRoutesToGroup(group *gin.RouterGroup, routes
 		}
 	}
 }
+
+// joinRoutePath resolves a route path against its group the way gin does,
+// keeping a trailing slash of the relative path.
+func joinRoutePath(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+
+	finalPath := path.Join(basePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
@@ -0,0 +1,274 @@
+package allowlist
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	allowlistRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/allowlist"
+	usersRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/users"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AdminAllowlistService interface {
+	CreateEntry(ctx context.Context, request *models.AdministratorIPAllowlistRequest, userID string) (*models.AdministratorIPAllowlist, appErrors.Error)
+	UpdateEntry(ctx context.Context, request *models.AdministratorIPAllowlistRequest, userID string) (*models.AdministratorIPAllowlist, appErrors.Error)
+	DeleteEntry(ctx context.Context, entryID string) appErrors.Error
+	GetEntry(entryID string) (*models.AdministratorIPAllowlist, appErrors.Error)
+	GetEntries(paginationParams *types.PaginationParams, queryParams *models.AdministratorIPAllowlistSearch) (*database.PaginatedResult, appErrors.Error)
+
+	IsAllowed(ip string) bool
+	IsAllowedFor(ip, userID, roleID string) bool
+}
+
+type adminAllowlistService struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	repo      allowlistRepo.AdminAllowlistRepository
+	usersRepo usersRepo.AdminUsersRepository
+	nats      *transport.NatsManager
+	config    *config.Config
+	logger    *zap.Logger
+	trie      *ipTrie
+	mutex     sync.RWMutex
+}
+
+func NewAdminAllowlistService(repo *allowlistRepo.AdminAllowlistRepository, usersRepo *usersRepo.AdminUsersRepository, natsManager *transport.NatsManager, config *config.Config) AdminAllowlistService {
+	service := &adminAllowlistService{
+		repo:      *repo,
+		usersRepo: *usersRepo,
+		nats:      natsManager,
+		config:    config,
+		logger:    config.Logger,
+		trie:      newIPTrie(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service.ctx = ctx
+	service.cancel = cancel
+
+	service.reload()
+
+	if _, err := natsManager.Subscribe(config.AllowlistRules.InvalidationSubject, func(*nats.Msg) { service.reload() }); err != nil {
+		service.logger.Error("unable to subscribe to allowlist invalidations", zap.Error(err))
+	}
+
+	go service.reloadPeriodically()
+
+	return service
+}
+
+func (s *adminAllowlistService) CreateEntry(ctx context.Context, request *models.AdministratorIPAllowlistRequest, userID string) (*models.AdministratorIPAllowlist, appErrors.Error) {
+	entry := &models.AdministratorIPAllowlist{CreatedBy: userID}
+	if appErr := s.applyRequest(entry, request); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.repo.Create(entry); err != nil {
+		s.logger.Error("error creating allowlist entry", zap.Error(err))
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error creating allowlist entry", err)
+	}
+
+	s.invalidate()
+
+	return entry, nil
+}
+
+func (s *adminAllowlistService) UpdateEntry(ctx context.Context, request *models.AdministratorIPAllowlistRequest, userID string) (*models.AdministratorIPAllowlist, appErrors.Error) {
+	entry, appErr := s.GetEntry(request.EntryID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	entry.UpdatedBy = userID
+	if appErr := s.applyRequest(entry, request); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.repo.Update(entry); err != nil {
+		s.logger.Error("error updating allowlist entry", zap.Error(err))
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error updating allowlist entry", err)
+	}
+
+	s.invalidate()
+
+	return entry, nil
+}
+
+func (s *adminAllowlistService) DeleteEntry(ctx context.Context, entryID string) appErrors.Error {
+	if _, appErr := s.GetEntry(entryID); appErr != nil {
+		return appErr
+	}
+
+	if err := s.repo.Delete(entryID); err != nil {
+		s.logger.Error("error deleting allowlist entry", zap.Error(err))
+		return appErrors.AppError(http.StatusInternalServerError, "", "error deleting allowlist entry", err)
+	}
+
+	s.invalidate()
+
+	return nil
+}
+
+func (s *adminAllowlistService) GetEntry(entryID string) (*models.AdministratorIPAllowlist, appErrors.Error) {
+	entry, err := s.repo.GetByID(entryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.AppError(http.StatusNotFound, "", "allowlist entry not found", err)
+	} else if err != nil {
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error fetching allowlist entry", err)
+	}
+
+	return entry, nil
+}
+
+func (s *adminAllowlistService) GetEntries(paginationParams *types.PaginationParams, queryParams *models.AdministratorIPAllowlistSearch) (*database.PaginatedResult, appErrors.Error) {
+	data, err := s.repo.GetAll(paginationParams, queryParams)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
+// IsAllowed reports whether the ip is in any range of the allowlist. It is
+// checked before sign in, when the admin is not known yet.
+func (s *adminAllowlistService) IsAllowed(ip string) bool {
+	return s.IsAllowedFor(ip, "", "")
+}
+
+// IsAllowedFor reports whether the ip is in a global range or in one given to
+// the admin or to the admin's role.
+func (s *adminAllowlistService) IsAllowedFor(ip, userID, roleID string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	s.mutex.RLock()
+	trie := s.trie
+	s.mutex.RUnlock()
+
+	return trie.match(parsedIP, userID, roleID, time.Now())
+}
+
+func (s *adminAllowlistService) applyRequest(entry *models.AdministratorIPAllowlist, request *models.AdministratorIPAllowlistRequest) appErrors.Error {
+	cidr, err := models.NormalizeCIDR(request.CIDR)
+	if err != nil {
+		return appErrors.AppError(http.StatusBadRequest, "", "invalid cidr '"+request.CIDR+"'", err)
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return appErrors.AppError(http.StatusBadRequest, "", "expires_at must be in the future", nil)
+	}
+
+	if request.Scope == models.AllowlistScopeAdmin {
+		if _, err := s.usersRepo.FindAdminUserByID(request.ScopeID); err != nil {
+			return appErrors.AppError(http.StatusBadRequest, "", "admin user '"+request.ScopeID+"' not found", err)
+		}
+	}
+
+	entry.CIDR = cidr
+	entry.Scope = request.Scope
+	entry.ScopeID = request.ScopeID
+	entry.Reason = request.Reason
+	entry.ExpiresAt = request.ExpiresAt
+
+	if entry.Scope == models.AllowlistScopeGlobal {
+		entry.ScopeID = ""
+	}
+
+	return nil
+}
+
+// invalidate reloads the local cache and asks the other instances to reload
+// theirs.
+func (s *adminAllowlistService) invalidate() {
+	s.reload()
+
+	if err := s.nats.Publish(s.config.AllowlistRules.InvalidationSubject, []byte("reload")); err != nil {
+		s.logger.Error("unable to publish allowlist invalidation", zap.Error(err))
+	}
+}
+
+// reload rebuilds the trie from the stored entries, the ranges of the config
+// and the vpn addresses of the verified admins. The previous trie is kept
+// when the entries can not be read.
+func (s *adminAllowlistService) reload() {
+	now := time.Now()
+
+	entries, err := s.repo.GetActive(now)
+	if err != nil {
+		s.logger.Error("unable to load the ip allowlist", zap.Error(err))
+		return
+	}
+
+	trie := newIPTrie()
+
+	for _, entry := range entries {
+		s.insert(trie, entry.CIDR, trieEntry{scope: entry.Scope, scopeID: entry.ScopeID, expiresAt: entry.ExpiresAt})
+	}
+
+	for _, cidr := range append(s.config.AllowedIpRanges, s.config.AllowedSpecificIps...) {
+		s.insert(trie, cidr, trieEntry{scope: models.AllowlistScopeGlobal})
+	}
+
+	admins, err := s.usersRepo.GetVerifiedAdminUsers()
+	if err != nil {
+		s.logger.Error("unable to load the admin vpn addresses", zap.Error(err))
+	}
+
+	for _, admin := range admins {
+		if admin.VpnAddr != "" {
+			s.insert(trie, admin.VpnAddr, trieEntry{scope: models.AllowlistScopeAdmin, scopeID: admin.UserID})
+		}
+	}
+
+	s.mutex.Lock()
+	s.trie = trie
+	s.mutex.Unlock()
+
+	s.logger.Debug("ip allowlist reloaded", zap.Int("ranges", trie.size))
+}
+
+func (s *adminAllowlistService) insert(trie *ipTrie, cidr string, entry trieEntry) {
+	normalized, err := models.NormalizeCIDR(cidr)
+	if err != nil {
+		s.logger.Error("skipping invalid allowlist range", zap.String("cidr", cidr), zap.Error(err))
+		return
+	}
+
+	_, network, _ := net.ParseCIDR(normalized)
+	trie.insert(network, entry)
+}
+
+// reloadPeriodically picks up the changes of an instance whose invalidation
+// was missed.
+func (s *adminAllowlistService) reloadPeriodically() {
+	interval := time.Duration(s.config.AllowlistRules.ReloadInSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.reload()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package allowlist
+
+import (
+	"net"
+	"time"
+
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+)
+
+type trieEntry struct {
+	scope     models.AllowlistScope
+	scopeID   string
+	expiresAt *time.Time
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	entries  []trieEntry
+}
+
+// ipTrie is a binary trie of the allowed ranges. IPv4 ranges are kept in
+// their IPv4-in-IPv6 form, so a lookup walks at most 128 bits whatever the
+// size of the allowlist.
+type ipTrie struct {
+	root *trieNode
+	size int
+}
+
+func newIPTrie() *ipTrie {
+	return &ipTrie{root: &trieNode{}}
+}
+
+func (t *ipTrie) insert(network *net.IPNet, entry trieEntry) {
+	ip := network.IP.To16()
+	ones, bits := network.Mask.Size()
+	if bits == net.IPv4len*8 {
+		ones += (net.IPv6len - net.IPv4len) * 8
+	}
+
+	node := t.root
+	for i := 0; i < ones; i++ {
+		bit := ip[i/8] >> (7 - uint(i%8)) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &trieNode{}
+		}
+		node = node.children[bit]
+	}
+
+	node.entries = append(node.entries, entry)
+	t.size++
+}
+
+// match reports whether one of the ranges holding the ip is open to the
+// admin. An empty userID matches a range of any scope, as nobody is signed
+// in yet to tell them apart.
+func (t *ipTrie) match(ip net.IP, userID, roleID string, now time.Time) bool {
+	ip = ip.To16()
+	if ip == nil {
+		return false
+	}
+
+	node := t.root
+	for i := 0; node != nil; i++ {
+		for _, entry := range node.entries {
+			if entry.allows(userID, roleID, now) {
+				return true
+			}
+		}
+
+		if i == net.IPv6len*8 {
+			break
+		}
+		node = node.children[ip[i/8]>>(7-uint(i%8))&1]
+	}
+
+	return false
+}
+
+func (e trieEntry) allows(userID, roleID string, now time.Time) bool {
+	if e.expiresAt != nil && !now.Before(*e.expiresAt) {
+		return false
+	}
+
+	switch e.scope {
+	case models.AllowlistScopeGlobal:
+		return true
+	case models.AllowlistScopeRole:
+		return userID == "" || e.scopeID == roleID
+	case models.AllowlistScopeAdmin:
+		return userID == "" || e.scopeID == userID
+	}
+
+	return false
+}
@@ -0,0 +1,219 @@
+package allowlist
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+)
+
+type testRange struct {
+	cidr  string
+	entry trieEntry
+}
+
+func newTestTrie(t *testing.T, ranges []testRange) *ipTrie {
+	t.Helper()
+
+	trie := newIPTrie()
+	for _, r := range ranges {
+		cidr, err := models.NormalizeCIDR(r.cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trie.insert(network, r.entry)
+	}
+
+	return trie
+}
+
+func TestIPTrieMatch(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	global := trieEntry{scope: models.AllowlistScopeGlobal}
+	admin := func(userID string) trieEntry {
+		return trieEntry{scope: models.AllowlistScopeAdmin, scopeID: userID}
+	}
+	role := func(roleID string) trieEntry {
+		return trieEntry{scope: models.AllowlistScopeRole, scopeID: roleID}
+	}
+
+	tests := []struct {
+		name   string
+		ranges []testRange
+		ip     string
+		userID string
+		roleID string
+		want   bool
+	}{
+		{
+			name:   "ipv4 address in range",
+			ranges: []testRange{{"10.0.0.0/8", global}},
+			ip:     "10.20.30.40",
+			want:   true,
+		},
+		{
+			name:   "ipv4 address out of range",
+			ranges: []testRange{{"10.0.0.0/8", global}},
+			ip:     "11.0.0.1",
+			want:   false,
+		},
+		{
+			name:   "single ipv4 address",
+			ranges: []testRange{{"203.0.113.7", global}},
+			ip:     "203.0.113.7",
+			want:   true,
+		},
+		{
+			name:   "neighbour of a single ipv4 address",
+			ranges: []testRange{{"203.0.113.7", global}},
+			ip:     "203.0.113.6",
+			want:   false,
+		},
+		{
+			name:   "ipv4 mapped ipv6 address matches the ipv4 range",
+			ranges: []testRange{{"192.168.1.0/24", global}},
+			ip:     "::ffff:192.168.1.9",
+			want:   true,
+		},
+		{
+			name:   "ipv4 any range does not hold ipv6 addresses",
+			ranges: []testRange{{"0.0.0.0/0", global}},
+			ip:     "2001:db8::1",
+			want:   false,
+		},
+		{
+			name:   "ipv4 any range holds every ipv4 address",
+			ranges: []testRange{{"0.0.0.0/0", global}},
+			ip:     "198.51.100.1",
+			want:   true,
+		},
+		{
+			name:   "ipv6 address in range",
+			ranges: []testRange{{"2001:db8::/32", global}},
+			ip:     "2001:db8:cafe::17",
+			want:   true,
+		},
+		{
+			name:   "ipv6 address out of range",
+			ranges: []testRange{{"2001:db8::/32", global}},
+			ip:     "2001:db9::1",
+			want:   false,
+		},
+		{
+			name:   "single ipv6 address",
+			ranges: []testRange{{"2001:db8::1", global}},
+			ip:     "2001:db8::1",
+			want:   true,
+		},
+		{
+			name:   "neighbour of a single ipv6 address",
+			ranges: []testRange{{"2001:db8::1", global}},
+			ip:     "2001:db8::2",
+			want:   false,
+		},
+		{
+			name:   "narrower admin range does not hide the wider global one",
+			ranges: []testRange{{"10.0.0.0/8", global}, {"10.1.0.0/16", admin("user-2")}},
+			ip:     "10.1.2.3",
+			userID: "user-1",
+			want:   true,
+		},
+		{
+			name:   "wider admin range does not hide the narrower global one",
+			ranges: []testRange{{"10.0.0.0/8", admin("user-2")}, {"10.1.2.0/24", global}},
+			ip:     "10.1.2.3",
+			userID: "user-1",
+			want:   true,
+		},
+		{
+			name:   "longest prefix of another admin is closed",
+			ranges: []testRange{{"10.0.0.0/8", admin("user-2")}, {"10.1.0.0/16", admin("user-3")}},
+			ip:     "10.1.2.3",
+			userID: "user-1",
+			want:   false,
+		},
+		{
+			name:   "longest prefix of the admin is open",
+			ranges: []testRange{{"10.0.0.0/8", admin("user-2")}, {"10.1.0.0/16", admin("user-1")}},
+			ip:     "10.1.2.3",
+			userID: "user-1",
+			want:   true,
+		},
+		{
+			name:   "ipv6 longest prefix of the role is open",
+			ranges: []testRange{{"2001:db8::/32", role("auditors")}, {"2001:db8:cafe::/48", role("operators")}},
+			ip:     "2001:db8:cafe::17",
+			userID: "user-1",
+			roleID: "operators",
+			want:   true,
+		},
+		{
+			name:   "ipv6 range of another role is closed",
+			ranges: []testRange{{"2001:db8:cafe::/48", role("operators")}},
+			ip:     "2001:db8:cafe::17",
+			userID: "user-1",
+			roleID: "auditors",
+			want:   false,
+		},
+		{
+			name:   "scoped range is open before sign in",
+			ranges: []testRange{{"10.1.0.0/16", admin("user-2")}},
+			ip:     "10.1.2.3",
+			want:   true,
+		},
+		{
+			name:   "expired range is closed",
+			ranges: []testRange{{"10.0.0.0/8", trieEntry{scope: models.AllowlistScopeGlobal, expiresAt: &past}}},
+			ip:     "10.1.2.3",
+			want:   false,
+		},
+		{
+			name: "expired longest prefix falls back to the wider range",
+			ranges: []testRange{
+				{"10.0.0.0/8", trieEntry{scope: models.AllowlistScopeGlobal, expiresAt: &future}},
+				{"10.1.0.0/16", trieEntry{scope: models.AllowlistScopeGlobal, expiresAt: &past}},
+			},
+			ip:   "10.1.2.3",
+			want: true,
+		},
+		{
+			name: "empty allowlist is closed",
+			ip:   "10.1.2.3",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trie := newTestTrie(t, tt.ranges)
+
+			if got := trie.match(net.ParseIP(tt.ip), tt.userID, tt.roleID, now); got != tt.want {
+				t.Errorf("match(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPTrieSize(t *testing.T) {
+	trie := newTestTrie(t, []testRange{
+		{"10.0.0.0/8", trieEntry{scope: models.AllowlistScopeGlobal}},
+		{"10.0.0.0/8", trieEntry{scope: models.AllowlistScopeAdmin, scopeID: "user-1"}},
+		{"2001:db8::/32", trieEntry{scope: models.AllowlistScopeGlobal}},
+	})
+
+	if trie.size != 3 {
+		t.Errorf("size = %d, want 3", trie.size)
+	}
+
+	if trie.match(nil, "", "", time.Now()) {
+		t.Error("an unparsable ip matched")
+	}
+}
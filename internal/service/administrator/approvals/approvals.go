@@ -0,0 +1,322 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/caesar"
+	contextMessage "github.com/denizumutdereli/stream-admin/internal/comm/message"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	approvalsRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/approvals"
+	usersRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/users"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const approvalMessageType = "approval"
+
+// Replayer executes an approved request on behalf of its requester and
+// returns the response it produced.
+type Replayer func(ctx context.Context, approval *models.AdministratorApproval, replay *types.ApprovalReplay) (int, []byte, error)
+
+type AdminApprovalsService interface {
+	RequestApproval(ctx context.Context, approval *models.AdministratorApproval) (*models.AdministratorApproval, appErrors.Error)
+	ApproveRequest(ctx context.Context, approvalID, approverID, approverRoleID string, stepUpVerified bool) (*models.AdministratorApproval, appErrors.Error)
+	RejectRequest(ctx context.Context, approvalID, approverID, approverRoleID, reason string) (*models.AdministratorApproval, appErrors.Error)
+	GetApproval(approvalID string) (*models.AdministratorApproval, appErrors.Error)
+	GetApprovals(paginationParams *types.PaginationParams, queryParams *models.AdministratorApprovalSearch) (*database.PaginatedResult, appErrors.Error)
+	SetReplayer(replayer Replayer)
+}
+
+type adminApprovalsService struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
+	repo            approvalsRepo.AdminApprovalsRepository
+	usersRepo       usersRepo.AdminUsersRepository
+	policyService   policy.AdminPolicyService
+	contextMessages contextMessage.ContextMessages
+	config          *config.Config
+	logger          *zap.Logger
+	caesar          caesar.CaesarManager
+	replayer        Replayer
+	mutex           sync.RWMutex
+}
+
+func NewAdminApprovalsService(repo *approvalsRepo.AdminApprovalsRepository, usersRepo *usersRepo.AdminUsersRepository, policyService policy.AdminPolicyService, contextMessages contextMessage.ContextMessages, caesar caesar.CaesarManager, config *config.Config) AdminApprovalsService {
+	service := &adminApprovalsService{
+		repo:            *repo,
+		usersRepo:       *usersRepo,
+		policyService:   policyService,
+		contextMessages: contextMessages,
+		config:          config,
+		logger:          config.Logger,
+		caesar:          caesar,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service.ctx = ctx
+	service.cancel = cancel
+
+	return service
+}
+
+func (s *adminApprovalsService) SetReplayer(replayer Replayer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.replayer = replayer
+}
+
+// RequestApproval persists a held back request and lets the administrators
+// who may approve it know about it.
+func (s *adminApprovalsService) RequestApproval(ctx context.Context, approval *models.AdministratorApproval) (*models.AdministratorApproval, appErrors.Error) {
+	approval.ExpiresAt = time.Now().Add(time.Duration(s.config.ApprovalRules.ExpiryInMinutes) * time.Minute)
+
+	if err := s.repo.Create(approval); err != nil {
+		s.logger.Error("error creating approval request", zap.Error(err))
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error creating approval request", err)
+	}
+
+	go s.notifyApprovers(*approval)
+
+	return approval, nil
+}
+
+// ApproveRequest records the decision and replays the original request under
+// the requester's identity. The approval ends up failed when the replayed
+// request does not succeed. Step-up routes are only replayed when the approver
+// passed step-up on the approve call.
+func (s *adminApprovalsService) ApproveRequest(ctx context.Context, approvalID, approverID, approverRoleID string, stepUpVerified bool) (*models.AdministratorApproval, appErrors.Error) {
+	approval, appErr := s.decide(ctx, approvalID, approverID, approverRoleID, models.ApprovalStatusApproved, "")
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	status, body, err := s.replay(ctx, approval, stepUpVerified)
+	if err != nil {
+		s.logger.Error("error replaying approved request", zap.String("approval_id", approval.ApprovalID), zap.Error(err))
+		approval.Status = models.ApprovalStatusFailed
+		approval.ResultBody = err.Error()
+	} else {
+		approval.ResultStatus = status
+		approval.ResultBody = s.truncateResult(body)
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			approval.Status = models.ApprovalStatusFailed
+		}
+	}
+
+	if err := s.repo.SaveResult(approval); err != nil {
+		s.logger.Error("error saving approval result", zap.String("approval_id", approval.ApprovalID), zap.Error(err))
+	}
+
+	s.notifyRequester(approval)
+
+	return approval, nil
+}
+
+func (s *adminApprovalsService) RejectRequest(ctx context.Context, approvalID, approverID, approverRoleID, reason string) (*models.AdministratorApproval, appErrors.Error) {
+	approval, appErr := s.decide(ctx, approvalID, approverID, approverRoleID, models.ApprovalStatusRejected, reason)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	s.notifyRequester(approval)
+
+	return approval, nil
+}
+
+func (s *adminApprovalsService) GetApproval(approvalID string) (*models.AdministratorApproval, appErrors.Error) {
+	approval, err := s.repo.GetByID(approvalID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.AppError(http.StatusNotFound, "", "approval not found", err)
+	} else if err != nil {
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error fetching approval", err)
+	}
+
+	if approval.IsExpired(time.Now()) {
+		approval.Status = models.ApprovalStatusExpired
+	}
+
+	return approval, nil
+}
+
+func (s *adminApprovalsService) GetApprovals(paginationParams *types.PaginationParams, queryParams *models.AdministratorApprovalSearch) (*database.PaginatedResult, appErrors.Error) {
+	s.expirePending()
+
+	data, err := s.repo.GetAll(paginationParams, queryParams)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
+func (s *adminApprovalsService) decide(ctx context.Context, approvalID, approverID, approverRoleID string, status models.ApprovalStatus, reason string) (*models.AdministratorApproval, appErrors.Error) {
+	approval, appErr := s.GetApproval(approvalID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if approval.Status == models.ApprovalStatusExpired {
+		s.expirePending()
+		return nil, appErrors.AppError(http.StatusGone, "", "approval request has expired", nil)
+	}
+
+	if approval.Status != models.ApprovalStatusPending {
+		return nil, appErrors.AppError(http.StatusConflict, "", "approval request is already "+string(approval.Status), nil)
+	}
+
+	if approval.RequesterID == approverID {
+		return nil, appErrors.AppError(http.StatusForbidden, "", "you cannot decide on your own approval request", nil)
+	}
+
+	if appErr := s.canApprove(ctx, approverRoleID, approval); appErr != nil {
+		return nil, appErr
+	}
+
+	decidedAt := time.Now()
+	approval.Status = status
+	approval.ApproverID = approverID
+	approval.RejectionReason = reason
+	approval.DecidedAt = &decidedAt
+
+	decided, err := s.repo.Decide(approval)
+	if err != nil {
+		s.logger.Error("error deciding approval", zap.String("approval_id", approvalID), zap.Error(err))
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error deciding approval", err)
+	}
+
+	if !decided {
+		return nil, appErrors.AppError(http.StatusConflict, "", "approval request was decided or has expired in the meantime", nil)
+	}
+
+	return approval, nil
+}
+
+// canApprove requires the approver to be allowed to take the held back action
+// without any further condition.
+func (s *adminApprovalsService) canApprove(ctx context.Context, roleID string, approval *models.AdministratorApproval) appErrors.Error {
+	decision, appErr := s.policyService.ResolvePolicy(ctx, roleID, types.PolicySource(approval.Source), types.PolicyAction(approval.Action))
+	if appErr != nil {
+		return appErr
+	}
+
+	if decision.Allowance != types.AllowanceAllowed {
+		return appErrors.AppError(http.StatusForbidden, "", "your role may not approve "+approval.Action+" on "+approval.Source, nil)
+	}
+
+	return nil
+}
+
+// replay runs the request with the requester's current role, so an approval
+// does not outlive a change of the requester's permissions.
+func (s *adminApprovalsService) replay(ctx context.Context, approval *models.AdministratorApproval, stepUpVerified bool) (int, []byte, error) {
+	s.mutex.RLock()
+	replayer := s.replayer
+	s.mutex.RUnlock()
+
+	if replayer == nil {
+		return 0, nil, errors.New("no replayer is set for approved requests")
+	}
+
+	requester, err := s.usersRepo.FindAdminUserByID(approval.RequesterID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("requester could not be loaded: %w", err)
+	}
+
+	if requester.Status != models.UserStatusVerified {
+		return 0, nil, fmt.Errorf("requester is %s", requester.Status)
+	}
+
+	replay := &types.ApprovalReplay{
+		ApprovalID:     approval.ApprovalID,
+		UserID:         requester.UserID,
+		RoleID:         requester.UserRole,
+		UserAgent:      approval.UserAgent,
+		Source:         types.PolicySource(approval.Source),
+		Action:         types.PolicyAction(approval.Action),
+		StepUpVerified: stepUpVerified,
+	}
+
+	return replayer(ctx, approval, replay)
+}
+
+func (s *adminApprovalsService) notifyApprovers(approval models.AdministratorApproval) {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Duration(s.config.DefaultFuncsTimeOutInSeconds)*time.Second)
+	defer cancel()
+
+	adminUsers, err := s.usersRepo.GetVerifiedAdminUsers()
+	if err != nil {
+		s.logger.Error("error loading approvers", zap.String("approval_id", approval.ApprovalID), zap.Error(err))
+		return
+	}
+
+	message := fmt.Sprintf("Approval %s: %s requests to %s %s", approval.ApprovalID, approval.RequesterID, approval.Action, approval.Source)
+
+	approverRoles := make(map[string]bool)
+	for _, adminUser := range adminUsers {
+		if adminUser.UserID == approval.RequesterID {
+			continue
+		}
+
+		allowed, checked := approverRoles[adminUser.UserRole]
+		if !checked {
+			allowed = s.canApprove(ctx, adminUser.UserRole, &approval) == nil
+			approverRoles[adminUser.UserRole] = allowed
+		}
+
+		if allowed {
+			s.sendMessage(adminUser.UserID, message, approval.ExpiresAt)
+		}
+	}
+}
+
+func (s *adminApprovalsService) notifyRequester(approval *models.AdministratorApproval) {
+	message := fmt.Sprintf("Approval %s: your request to %s %s is %s", approval.ApprovalID, approval.Action, approval.Source, approval.Status)
+	if approval.RejectionReason != "" {
+		message += ": " + approval.RejectionReason
+	}
+
+	s.sendMessage(approval.RequesterID, message, approval.ExpiresAt)
+}
+
+func (s *adminApprovalsService) sendMessage(userID, message string, expiresAt time.Time) {
+	timeout := int(time.Until(expiresAt).Minutes()) + 1
+	if timeout < 1 {
+		timeout = 1
+	}
+
+	err := s.contextMessages.SetContextualMessage(&types.ContextualMessage{
+		UserId:                userID,
+		MessageType:           approvalMessageType,
+		Message:               message,
+		RedisDelivery:         true,
+		NatsDelivery:          true,
+		RedisTimeoutInMinutes: &timeout,
+		IssuedAt:              time.Now().Unix(),
+	})
+	if err != nil {
+		s.logger.Error("error sending approval message", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
+func (s *adminApprovalsService) expirePending() {
+	if _, err := s.repo.ExpirePending(time.Now()); err != nil {
+		s.logger.Error("error expiring approval requests", zap.Error(err))
+	}
+}
+
+func (s *adminApprovalsService) truncateResult(body []byte) string {
+	if max := s.config.ApprovalRules.MaxResultSize; max > 0 && len(body) > max {
+		return string(body[:max])
+	}
+	return string(body)
+}
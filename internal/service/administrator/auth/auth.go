@@ -14,17 +14,30 @@ import (
 	users "github.com/denizumutdereli/stream-admin/internal/repository/administrator/users"
 	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/denizumutdereli/stream-admin/internal/types"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 	"go.uber.org/zap"
 )
 
 type AdminAuthService interface {
-	SignIn(username, password string, debug bool) (bool, appErrors.Error)
-	VerifyOTPAndLogin(userOTP, username, userAgent string) (types.TokenResponse, appErrors.Error)
+	SignIn(username, password string) (models.TwoFactorMethod, appErrors.Error)
+	VerifyOTPAndLogin(userOTP, username, userAgent, ip string) (types.TokenResponse, appErrors.Error)
 	SignOut(accessToken, userAgent string) appErrors.Error
 	RefreshToken(refreshToken, userAgent string) (types.TokenResponse, appErrors.Error)
+	GetSessions(userID, currentSessionID string) ([]types.AdminSession, appErrors.Error)
+	RevokeSession(userID, sessionID string) appErrors.Error
+	RevokeUserSessions(userID string) appErrors.Error
 	InitiateOTP(phone, username string) (string, appErrors.Error)
 	VerifyOTP(phone, username, userOTP string) (bool, appErrors.Error)
+	InitiateStepUp(ctx context.Context, userID, method, path string) (*types.StepUpChallenge, appErrors.Error)
+	VerifyStepUp(ctx context.Context, userID, challengeID, userOTP string) (*types.StepUpToken, appErrors.Error)
+	ConsumeStepUpToken(ctx context.Context, userID, token, method, path string) appErrors.Error
+	GetJWKS() types.JWKS
+	ForgotPassword(ctx context.Context, username string) appErrors.Error
+	ResetPassword(ctx context.Context, resetToken, newPassword string) appErrors.Error
+	ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) (types.TokenResponse, appErrors.Error)
+	EnrollTOTP(ctx context.Context, userID string) (*types.TOTPEnrollment, appErrors.Error)
+	ConfirmTOTP(ctx context.Context, userID, code string) ([]string, appErrors.Error)
+	DisableTOTP(ctx context.Context, userID, code string) appErrors.Error
 }
 
 type adminAuthService struct {
@@ -33,54 +46,72 @@ type adminAuthService struct {
 	config      *config.Config
 	logger      *zap.Logger
 	caesar      caesar.CaesarManager
-	restClient  *transport.Client
+	otpProvider OTPProvider
 	redisClient *transport.RedisManager
 }
 
-func NewAuthService(authRepo *auth.AdminAuthRepository, userRepo *users.AdminUsersRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) AdminAuthService {
+func NewAuthService(authRepo *auth.AdminAuthRepository, userRepo *users.AdminUsersRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) (AdminAuthService, error) {
+	otpProvider, err := NewOTPProvider(config, caesar)
+	if err != nil {
+		return nil, err
+	}
+
 	return &adminAuthService{
 		authRepo:    *authRepo,
 		userRepo:    *userRepo,
 		config:      config,
 		logger:      config.Logger,
 		caesar:      caesar,
-		restClient:  transport.NewRestClient(config.OTPServiceApi, config.Logger),
+		otpProvider: otpProvider,
 		redisClient: redis,
-	}
+	}, nil
 }
 
-func (s *adminAuthService) SignIn(username, password string, debug bool) (bool, appErrors.Error) {
+// SignIn checks the credentials and tells which second factor the login has
+// to be completed with. Sms codes are sent right away.
+func (s *adminAuthService) SignIn(username, password string) (models.TwoFactorMethod, appErrors.Error) {
 	user, err := s.userRepo.FindAdminUserByAdminUsername(username)
 	if err != nil {
-		return false, appErrors.AppError(http.StatusUnauthorized, "", "credentials are not valid", err)
+		return "", appErrors.AppError(http.StatusUnauthorized, "", "credentials are not valid", err)
 	}
 
 	if !s.authRepo.CheckPasswordHash(password, user.Password) {
-		return false, appErrors.AppError(http.StatusUnauthorized, "", "credentials are not valid2", nil)
+		return "", appErrors.AppError(http.StatusUnauthorized, "", "credentials are not valid2", nil)
 	}
 
 	if user.Status != models.UserStatusVerified {
-		return false, appErrors.AppError(http.StatusUnauthorized, "", "you should be verified", nil)
+		return "", appErrors.AppError(http.StatusUnauthorized, "", "you should be verified", nil)
 	}
 
-	if !debug {
-		_, err = s.InitiateOTP(user.PhoneNumber, username)
-		if err != nil {
-			return false, appErrors.AppError(http.StatusServiceUnavailable, "", "otp code could not send", nil)
+	if user.TwoFactorMethod == models.TwoFactorTOTP {
+		if appErr := s.beginTOTPLogin(context.Background(), username); appErr != nil {
+			return "", appErr
 		}
+		return models.TwoFactorTOTP, nil
 	}
 
-	return true, nil
+	_, err = s.InitiateOTP(user.PhoneNumber, username)
+	if err != nil {
+		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "otp code could not send", nil)
+	}
+
+	return models.TwoFactorSMS, nil
 }
 
-func (s *adminAuthService) VerifyOTPAndLogin(userOTP, username, userAgent string) (types.TokenResponse, appErrors.Error) {
+func (s *adminAuthService) VerifyOTPAndLogin(userOTP, username, userAgent, ip string) (types.TokenResponse, appErrors.Error) {
 
 	user, err := s.userRepo.FindAdminUserByAdminUsername(username)
 	if err != nil {
 		return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "credentials are not valid", err)
 	}
 
-	if userOTP != "999900" {
+	switch {
+	case user.TwoFactorMethod == models.TwoFactorTOTP:
+		if appErr := s.completeTOTPLogin(context.Background(), &user, userOTP); appErr != nil {
+			return types.TokenResponse{}, appErr
+		}
+
+	default:
 		_, err = s.VerifyOTP(user.PhoneNumber, username, userOTP)
 		if err != nil {
 			return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "invalid or expired otp", err)
@@ -93,73 +124,68 @@ func (s *adminAuthService) VerifyOTPAndLogin(userOTP, username, userAgent string
 		}
 	}
 
-	newAccessToken, newAccessExpiresIn, newRefreshToken, newRefreshExpireIn, err := s.authRepo.GenerateAccessToken(&user, userAgent)
+	session := &types.AdminSession{UserAgent: userAgent, Ip: ip}
+
+	newAccessToken, newAccessExpiresIn, newRefreshToken, newRefreshExpireIn, err := s.authRepo.GenerateAccessToken(&user, session)
 	if err != nil {
 		s.logger.Error("Error generating access & refresh token")
 		return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "Error generating access & refresh token", err)
 	}
 
 	tokenResponse := types.TokenResponse{
-		AccessToken:           newAccessToken,
-		RefreshToken:          newRefreshToken,
-		AccessTokenExpiresIn:  newAccessExpiresIn,
-		RefreshTokenExpiresIn: newRefreshExpireIn,
+		AccessToken:            newAccessToken,
+		RefreshToken:           newRefreshToken,
+		AccessTokenExpiresIn:   newAccessExpiresIn,
+		RefreshTokenExpiresIn:  newRefreshExpireIn,
+		PasswordChangeRequired: user.MustChangePassword,
 	}
 
 	return tokenResponse, nil
 }
 
 func (s *adminAuthService) SignOut(accessToken, userAgent string) appErrors.Error {
-	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.SecretJWTToken), nil
-	}
-
-	token, err := jwt.ParseWithClaims(accessToken, &types.AccessTokenClaims{}, keyFunc)
+	claims, err := s.authRepo.ParseAccessToken(accessToken)
 	if err != nil {
 		s.logger.Error("Error parsing token at sign out", zap.Error(err))
 		return appErrors.AppError(http.StatusUnauthorized, "", "error parsing token at sign out", err)
 	}
 
-	if claims, ok := token.Claims.(*types.AccessTokenClaims); ok && token.Valid {
+	userID := claims.UserID
+	decodedUserAgent := claims.UserAgent
+	decodedTokenType := claims.TokenType
 
-		userID := claims.UserID
-		decodedUserAgent := claims.UserAgent
-		decodedTokenType := claims.TokenType
-
-		tokenExist := s.authRepo.IsAccessTokenValidAndExist(claims.UserID, accessToken, userAgent)
-		if !tokenExist {
-			return appErrors.AppError(http.StatusUnauthorized, "", "the token is expired or not valid", nil)
-		}
+	tokenExist := s.authRepo.IsAccessTokenValidAndExist(claims, accessToken)
+	if !tokenExist {
+		return appErrors.AppError(http.StatusUnauthorized, "", "the token is expired or not valid", nil)
+	}
 
-		if decodedTokenType != "access_token" {
-			s.logger.Warn("user trying to signout but with invalid token type")
-			return appErrors.AppError(http.StatusUnauthorized, "", "token type is invalid, use access token instead", nil)
-		}
+	if decodedTokenType != "access_token" {
+		s.logger.Warn("user trying to signout but with invalid token type")
+		return appErrors.AppError(http.StatusUnauthorized, "", "token type is invalid, use access token instead", nil)
+	}
 
-		if decodedUserAgent != userAgent {
-			s.logger.Warn("user trying to refresh token but user-agent does not match")
-			return appErrors.AppError(http.StatusUnauthorized, "", "user-agent missmatch", nil)
-		}
+	if decodedUserAgent != userAgent {
+		s.logger.Warn("user trying to refresh token but user-agent does not match")
+		return appErrors.AppError(http.StatusUnauthorized, "", "user-agent missmatch", nil)
+	}
 
-		if err := s.authRepo.RevokeTokens(userID); err != nil {
-			s.logger.Debug("user trying to sign out but failed to revoke", zap.Error(err))
-			return appErrors.AppError(http.StatusServiceUnavailable, "", "failed on revoking tokens", err)
-		}
+	if err := s.authRepo.RevokeSession(userID, claims.SessionID); err != nil {
+		s.logger.Debug("user trying to sign out but failed to revoke", zap.Error(err))
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "failed on revoking tokens", err)
+	}
 
-		return nil
+	return nil
+}
 
-	} else {
-		return appErrors.AppError(http.StatusUnauthorized, "", "invalid access token", nil)
-	}
+// GetJWKS returns the public keys access tokens are verified with.
+func (s *adminAuthService) GetJWKS() types.JWKS {
+	return s.authRepo.JWKS()
 }
 
 func (s *adminAuthService) RefreshToken(refreshToken, userAgent string) (types.TokenResponse, appErrors.Error) {
 
 	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if token.Method != jwt.SigningMethodHS256 {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(s.config.SecretRefreshToken), nil
@@ -176,11 +202,6 @@ func (s *adminAuthService) RefreshToken(refreshToken, userAgent string) (types.T
 		decodedUserAgent := claims.UserAgent
 		decodedTokenType := claims.TokenType
 
-		tokenExist := s.authRepo.IsRefreshTokenValidAndExist(claims.UserID, refreshToken, userAgent)
-		if !tokenExist {
-			return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "refresh token expired or invalid", nil)
-		}
-
 		if decodedTokenType != "refresh_token" {
 			s.logger.Warn("user trying to refresh token but with invalid token type2 ::" + decodedTokenType)
 			return types.TokenResponse{}, appErrors.AppError(http.StatusBadRequest, "", "token type is invalid, use refresh token instead", nil)
@@ -191,9 +212,13 @@ func (s *adminAuthService) RefreshToken(refreshToken, userAgent string) (types.T
 			return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "user-agent missmatch", nil)
 		}
 
-		if err := s.authRepo.RevokeTokens(userID); err != nil {
-			s.logger.Debug("user trying to refresh token but failed to revoke", zap.Error(err))
-			return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "failed on revoking tokens", err)
+		session, err := s.authRepo.UseRefreshToken(claims)
+		if err == auth.ErrRefreshTokenReused {
+			return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "refresh token was already used, the session is revoked", err)
+		} else if err == auth.ErrSessionNotFound {
+			return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "refresh token expired or invalid", err)
+		} else if err != nil {
+			return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "failed on rotating tokens", err)
 		}
 
 		user, err := s.userRepo.FindAdminUserByID(userID)
@@ -201,17 +226,18 @@ func (s *adminAuthService) RefreshToken(refreshToken, userAgent string) (types.T
 			return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "", err)
 		}
 
-		newAccessToken, newAccessExpiresIn, newRefreshToken, newRefreshExpireIn, err := s.authRepo.GenerateAccessToken(&user, userAgent)
+		newAccessToken, newAccessExpiresIn, newRefreshToken, newRefreshExpireIn, err := s.authRepo.GenerateAccessToken(&user, session)
 		if err != nil {
 			s.logger.Error("Error generating access token")
 			return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "error generating access token", err)
 		}
 
 		tokenResponse := types.TokenResponse{
-			AccessToken:           newAccessToken,
-			RefreshToken:          newRefreshToken,
-			AccessTokenExpiresIn:  newAccessExpiresIn,
-			RefreshTokenExpiresIn: newRefreshExpireIn,
+			AccessToken:            newAccessToken,
+			RefreshToken:           newRefreshToken,
+			AccessTokenExpiresIn:   newAccessExpiresIn,
+			RefreshTokenExpiresIn:  newRefreshExpireIn,
+			PasswordChangeRequired: user.MustChangePassword,
 		}
 		return tokenResponse, nil
 
@@ -221,43 +247,48 @@ func (s *adminAuthService) RefreshToken(refreshToken, userAgent string) (types.T
 
 }
 
-func (s *adminAuthService) InitiateOTP(phone, username string) (string, appErrors.Error) {
-	otp, err := s.caesar.GenerateOTP()
+// GetSessions lists the devices the user is signed in from, flagging the one
+// the request comes from.
+func (s *adminAuthService) GetSessions(userID, currentSessionID string) ([]types.AdminSession, appErrors.Error) {
+	sessions, err := s.authRepo.GetSessions(userID)
 	if err != nil {
-		s.logger.Error("Error generating OTP", zap.Error(err))
-		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "error generating otp code", err)
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error retrieving sessions", err)
 	}
 
-	type SMSServiceMessage struct {
-		Recipient string `json:"recipient"`
-		Message   string `json:"message"`
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionID == currentSessionID
 	}
 
-	requestBody := &SMSServiceMessage{
-		Recipient: phone,
-		Message:   fmt.Sprintf("Your %s code is: %s", s.config.AppName, otp),
-	}
+	return sessions, nil
+}
 
-	headers := make(map[string]string)
-	headers["Content-Type"] = "application/json"
-	headers["X-Secret"] = s.config.OTPServiceKey
-	response, err := s.restClient.DoRequest("POST", "", requestBody, headers)
-	if err != nil {
-		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "OTP service call problem", err)
+func (s *adminAuthService) RevokeSession(userID, sessionID string) appErrors.Error {
+	err := s.authRepo.RevokeSession(userID, sessionID)
+	if err == auth.ErrSessionNotFound {
+		return appErrors.AppError(http.StatusNotFound, "", "session not found", err)
+	} else if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "failed on revoking the session", err)
 	}
 
-	if response.StatusCode != http.StatusOK {
-		s.logger.Error("SMS service failed to send the message", zap.Int("status", response.StatusCode), zap.String("response:", string(response.Body)))
-		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "failed to send the OTP code", nil)
+	return nil
+}
+
+// RevokeUserSessions signs another admin out of every device.
+func (s *adminAuthService) RevokeUserSessions(userID string) appErrors.Error {
+	if _, err := s.userRepo.FindAdminUserByID(userID); err != nil {
+		return appErrors.AppError(http.StatusNotFound, "", "admin user not found", err)
 	}
 
-	phone_key := fmt.Sprintf("%s%s%s", phone, username, "_auth_login")
-	err = s.caesar.StoreOTP(phone_key, otp, time.Now().Add(time.Duration(s.config.OTPCodesInMinutes+1)*time.Minute))
-	if err != nil {
-		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "error storing the OTP code", err)
+	if err := s.authRepo.RevokeTokens(userID); err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "failed on revoking tokens", err)
 	}
 
-	return otp, nil
+	return nil
+}
+
+func (s *adminAuthService) InitiateOTP(phone, username string) (string, appErrors.Error) {
+	phone_key := fmt.Sprintf("%s%s%s", phone, username, "_auth_login")
+	return s.sendOTP(phone, phone_key)
 }
 
 func (s *adminAuthService) VerifyOTP(phone, username, userOTP string) (bool, appErrors.Error) {
@@ -274,6 +305,25 @@ func (s *adminAuthService) VerifyOTP(phone, username, userOTP string) (bool, app
 	return true, nil
 }
 
+func (s *adminAuthService) sendOTP(phone, key string) (string, appErrors.Error) {
+	otp, err := s.otpProvider.Generate(key)
+	if err != nil {
+		s.logger.Error("Error generating OTP", zap.Error(err))
+		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "error generating otp code", err)
+	}
+
+	if err := s.otpProvider.Deliver(phone, otp); err != nil {
+		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "failed to send the OTP code", err)
+	}
+
+	err = s.caesar.StoreOTP(key, otp, time.Now().Add(time.Duration(s.config.OTPCodesInMinutes+1)*time.Minute))
+	if err != nil {
+		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "error storing the OTP code", err)
+	}
+
+	return otp, nil
+}
+
 // func (s *adminAuthService) Initiate2FA(userID string) (string, error) {
 // 	// Generate a unique secret key for the user.
 // 	secretKey, err := s.caesar.Generate2FACaesar()
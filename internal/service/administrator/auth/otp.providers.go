@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/denizumutdereli/stream-admin/internal/caesar"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"go.uber.org/zap"
+)
+
+const (
+	OTPProviderSMS  = "sms"
+	OTPProviderTest = "test"
+
+	// TestAuthEnvFlag has to be set to true in the environment, on top of the
+	// config, before the test provider is used.
+	TestAuthEnvFlag = "STREAM_ADMIN_TEST_AUTH"
+
+	productionEnvironment = "production"
+)
+
+// OTPProvider issues the one time codes of logins, step-up challenges and
+// admin verifications and delivers them to the admin.
+type OTPProvider interface {
+	Generate(key string) (string, error)
+	Deliver(phone, otp string) error
+}
+
+// NewOTPProvider builds the provider named by OTP_PROVIDER. The test provider
+// is refused unless every guard of test-mode auth holds.
+func NewOTPProvider(cfg *config.Config, caesar caesar.CaesarManager) (OTPProvider, error) {
+	switch cfg.OTPProvider {
+	case "", OTPProviderSMS:
+		return &smsOTPProvider{
+			caesar:     caesar,
+			restClient: transport.NewRestClient(cfg.OTPServiceApi, cfg.Logger),
+			config:     cfg,
+			logger:     cfg.Logger,
+		}, nil
+
+	case OTPProviderTest:
+		if os.Getenv(TestAuthEnvFlag) != "true" {
+			return nil, fmt.Errorf("otp provider %q needs %s=true in the environment", OTPProviderTest, TestAuthEnvFlag)
+		}
+		if cfg.Https == "true" {
+			return nil, fmt.Errorf("otp provider %q can not be used with https", OTPProviderTest)
+		}
+		if cfg.Environment == productionEnvironment {
+			return nil, fmt.Errorf("otp provider %q can not be used in production", OTPProviderTest)
+		}
+		if cfg.OTPTestSeed == "" {
+			return nil, errors.New("otp provider test needs an OTP_TEST_SEED")
+		}
+
+		logSecurityEvent(cfg, "test-mode auth activated, otp codes are deterministic and not sent",
+			zap.String("otp_provider", OTPProviderTest), zap.String("environment", cfg.Environment))
+
+		return &testOTPProvider{seed: []byte(cfg.OTPTestSeed)}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown otp provider %q", cfg.OTPProvider)
+	}
+}
+
+type smsOTPProvider struct {
+	caesar     caesar.CaesarManager
+	restClient *transport.Client
+	config     *config.Config
+	logger     *zap.Logger
+}
+
+func (p *smsOTPProvider) Generate(key string) (string, error) {
+	return p.caesar.GenerateOTP()
+}
+
+func (p *smsOTPProvider) Deliver(phone, otp string) error {
+	type SMSServiceMessage struct {
+		Recipient string `json:"recipient"`
+		Message   string `json:"message"`
+	}
+
+	requestBody := &SMSServiceMessage{
+		Recipient: phone,
+		Message:   fmt.Sprintf("Your %s code is: %s", p.config.AppName, otp),
+	}
+
+	headers := make(map[string]string)
+	headers["Content-Type"] = "application/json"
+	headers["X-Secret"] = p.config.OTPServiceKey
+	response, err := p.restClient.DoRequest("POST", "", requestBody, headers)
+	if err != nil {
+		return fmt.Errorf("OTP service call problem: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		p.logger.Error("SMS service failed to send the message", zap.Int("status", response.StatusCode), zap.String("response:", string(response.Body)))
+		return errors.New("failed to send the OTP code")
+	}
+
+	return nil
+}
+
+// testOTPProvider derives the code from the key it is stored under instead of
+// sending it, so automated tests can compute the code of any login.
+type testOTPProvider struct {
+	seed []byte
+}
+
+func (p *testOTPProvider) Generate(key string) (string, error) {
+	mac := hmac.New(sha256.New, p.seed)
+	mac.Write([]byte(key))
+	sum := mac.Sum(nil)
+
+	return fmt.Sprintf("%06d", binary.BigEndian.Uint32(sum[:4])%1000000), nil
+}
+
+func (p *testOTPProvider) Deliver(phone, otp string) error {
+	return nil
+}
+
+// logSecurityEvent reports changes that weaken authentication, to syslog as
+// well when it is enabled.
+func logSecurityEvent(cfg *config.Config, message string, fields ...zap.Field) {
+	cfg.Logger.Warn(message, append(fields, zap.String("event_type", "security"))...)
+
+	if cfg.LoggerSys != nil {
+		if err := cfg.LoggerSys.Warning(fmt.Sprintf("CEF:0|%s|%s|1.0|auth|%s|8|", cfg.AppName, cfg.ServiceName, message)); err != nil {
+			cfg.Logger.Error("unable to write the security event to syslog", zap.Error(err))
+		}
+	}
+}
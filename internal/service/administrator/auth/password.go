@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	auth "github.com/denizumutdereli/stream-admin/internal/repository/administrator/auth"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/denizumutdereli/stream-admin/internal/validation"
+	"go.uber.org/zap"
+)
+
+// ForgotPassword mails a reset link to the user. It answers the same whether
+// or not the username exists, so it cannot be used to look up admins.
+func (s *adminAuthService) ForgotPassword(ctx context.Context, username string) appErrors.Error {
+	user, err := s.userRepo.FindAdminUserByAdminUsername(username)
+	if err != nil {
+		s.logger.Info("password reset requested for an unknown user")
+		return nil
+	}
+
+	// failures are only logged, an error would tell that the user exists
+	resetToken, err := s.authRepo.GeneratePasswordResetToken(user.UserID)
+	if err != nil {
+		s.logger.Error("error generating the password reset token", zap.String("user_id", user.UserID), zap.Error(err))
+		return nil
+	}
+
+	if err := s.authRepo.SendPasswordResetEmail(user.Username, resetToken); err != nil {
+		s.logger.Error("error sending the password reset email", zap.String("user_id", user.UserID), zap.Error(err))
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out
+// everywhere, since whoever had the old password may still be signed in.
+func (s *adminAuthService) ResetPassword(ctx context.Context, resetToken, newPassword string) appErrors.Error {
+	if err := validation.ValidatePassword(newPassword, s.config.PasswordRules); err != nil {
+		return appErrors.AppError(http.StatusBadRequest, "", err.Error(), err)
+	}
+
+	userID, err := s.authRepo.ConsumePasswordResetToken(resetToken)
+	if err == auth.ErrResetTokenInvalid {
+		return appErrors.AppError(http.StatusUnauthorized, "", err.Error(), err)
+	} else if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error checking the password reset token", err)
+	}
+
+	if appErr := s.setPassword(userID, newPassword); appErr != nil {
+		return appErr
+	}
+
+	if err := s.authRepo.RevokeTokens(userID); err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "password reset but the sessions could not be revoked", err)
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password of a signed in user. Other sessions
+// are signed out and the current one gets fresh tokens, which no longer
+// carry a pending password change.
+func (s *adminAuthService) ChangePassword(ctx context.Context, userID, sessionID, currentPassword, newPassword string) (types.TokenResponse, appErrors.Error) {
+	user, err := s.userRepo.FindAdminUserByID(userID)
+	if err != nil {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "user not found", err)
+	}
+
+	if !s.authRepo.CheckPasswordHash(currentPassword, user.Password) {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "current password is not valid", nil)
+	}
+
+	if err := validation.ValidatePassword(newPassword, s.config.PasswordRules); err != nil {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusBadRequest, "", err.Error(), err)
+	}
+
+	sessions, err := s.authRepo.GetSessions(userID)
+	if err != nil {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "error retrieving sessions", err)
+	}
+
+	var current *types.AdminSession
+	for i := range sessions {
+		if sessions[i].SessionID == sessionID {
+			current = &sessions[i]
+		}
+	}
+	if current == nil {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusUnauthorized, "", "session not found", nil)
+	}
+
+	if appErr := s.setPassword(userID, newPassword); appErr != nil {
+		return types.TokenResponse{}, appErr
+	}
+
+	if err := s.authRepo.RevokeTokens(userID); err != nil {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "password changed but the sessions could not be revoked", err)
+	}
+
+	user.MustChangePassword = false
+
+	accessToken, accessExpiresIn, refreshToken, refreshExpiresIn, err := s.authRepo.GenerateAccessToken(&user, current)
+	if err != nil {
+		return types.TokenResponse{}, appErrors.AppError(http.StatusServiceUnavailable, "", "password changed, sign in again", err)
+	}
+
+	return types.TokenResponse{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresIn:  accessExpiresIn,
+		RefreshTokenExpiresIn: refreshExpiresIn,
+	}, nil
+}
+
+// setPassword stores a new password unless it is the current one or one of
+// the previous passwords the history keeps.
+func (s *adminAuthService) setPassword(userID, newPassword string) appErrors.Error {
+	user, err := s.userRepo.FindAdminUserByID(userID)
+	if err != nil {
+		return appErrors.AppError(http.StatusUnauthorized, "", "user not found", err)
+	}
+
+	historySize := s.config.PasswordRules.HistorySize
+
+	previous, err := s.userRepo.GetPasswordHistory(userID, historySize-1)
+	if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error checking the password history", err)
+	}
+
+	for _, hash := range append([]string{user.Password}, previous...) {
+		if s.authRepo.CheckPasswordHash(newPassword, hash) {
+			return appErrors.AppError(http.StatusBadRequest, "", "password was used recently, choose another one", nil)
+		}
+	}
+
+	hashedPassword, err := s.authRepo.HashPassword(newPassword)
+	if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error hashing the password", err)
+	}
+
+	if err := s.userRepo.ChangePassword(userID, hashedPassword, historySize); err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error changing the password", err)
+	}
+
+	return nil
+}
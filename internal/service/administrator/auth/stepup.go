@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/go-redis/redis/v8"
+	"github.com/twinj/uuid"
+	"go.uber.org/zap"
+)
+
+// InitiateStepUp challenges a signed in user about to take a sensitive action.
+// Users with an authenticator answer with its code, others get one by sms.
+func (s *adminAuthService) InitiateStepUp(ctx context.Context, userID, method, path string) (*types.StepUpChallenge, appErrors.Error) {
+	user, err := s.userRepo.FindAdminUserByID(userID)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusUnauthorized, "", "user not found", err)
+	}
+
+	challenge := &types.StepUpChallenge{
+		ChallengeID: uuid.NewV4().String(),
+		UserID:      userID,
+		Method:      method,
+		Path:        path,
+		Factor:      string(models.TwoFactorSMS),
+		ExpiresAt:   time.Now().Add(time.Duration(s.config.OTPCodesInMinutes) * time.Minute),
+	}
+
+	if user.TwoFactorMethod == models.TwoFactorTOTP {
+		challenge.Factor = string(models.TwoFactorTOTP)
+	} else if _, appErr := s.sendOTP(user.PhoneNumber, stepUpOTPKey(challenge.ChallengeID)); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := s.redisClient.SetKeyValue(ctx, stepUpChallengeKey(challenge.ChallengeID), challenge, time.Until(challenge.ExpiresAt)); err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error storing the step-up challenge", err)
+	}
+
+	return challenge, nil
+}
+
+// VerifyStepUp exchanges the code of a challenge for a short lived token
+// scoped to the challenged method and path. A challenge is dropped after
+// too many wrong codes, so the action has to be challenged again.
+func (s *adminAuthService) VerifyStepUp(ctx context.Context, userID, challengeID, userOTP string) (*types.StepUpToken, appErrors.Error) {
+	var challenge types.StepUpChallenge
+	err := s.redisClient.GetKeyValue(ctx, stepUpChallengeKey(challengeID), &challenge)
+	if err == redis.Nil {
+		return nil, appErrors.AppError(http.StatusUnauthorized, "", "step-up challenge not found or expired", nil)
+	} else if err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error retrieving the step-up challenge", err)
+	}
+
+	if challenge.UserID != userID {
+		return nil, appErrors.AppError(http.StatusForbidden, "", "step-up challenge belongs to another user", nil)
+	}
+
+	valid, appErr := s.checkStepUpCode(ctx, &challenge, userOTP)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !valid {
+		challenge.Attempts++
+		if challenge.Attempts >= s.config.OTPCodesMaxTry {
+			s.dropStepUpChallenge(ctx, challengeID)
+			return nil, appErrors.AppError(http.StatusTooManyRequests, "", "too many wrong codes, retry the action for a new code", nil)
+		}
+
+		if err := s.redisClient.SetKeyValue(ctx, stepUpChallengeKey(challengeID), challenge, time.Until(challenge.ExpiresAt)); err != nil {
+			s.logger.Error("error updating step-up challenge attempts", zap.String("challenge_id", challengeID), zap.Error(err))
+		}
+		return nil, appErrors.AppError(http.StatusUnauthorized, "", "otp code is incorrect", nil)
+	}
+
+	s.dropStepUpChallenge(ctx, challengeID)
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error generating step-up token", err)
+	}
+
+	token := &types.StepUpToken{
+		Token:     hex.EncodeToString(tokenBytes),
+		Method:    challenge.Method,
+		Path:      challenge.Path,
+		ExpiresIn: int64(s.config.OTPStepUpTokenInSeconds),
+	}
+
+	ttl := time.Duration(s.config.OTPStepUpTokenInSeconds) * time.Second
+	if err := s.redisClient.SetKeyValue(ctx, stepUpTokenKey(userID, token.Token), token, ttl); err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error storing the step-up token", err)
+	}
+
+	return token, nil
+}
+
+// ConsumeStepUpToken lets the request through once when the token was issued
+// to the user for this very method and path.
+func (s *adminAuthService) ConsumeStepUpToken(ctx context.Context, userID, token, method, path string) appErrors.Error {
+	var stepUpToken types.StepUpToken
+	err := s.redisClient.GetKeyValue(ctx, stepUpTokenKey(userID, token), &stepUpToken)
+	if err == redis.Nil {
+		return appErrors.AppError(http.StatusUnauthorized, "", "step-up token is invalid or expired", nil)
+	} else if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error retrieving the step-up token", err)
+	}
+
+	if stepUpToken.Method != method || stepUpToken.Path != path {
+		return appErrors.AppError(http.StatusForbidden, "", "step-up token was issued for another action", nil)
+	}
+
+	// only the request that actually deletes the token may use it
+	deleted, err := s.redisClient.Client.Del(ctx, stepUpTokenKey(userID, token)).Result()
+	if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error consuming the step-up token", err)
+	}
+	if deleted == 0 {
+		return appErrors.AppError(http.StatusUnauthorized, "", "step-up token is invalid or expired", nil)
+	}
+
+	return nil
+}
+
+func (s *adminAuthService) checkStepUpCode(ctx context.Context, challenge *types.StepUpChallenge, userOTP string) (bool, appErrors.Error) {
+	if challenge.Factor == string(models.TwoFactorTOTP) {
+		user, err := s.userRepo.FindAdminUserByID(challenge.UserID)
+		if err != nil {
+			return false, appErrors.AppError(http.StatusUnauthorized, "", "user not found", err)
+		}
+		return s.verifyTOTP(ctx, &user, userOTP)
+	}
+
+	validOTP, err := s.caesar.RetrieveOTP(stepUpOTPKey(challenge.ChallengeID))
+	if err != nil {
+		return false, appErrors.AppError(http.StatusUnauthorized, "", "invalid or expired otp", err)
+	}
+
+	return userOTP == validOTP, nil
+}
+
+func (s *adminAuthService) dropStepUpChallenge(ctx context.Context, challengeID string) {
+	for _, key := range []string{stepUpChallengeKey(challengeID), stepUpOTPKey(challengeID)} {
+		if err := s.redisClient.DeleteKey(ctx, key); err != nil {
+			s.logger.Error("error deleting step-up challenge", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+func stepUpOTPKey(challengeID string) string {
+	return challengeID + "_auth_stepup"
+}
+
+func stepUpChallengeKey(challengeID string) string {
+	return "stepup_challenge:" + challengeID
+}
+
+func stepUpTokenKey(userID, token string) string {
+	return "stepup_token:" + userID + ":" + token
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/caesar"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// EnrollTOTP starts setting up an authenticator app for the user. The secret
+// stays pending until ConfirmTOTP receives a code generated from it.
+func (s *adminAuthService) EnrollTOTP(ctx context.Context, userID string) (*types.TOTPEnrollment, appErrors.Error) {
+	user, err := s.userRepo.FindAdminUserByID(userID)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusUnauthorized, "", "user not found", err)
+	}
+
+	if user.TwoFactorMethod == models.TwoFactorTOTP {
+		return nil, appErrors.AppError(http.StatusConflict, "", "an authenticator is already enrolled, disable it first", nil)
+	}
+
+	secret, err := s.caesar.Generate2FACaesar()
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error generating the authenticator secret", err)
+	}
+
+	uri := s.caesar.TOTPProvisioningURI(s.config.TOTPRules.Issuer, user.Username, secret)
+
+	qrCode, err := caesar.QRCodePNG(uri, s.config.TOTPRules.QRCodeSize)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error generating the qr code", err)
+	}
+
+	enrollment := &types.TOTPEnrollment{
+		Secret:    secret,
+		URI:       uri,
+		QRCode:    qrCode,
+		ExpiresAt: time.Now().Add(time.Duration(s.config.TOTPRules.EnrollmentInMinutes) * time.Minute),
+	}
+
+	if err := s.redisClient.SetKeyValue(ctx, totpEnrollmentKey(userID), secret, time.Until(enrollment.ExpiresAt)); err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error storing the authenticator enrollment", err)
+	}
+
+	return enrollment, nil
+}
+
+// ConfirmTOTP completes the enrollment with a first code from the app and
+// returns the recovery codes, which are not retrievable afterwards.
+func (s *adminAuthService) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, appErrors.Error) {
+	var secret string
+	err := s.redisClient.GetKeyValue(ctx, totpEnrollmentKey(userID), &secret)
+	if err == redis.Nil {
+		return nil, appErrors.AppError(http.StatusNotFound, "", "no pending authenticator enrollment", nil)
+	} else if err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error retrieving the authenticator enrollment", err)
+	}
+
+	counter, ok := s.caesar.ValidateTOTP(secret, code, time.Now(), s.config.TOTPRules.SkewSteps)
+	if !ok {
+		return nil, appErrors.AppError(http.StatusUnauthorized, "", "authenticator code is incorrect", nil)
+	}
+
+	fresh, appErr := s.markTOTPUsed(ctx, userID, counter)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !fresh {
+		return nil, appErrors.AppError(http.StatusUnauthorized, "", "authenticator code was already used", nil)
+	}
+
+	recoveryCodes, err := s.caesar.GenerateRecoveryCodes(s.config.TOTPRules.RecoveryCodes)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error generating recovery codes", err)
+	}
+
+	hashes := make([]string, 0, len(recoveryCodes))
+	for _, recoveryCode := range recoveryCodes {
+		hashes = append(hashes, caesar.HashRecoveryCode(recoveryCode))
+	}
+
+	if err := s.userRepo.EnableTOTP(userID, secret, hashes); err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error enabling the authenticator", err)
+	}
+
+	if err := s.redisClient.DeleteKey(ctx, totpEnrollmentKey(userID)); err != nil {
+		s.logger.Warn("unable to delete the authenticator enrollment")
+	}
+
+	return recoveryCodes, nil
+}
+
+// DisableTOTP moves the user back to sms codes. It takes a current code so a
+// stolen session alone cannot remove the authenticator.
+func (s *adminAuthService) DisableTOTP(ctx context.Context, userID, code string) appErrors.Error {
+	user, err := s.userRepo.FindAdminUserByID(userID)
+	if err != nil {
+		return appErrors.AppError(http.StatusUnauthorized, "", "user not found", err)
+	}
+
+	if user.TwoFactorMethod != models.TwoFactorTOTP {
+		return appErrors.AppError(http.StatusBadRequest, "", "no authenticator is enrolled", nil)
+	}
+
+	valid, appErr := s.verifyTOTP(ctx, &user, code)
+	if appErr != nil {
+		return appErr
+	}
+	if !valid {
+		return appErrors.AppError(http.StatusUnauthorized, "", "authenticator or recovery code is incorrect", nil)
+	}
+
+	if err := s.userRepo.DisableTOTP(userID); err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error disabling the authenticator", err)
+	}
+
+	return nil
+}
+
+// beginTOTPLogin records that the password of the user was checked, since
+// unlike sms codes an authenticator code does not prove it on its own.
+func (s *adminAuthService) beginTOTPLogin(ctx context.Context, username string) appErrors.Error {
+	ttl := time.Duration(s.config.OTPCodesInMinutes) * time.Minute
+	if err := s.redisClient.SetKeyValue(ctx, totpLoginKey(username), 0, ttl); err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error starting the login", err)
+	}
+	return nil
+}
+
+// completeTOTPLogin checks the code of a login started by beginTOTPLogin.
+// The login has to be started over after too many wrong codes.
+func (s *adminAuthService) completeTOTPLogin(ctx context.Context, user *models.AdministratorUser, code string) appErrors.Error {
+	var attempts int
+	err := s.redisClient.GetKeyValue(ctx, totpLoginKey(user.Username), &attempts)
+	if err == redis.Nil {
+		return appErrors.AppError(http.StatusUnauthorized, "", "sign in with your credentials first", nil)
+	} else if err != nil {
+		return appErrors.AppError(http.StatusServiceUnavailable, "", "error retrieving the login", err)
+	}
+
+	valid, appErr := s.verifyTOTP(ctx, user, code)
+	if appErr != nil {
+		return appErr
+	}
+
+	if !valid {
+		attempts++
+		if attempts >= s.config.OTPCodesMaxTry {
+			if err := s.redisClient.DeleteKey(ctx, totpLoginKey(user.Username)); err != nil {
+				s.logger.Error("error deleting the login", zap.String("username", user.Username), zap.Error(err))
+			}
+			return appErrors.AppError(http.StatusTooManyRequests, "", "too many wrong codes, sign in again", nil)
+		}
+
+		if err := s.redisClient.Client.SetXX(ctx, totpLoginKey(user.Username), attempts, redis.KeepTTL).Err(); err != nil {
+			s.logger.Error("error updating login attempts", zap.String("username", user.Username), zap.Error(err))
+		}
+		return appErrors.AppError(http.StatusUnauthorized, "", "invalid or already used authenticator code", nil)
+	}
+
+	if err := s.redisClient.DeleteKey(ctx, totpLoginKey(user.Username)); err != nil {
+		return appErrors.AppError(http.StatusInternalServerError, "", "unable to delete redis key", err)
+	}
+
+	return nil
+}
+
+// verifyTOTP accepts a code of the user's authenticator or one of the unused
+// recovery codes. A code that was already accepted within its time step is
+// refused, so an intercepted code cannot be replayed.
+func (s *adminAuthService) verifyTOTP(ctx context.Context, user *models.AdministratorUser, code string) (bool, appErrors.Error) {
+	secret, err := s.userRepo.OpenTOTPSecret(user)
+	if err != nil {
+		return false, appErrors.AppError(http.StatusServiceUnavailable, "", "error reading the authenticator secret", err)
+	}
+
+	if counter, ok := s.caesar.ValidateTOTP(secret, code, time.Now(), s.config.TOTPRules.SkewSteps); ok {
+		return s.markTOTPUsed(ctx, user.UserID, counter)
+	}
+
+	if len(code) == caesar.TOTPDigits {
+		return false, nil
+	}
+
+	used, err := s.userRepo.UseRecoveryCode(user.UserID, caesar.HashRecoveryCode(code))
+	if err != nil {
+		return false, appErrors.AppError(http.StatusServiceUnavailable, "", "error checking the recovery code", err)
+	}
+
+	return used, nil
+}
+
+func (s *adminAuthService) markTOTPUsed(ctx context.Context, userID string, counter int64) (bool, appErrors.Error) {
+	window := time.Duration(2*s.config.TOTPRules.SkewSteps+1) * caesar.TOTPPeriod * time.Second
+
+	fresh, err := s.redisClient.Client.SetNX(ctx, totpUsedKey(userID, counter), true, window).Result()
+	if err != nil {
+		return false, appErrors.AppError(http.StatusServiceUnavailable, "", "error checking the authenticator code", err)
+	}
+
+	return fresh, nil
+}
+
+func totpEnrollmentKey(userID string) string {
+	return "totp_enrollment:" + userID
+}
+
+func totpLoginKey(username string) string {
+	return "totp_login:" + username
+}
+
+func totpUsedKey(userID string, counter int64) string {
+	return "totp_used:" + userID + ":" + strconv.FormatInt(counter, 10)
+}
@@ -0,0 +1,60 @@
+package logs
+
+import (
+	"encoding/json"
+	"time"
+
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// applyAuditRecord copies what the audit middleware and the handler recorded
+// about the request to the entry.
+func (a *adminLogsService) applyAuditRecord(c *gin.Context, entry *models.AdministratorLogs) {
+	record := utils.AuditRecordOf(c)
+	if record == nil {
+		return
+	}
+
+	entry.ResourceType = record.ResourceType
+	entry.ResourceID = record.ResourceID
+	entry.RequestBody = a.auditBody(c, record.RequestBody)
+	entry.ErrorMessage = record.ErrorMessage
+	entry.LatencyMs = time.Since(record.StartedAt).Milliseconds()
+
+	if record.Before == nil && record.After == nil {
+		return
+	}
+
+	diff, err := json.Marshal(utils.AuditDiff(record.Before, record.After, a.config.AuditRules.RedactFields))
+	if err != nil {
+		a.logger.Error("unable to marshal the audit diff", zap.Error(err))
+		return
+	}
+	entry.Diff = string(diff)
+}
+
+// auditBody redacts the request body before it is stored. Bodies of the
+// routes listed in AUDIT_RULES.skip_body_routes and bodies that are not JSON
+// are left out.
+func (a *adminLogsService) auditBody(c *gin.Context, body string) string {
+	if body == "" {
+		return ""
+	}
+
+	route := c.FullPath()
+	for _, skipped := range a.config.AuditRules.SkipBodyRoutes {
+		if route == skipped {
+			return ""
+		}
+	}
+
+	redacted, ok := utils.RedactJSON([]byte(body), a.config.AuditRules.RedactFields)
+	if !ok {
+		return ""
+	}
+
+	return redacted
+}
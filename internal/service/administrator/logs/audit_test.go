@@ -0,0 +1,67 @@
+package logs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+func TestApplyAuditRecordRequestBody(t *testing.T) {
+	service := &adminLogsService{
+		config: &config.Config{AuditRules: &config.AuditRules{
+			RedactFields:   []string{"password", "token"},
+			SkipBodyRoutes: []string{"/auth/password/change"},
+		}},
+	}
+
+	tests := []struct {
+		name  string
+		route string
+		body  string
+		want  string
+	}{
+		{
+			name:  "password fields are redacted",
+			route: "/admin/users",
+			body:  `{"username":"jane","password":"hunter2","profile":{"reset_token":"abc"}}`,
+			want:  `{"password":"[REDACTED]","profile":{"reset_token":"[REDACTED]"},"username":"jane"}`,
+		},
+		{
+			name:  "skipped route keeps no body",
+			route: "/auth/password/change",
+			body:  `{"current_password":"hunter2","new_password":"hunter3"}`,
+			want:  "",
+		},
+		{
+			name:  "body that is not json is left out",
+			route: "/admin/users",
+			body:  `password=hunter2`,
+			want:  "",
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &models.AdministratorLogs{}
+
+			router := gin.New()
+			router.POST(tt.route, func(c *gin.Context) {
+				c.Set(string(types.ContextAuditRecordKey), &types.AuditRecord{StartedAt: time.Now(), RequestBody: tt.body})
+				service.applyAuditRecord(c, entry)
+			})
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.route, nil))
+
+			if entry.RequestBody != tt.want {
+				t.Errorf("RequestBody = %s, want %s", entry.RequestBody, tt.want)
+			}
+		})
+	}
+}
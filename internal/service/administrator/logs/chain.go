@@ -0,0 +1,330 @@
+package logs
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"go.uber.org/zap"
+)
+
+// chainHead is the last entry this node appended to its chain. It is only
+// touched with the service mutex held.
+type chainHead struct {
+	loaded bool
+	seq    int64
+	hash   string
+}
+
+type signedDigest struct {
+	Digest    json.RawMessage `json:"digest"`
+	PublicKey string          `json:"public_key"`
+	Signature string          `json:"signature"`
+}
+
+func (a *adminLogsService) setupChain() {
+	a.nodeID = a.config.AuditRules.NodeID
+	if a.nodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "admin"
+		}
+		a.nodeID = hostname
+	}
+
+	signingKey, err := parseSigningKey(a.config.AuditRules.DigestSigningKey)
+	if err != nil {
+		a.logger.Error("invalid audit digest signing key, using an ephemeral one", zap.Error(err))
+	}
+
+	if signingKey == nil {
+		_, signingKey, _ = ed25519.GenerateKey(rand.Reader)
+		a.logger.Warn("audit digests are signed with an ephemeral key, set AUDIT_RULES.digest_signing_key to keep them verifiable across restarts",
+			zap.String("publicKey", base64.StdEncoding.EncodeToString(signingKey.Public().(ed25519.PublicKey))))
+	}
+	a.signingKey = signingKey
+
+	go a.anchorPeriodically()
+}
+
+// parseSigningKey accepts a base64 ed25519 seed or private key. An empty key
+// yields nil.
+func parseSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	}
+
+	return nil, fmt.Errorf("signing key is %d bytes, expected a %d byte seed or a %d byte private key", len(raw), ed25519.SeedSize, ed25519.PrivateKeySize)
+}
+
+// appendToChain links the entry to the head of the node chain and stores it.
+// The caller holds the service mutex.
+func (a *adminLogsService) appendToChain(entry *models.AdministratorLogs) error {
+	if err := a.loadChainHead(); err != nil {
+		return err
+	}
+
+	entry.NodeID = a.nodeID
+	entry.ChainSeq = a.head.seq + 1
+	entry.PrevHash = a.head.hash
+	entry.Hash = entry.ChainHash()
+
+	if err := a.repo.Create(entry); err != nil {
+		return err
+	}
+
+	a.head.seq, a.head.hash = entry.ChainSeq, entry.Hash
+	return nil
+}
+
+func (a *adminLogsService) loadChainHead() error {
+	if a.head.loaded {
+		return nil
+	}
+
+	seq, hash, err := a.repo.GetChainHead(a.nodeID)
+	if err != nil {
+		return err
+	}
+
+	a.head = chainHead{loaded: true, seq: seq, hash: hash}
+	return nil
+}
+
+// anchorPeriodically records the head of the chain whenever it moved and
+// exports the digest of the previous day once it is over.
+func (a *adminLogsService) anchorPeriodically() {
+	interval := time.Duration(a.config.AuditRules.AnchorIntervalInMinutes) * time.Minute
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var anchoredSeq int64
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		a.mutex.Lock()
+		err := a.loadChainHead()
+		head := a.head
+		a.mutex.Unlock()
+
+		if err != nil {
+			a.logger.Error("unable to load the audit chain head", zap.Error(err))
+			continue
+		}
+
+		if head.seq > anchoredSeq {
+			anchor := &models.AdministratorLogAnchor{NodeID: a.nodeID, ChainSeq: head.seq, Hash: head.hash}
+			if err := a.repo.CreateAnchor(anchor); err != nil {
+				a.logger.Error("unable to anchor the audit chain", zap.Error(err))
+			} else {
+				anchoredSeq = head.seq
+			}
+		}
+
+		if err := a.exportDigest(time.Now().UTC().AddDate(0, 0, -1)); err != nil {
+			a.logger.Error("unable to export the audit digest", zap.Error(err))
+		}
+	}
+}
+
+func (a *adminLogsService) exportDigest(day time.Time) error {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	dayName := start.Format("2006-01-02")
+
+	exists, err := a.repo.HasDigest(a.nodeID, dayName)
+	if err != nil || exists {
+		return err
+	}
+
+	chainDay, err := a.repo.GetChainDay(a.nodeID, start, start.AddDate(0, 0, 1))
+	if err != nil || chainDay.Entries == 0 {
+		return err
+	}
+
+	digest := &models.AdministratorLogDigest{
+		NodeID:    a.nodeID,
+		Day:       dayName,
+		FirstSeq:  chainDay.FirstSeq,
+		LastSeq:   chainDay.LastSeq,
+		Entries:   chainDay.Entries,
+		HeadHash:  chainDay.HeadHash,
+		PublicKey: base64.StdEncoding.EncodeToString(a.signingKey.Public().(ed25519.PublicKey)),
+	}
+
+	payload := digest.DigestPayload()
+	digest.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(a.signingKey, payload))
+
+	line, err := json.Marshal(signedDigest{Digest: payload, PublicKey: digest.PublicKey, Signature: digest.Signature})
+	if err != nil {
+		return err
+	}
+
+	// the stored row decides, so a day is never appended to the file twice
+	created, err := a.repo.CreateDigest(digest)
+	if err != nil || !created {
+		return err
+	}
+
+	file, err := os.OpenFile(a.config.AuditRules.DigestFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// VerifyChain walks the chains of the given node, or of every node, and
+// reports the first broken link of each. fromSeq of zero starts at the oldest
+// entry still stored, archived ones are gone, and toSeq of zero verifies to
+// the head.
+func (a *adminLogsService) VerifyChain(nodeID string, fromSeq, toSeq int64) ([]*models.AdministratorLogChainReport, appErrors.Error) {
+	if fromSeq < 0 {
+		fromSeq = 0
+	}
+
+	if toSeq > 0 && toSeq < fromSeq {
+		err := errors.New("to_seq must not be lower than from_seq")
+		return nil, appErrors.AppError(http.StatusBadRequest, "", err.Error(), err)
+	}
+
+	nodes := []string{nodeID}
+	if nodeID == "" {
+		var err error
+		if nodes, err = a.repo.GetChainNodes(); err != nil {
+			return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		}
+	}
+
+	reports := make([]*models.AdministratorLogChainReport, 0, len(nodes))
+	for _, node := range nodes {
+		report, err := a.verifyNodeChain(node, fromSeq, toSeq)
+		if err != nil {
+			return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (a *adminLogsService) verifyNodeChain(nodeID string, fromSeq, toSeq int64) (*models.AdministratorLogChainReport, error) {
+	report := &models.AdministratorLogChainReport{NodeID: nodeID, FromSeq: fromSeq, Valid: true}
+
+	broken := func(id, seq int64, reason string) {
+		report.Valid = false
+		report.BrokenAt = &models.AdministratorLogChainBreak{ID: id, ChainSeq: seq, Reason: reason}
+	}
+
+	prevHash := ""
+	switch {
+	case fromSeq == 0:
+		// the oldest stored entry is trusted to link to the archived ones
+		err := a.repo.WalkChain(nodeID, 1, toSeq, 1, func(entry *models.AdministratorLogs) bool {
+			fromSeq, prevHash = entry.ChainSeq, entry.PrevHash
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+		if fromSeq == 0 {
+			fromSeq = 1
+		}
+		report.FromSeq = fromSeq
+	case fromSeq > 1:
+		// a range starting mid chain links to the entry before it
+		found := false
+		err := a.repo.WalkChain(nodeID, fromSeq-1, fromSeq-1, 1, func(entry *models.AdministratorLogs) bool {
+			prevHash, found = entry.Hash, true
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			broken(0, fromSeq-1, "entry is missing from the chain")
+			return report, nil
+		}
+	}
+
+	anchors, err := a.repo.GetAnchors(nodeID, fromSeq, toSeq)
+	if err != nil {
+		return nil, err
+	}
+	report.Anchors = len(anchors)
+
+	anchored := make(map[int64]string, len(anchors))
+	for _, anchor := range anchors {
+		anchored[anchor.ChainSeq] = anchor.Hash
+	}
+
+	expectedSeq := fromSeq
+	err = a.repo.WalkChain(nodeID, fromSeq, toSeq, a.config.AuditRules.VerifyBatchSize, func(entry *models.AdministratorLogs) bool {
+		switch {
+		case entry.ChainSeq != expectedSeq:
+			broken(entry.ID, expectedSeq, "entry is missing from the chain")
+		case entry.PrevHash != prevHash:
+			broken(entry.ID, entry.ChainSeq, "previous hash does not match the previous entry")
+		case entry.ChainHash() != entry.Hash:
+			broken(entry.ID, entry.ChainSeq, "entry content does not match its hash")
+		default:
+			if hash, ok := anchored[entry.ChainSeq]; ok && hash != entry.Hash {
+				broken(entry.ID, entry.ChainSeq, "entry does not match its anchor")
+			}
+		}
+
+		if !report.Valid {
+			return false
+		}
+
+		prevHash = entry.Hash
+		expectedSeq++
+		report.Checked++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.ToSeq = expectedSeq - 1
+
+	if report.Valid {
+		for _, anchor := range anchors {
+			if anchor.ChainSeq >= expectedSeq {
+				broken(0, expectedSeq, fmt.Sprintf("chain is truncated before anchored entry %d", anchor.ChainSeq))
+				break
+			}
+		}
+	}
+
+	return report, nil
+}
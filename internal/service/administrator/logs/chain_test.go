@@ -0,0 +1,240 @@
+package logs
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/repository/administrator/logs"
+)
+
+const testNodeID = "node-1"
+
+// chainRepository keeps a chain in memory, the rest of the repository is not
+// used by the verification.
+type chainRepository struct {
+	logs.AdminLogsRepository
+	entries []*models.AdministratorLogs
+	anchors []*models.AdministratorLogAnchor
+}
+
+func (r *chainRepository) WalkChain(nodeID string, fromSeq, toSeq int64, batchSize int, handle func(entry *models.AdministratorLogs) bool) error {
+	for _, entry := range r.entries {
+		if entry.NodeID != nodeID || entry.ChainSeq < fromSeq || (toSeq > 0 && entry.ChainSeq > toSeq) {
+			continue
+		}
+		if !handle(entry) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *chainRepository) GetAnchors(nodeID string, fromSeq, toSeq int64) ([]*models.AdministratorLogAnchor, error) {
+	var anchors []*models.AdministratorLogAnchor
+	for _, anchor := range r.anchors {
+		if anchor.NodeID == nodeID && anchor.ChainSeq >= fromSeq && (toSeq == 0 || anchor.ChainSeq <= toSeq) {
+			anchors = append(anchors, anchor)
+		}
+	}
+	return anchors, nil
+}
+
+// digestRepository stores digests once per day. HasDigest never sees them,
+// as for two exports racing each other.
+type digestRepository struct {
+	logs.AdminLogsRepository
+	stored map[string]bool
+}
+
+func (r *digestRepository) HasDigest(nodeID, day string) (bool, error) {
+	return false, nil
+}
+
+func (r *digestRepository) GetChainDay(nodeID string, start, end time.Time) (*logs.ChainDay, error) {
+	return &logs.ChainDay{FirstSeq: 1, LastSeq: 3, Entries: 3, HeadHash: "head"}, nil
+}
+
+func (r *digestRepository) CreateDigest(digest *models.AdministratorLogDigest) (bool, error) {
+	if r.stored[digest.Day] {
+		return false, nil
+	}
+	r.stored[digest.Day] = true
+	return true, nil
+}
+
+// buildChain links n entries the way appendToChain does.
+func buildChain(n int) []*models.AdministratorLogs {
+	entries := make([]*models.AdministratorLogs, 0, n)
+	prevHash := ""
+	for seq := int64(1); seq <= int64(n); seq++ {
+		entry := &models.AdministratorLogs{
+			ID:         seq,
+			UserID:     "user-1",
+			Action:     "/admin/users",
+			Method:     "GET",
+			Status:     200,
+			NodeID:     testNodeID,
+			ChainSeq:   seq,
+			PrevHash:   prevHash,
+			Timestamps: time.Unix(1700000000+seq, 0).UTC(),
+		}
+		entry.Hash = entry.ChainHash()
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func anchorAt(entries []*models.AdministratorLogs, seq int64) *models.AdministratorLogAnchor {
+	return &models.AdministratorLogAnchor{NodeID: testNodeID, ChainSeq: seq, Hash: entries[seq-1].Hash}
+}
+
+func TestVerifyNodeChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(repo *chainRepository)
+		fromSeq int64
+		toSeq   int64
+		valid   bool
+		brokeAt int64
+		reason  string
+		checked int64
+	}{
+		{
+			name:    "intact chain",
+			tamper:  func(repo *chainRepository) {},
+			valid:   true,
+			checked: 5,
+		},
+		{
+			name:    "range from the middle links to the entry before",
+			tamper:  func(repo *chainRepository) {},
+			fromSeq: 3,
+			toSeq:   4,
+			valid:   true,
+			checked: 2,
+		},
+		{
+			name: "changed row",
+			tamper: func(repo *chainRepository) {
+				repo.entries[2].Status = 403
+			},
+			brokeAt: 3,
+			reason:  "entry content does not match its hash",
+		},
+		{
+			name: "changed row with its hash recomputed",
+			tamper: func(repo *chainRepository) {
+				repo.entries[2].Status = 403
+				repo.entries[2].Hash = repo.entries[2].ChainHash()
+			},
+			brokeAt: 4,
+			reason:  "previous hash does not match the previous entry",
+		},
+		{
+			name: "rewritten tail against an anchor",
+			tamper: func(repo *chainRepository) {
+				repo.anchors = []*models.AdministratorLogAnchor{anchorAt(repo.entries, 4)}
+				repo.entries[2].Status = 403
+				for i := 2; i < len(repo.entries); i++ {
+					repo.entries[i].PrevHash = repo.entries[i-1].Hash
+					repo.entries[i].Hash = repo.entries[i].ChainHash()
+				}
+			},
+			brokeAt: 4,
+			reason:  "entry does not match its anchor",
+		},
+		{
+			name: "deleted row",
+			tamper: func(repo *chainRepository) {
+				repo.entries = append(repo.entries[:2], repo.entries[3:]...)
+			},
+			brokeAt: 3,
+			reason:  "entry is missing from the chain",
+		},
+		{
+			name: "truncated tail",
+			tamper: func(repo *chainRepository) {
+				repo.anchors = []*models.AdministratorLogAnchor{anchorAt(repo.entries, 5)}
+				repo.entries = repo.entries[:3]
+			},
+			brokeAt: 4,
+			reason:  "chain is truncated before anchored entry 5",
+		},
+		{
+			name: "missing entry before the range",
+			tamper: func(repo *chainRepository) {
+				repo.entries = append(repo.entries[:1], repo.entries[2:]...)
+			},
+			fromSeq: 3,
+			brokeAt: 2,
+			reason:  "entry is missing from the chain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &chainRepository{entries: buildChain(5)}
+			tt.tamper(repo)
+
+			service := &adminLogsService{
+				config: &config.Config{AuditRules: &config.AuditRules{VerifyBatchSize: 2}},
+				repo:   repo,
+			}
+
+			report, err := service.verifyNodeChain(testNodeID, tt.fromSeq, tt.toSeq)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Valid != tt.valid {
+				t.Fatalf("Valid = %v, want %v (%+v)", report.Valid, tt.valid, report.BrokenAt)
+			}
+
+			if tt.valid {
+				if report.Checked != tt.checked {
+					t.Errorf("Checked = %d, want %d", report.Checked, tt.checked)
+				}
+				return
+			}
+
+			if report.BrokenAt.ChainSeq != tt.brokeAt || !strings.Contains(report.BrokenAt.Reason, tt.reason) {
+				t.Errorf("BrokenAt = %d %q, want %d %q", report.BrokenAt.ChainSeq, report.BrokenAt.Reason, tt.brokeAt, tt.reason)
+			}
+		})
+	}
+}
+
+func TestExportDigestAppendsOnlyStoredDigests(t *testing.T) {
+	digestFile := filepath.Join(t.TempDir(), "digests.jsonl")
+
+	service := &adminLogsService{
+		config:     &config.Config{AuditRules: &config.AuditRules{DigestFile: digestFile}},
+		repo:       &digestRepository{stored: make(map[string]bool)},
+		nodeID:     testNodeID,
+		signingKey: ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)),
+	}
+
+	day := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := service.exportDigest(day); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(digestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := bytes.Count(content, []byte("\n")); lines != 1 {
+		t.Errorf("digest file has %d lines, want 1", lines)
+	}
+}
@@ -2,11 +2,13 @@ package logs
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
@@ -26,9 +28,12 @@ import (
 
 type AdminLogsService interface {
 	GetAll(paginationParams *types.PaginationParams, queryParams *models.AdministratorLogsSearch) (*database.PaginatedResult, appErrors.Error)
+	Export(exportParams *types.ExportParams, queryParams *models.AdministratorLogsSearch, handle database.RowHandler) (int, appErrors.Error)
 	LogAction(c *gin.Context, userRole, UserID string, loglevel ...int) appErrors.Error
 	BuildTopicName(loglevel ...int) string
 	SendToNats(natsSubject string, logMessage []byte) appErrors.Error
+	VerifyChain(nodeID string, fromSeq, toSeq int64) ([]*models.AdministratorLogChainReport, appErrors.Error)
+	SetIsLeader(ctx context.Context, isLeader bool)
 }
 
 type adminLogsService struct {
@@ -43,6 +48,12 @@ type adminLogsService struct {
 	workerPool       *workers.WorkerPool
 	mutex            sync.RWMutex
 	connectionStatus bool
+	nodeID           string
+	head             chainHead
+	signingKey       ed25519.PrivateKey
+	retention        *LogRetention
+	isLeader         atomic.Bool
+	leadership       chan struct{}
 }
 
 func NewAdminLogsService(appContext *types.ExchangeConfig, repo *logs.AdminLogsRepository) AdminLogsService {
@@ -57,21 +68,37 @@ func NewAdminLogsService(appContext *types.ExchangeConfig, repo *logs.AdminLogsR
 		connectionStatus: true,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(service.config.DefaultFuncsTimeOutInSeconds)*time.Second)
+	// the background loops of the service run until it is canceled
+	ctx, cancel := context.WithCancel(context.Background())
 	service.ctx = ctx
 	service.cancel = cancel
 
+	service.setupChain()
+
+	service.retention = NewLogRetention(service.repo, service.config)
+	service.leadership = make(chan struct{}, 1)
+	go service.maintainPeriodically()
+
 	return service
 }
 
 func (s *adminLogsService) GetAll(paginationParams *types.PaginationParams, queryParams *models.AdministratorLogsSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetAll(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusBadRequest, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusBadRequest), "", err.Error(), err)
 	}
 	return data, nil
 }
 
+func (s *adminLogsService) Export(exportParams *types.ExportParams, queryParams *models.AdministratorLogsSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.Export(exportParams, queryParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
 func (a *adminLogsService) LogAction(c *gin.Context, userRole, UserID string, loglevel ...int) appErrors.Error {
 
 	actualLogLevel := 1
@@ -92,9 +119,16 @@ func (a *adminLogsService) LogAction(c *gin.Context, userRole, UserID string, lo
 		Ip:         utils.GetClientIP(c),
 		Status:     c.Writer.Status(),
 		UserAgent:  c.Request.UserAgent(),
-		Timestamps: time.Now(),
+		Route:      c.FullPath(),
+		Timestamps: time.Now().UTC().Truncate(time.Microsecond),
 	}
 
+	if replay, ok := types.ApprovalReplayFrom(c.Request.Context()); ok {
+		actionData.ApprovalID = replay.ApprovalID
+	}
+
+	a.applyAuditRecord(c, actionData)
+
 	actionJSON, err := json.Marshal(actionData)
 	if err != nil {
 		a.logger.Error("Failed to marshal action to JSON", zap.Error(err))
@@ -123,7 +157,7 @@ func (a *adminLogsService) LogAction(c *gin.Context, userRole, UserID string, lo
 		default:
 			a.mutex.Lock()
 			defer a.mutex.Unlock()
-			return a.repo.Create(actionData)
+			return a.appendToChain(actionData)
 		}
 	})
 
@@ -0,0 +1,319 @@
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	logs "github.com/denizumutdereli/stream-admin/internal/repository/administrator/logs"
+	"go.uber.org/zap"
+)
+
+// LogRetention keeps the logs table partitioned by month and moves the
+// partitions past LOG_RETENTION_RULES.retention_in_months to compressed
+// NDJSON archives, each with a manifest next to it.
+type LogRetention struct {
+	repo   logs.AdminLogsRepository
+	config *config.Config
+	logger *zap.Logger
+}
+
+func NewLogRetention(repo logs.AdminLogsRepository, config *config.Config) *LogRetention {
+	return &LogRetention{repo: repo, config: config, logger: config.Logger}
+}
+
+// Maintain partitions the table when it is not yet, makes the partitions of
+// the coming months and archives the expired ones. Only one instance should
+// run it at a time.
+func (r *LogRetention) Maintain(now time.Time) error {
+	rules := r.config.LogRetentionRules
+
+	if err := r.repo.EnsurePartitioned(); err != nil {
+		return fmt.Errorf("partitioning %s: %w", r.repo.LogsTable(), err)
+	}
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= rules.PremakeMonths; i++ {
+		if err := r.repo.EnsurePartition(month.AddDate(0, i, 0)); err != nil {
+			return fmt.Errorf("creating the partition of %s: %w", month.AddDate(0, i, 0).Format("2006-01"), err)
+		}
+	}
+
+	if rules.RetentionInMonths <= 0 {
+		return nil
+	}
+
+	partitions, err := r.repo.GetPartitions()
+	if err != nil {
+		return err
+	}
+
+	cutoff := month.AddDate(0, -rules.RetentionInMonths, 0)
+	for _, partition := range partitions {
+		if partition.Default || partition.Restored() || partition.To.After(cutoff) {
+			continue
+		}
+
+		manifest, err := r.archive(partition)
+		if err != nil {
+			return fmt.Errorf("archiving %s: %w", partition.Name, err)
+		}
+
+		if err := r.repo.DropPartition(partition.Name, manifest.Rows); err != nil {
+			return fmt.Errorf("dropping %s: %w", partition.Name, err)
+		}
+
+		r.logger.Info("admin logs partition archived", zap.String("partition", partition.Name), zap.Int64("rows", manifest.Rows), zap.String("file", manifest.File))
+	}
+
+	return nil
+}
+
+// SetIsLeader lets the instance holding the leadership maintain the logs
+// table, maintenance starts right away once it is gained.
+func (a *adminLogsService) SetIsLeader(ctx context.Context, isLeader bool) {
+	if a.isLeader.Swap(isLeader) == isLeader || !isLeader {
+		return
+	}
+
+	select {
+	case a.leadership <- struct{}{}:
+	default:
+	}
+}
+
+func (a *adminLogsService) maintainPeriodically() {
+	interval := time.Duration(a.config.LogRetentionRules.CheckIntervalInMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-a.leadership:
+		}
+
+		if !a.isLeader.Load() {
+			continue
+		}
+
+		if err := a.retention.Maintain(time.Now().UTC()); err != nil {
+			a.logger.Error("admin logs maintenance failed", zap.Error(err))
+		}
+	}
+}
+
+func (r *LogRetention) archive(partition *logs.LogPartition) (*models.AdministratorLogArchiveManifest, error) {
+	dir := r.config.LogRetentionRules.ArchiveDir
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	manifest := &models.AdministratorLogArchiveManifest{
+		Table:     r.repo.LogsTable(),
+		Partition: partition.Name,
+		From:      partition.From,
+		To:        partition.To,
+		File:      partition.Name + ".ndjson.gz",
+	}
+
+	file, err := os.CreateTemp(dir, partition.Name+"-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	writer := gzip.NewWriter(io.MultiWriter(file, hash))
+	encoder := json.NewEncoder(writer)
+	chains := make(map[string]*models.AdministratorLogArchiveChain)
+
+	manifest.Rows, err = r.repo.WalkPartition(partition.Name, r.config.LogRetentionRules.BatchSize, func(entry *models.AdministratorLogs) error {
+		if manifest.FirstID == 0 {
+			manifest.FirstID = entry.ID
+		}
+		manifest.LastID = entry.ID
+		trackChain(chains, entry)
+
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(dir, manifest.File)); err != nil {
+		return nil, err
+	}
+
+	manifest.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	manifest.ArchivedAt = time.Now().UTC()
+	manifest.Chains = make([]*models.AdministratorLogArchiveChain, 0, len(chains))
+	for _, chain := range chains {
+		manifest.Chains = append(manifest.Chains, chain)
+	}
+
+	return manifest, writeManifest(filepath.Join(dir, partition.Name+".manifest.json"), manifest)
+}
+
+func trackChain(chains map[string]*models.AdministratorLogArchiveChain, entry *models.AdministratorLogs) {
+	if entry.NodeID == "" {
+		return
+	}
+
+	chain, ok := chains[entry.NodeID]
+	if !ok {
+		chain = &models.AdministratorLogArchiveChain{NodeID: entry.NodeID, FirstSeq: entry.ChainSeq, FirstPrevHash: entry.PrevHash}
+		chains[entry.NodeID] = chain
+	}
+
+	if entry.ChainSeq < chain.FirstSeq {
+		chain.FirstSeq, chain.FirstPrevHash = entry.ChainSeq, entry.PrevHash
+	}
+	if entry.ChainSeq >= chain.LastSeq {
+		chain.LastSeq, chain.LastHash = entry.ChainSeq, entry.Hash
+	}
+}
+
+func writeManifest(path string, manifest *models.AdministratorLogArchiveManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0o640); err != nil {
+		return err
+	}
+
+	return os.Rename(temporary, path)
+}
+
+// Restore loads the archive of the manifest into a new partition and attaches
+// it to the logs table under the original name with the restored suffix.
+// Retention leaves it alone, it is dropped by hand once no longer needed.
+func (r *LogRetention) Restore(manifestPath string) (string, *models.AdministratorLogArchiveManifest, error) {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var manifest models.AdministratorLogArchiveManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return "", nil, fmt.Errorf("reading the manifest: %w", err)
+	}
+
+	archivePath := filepath.Join(filepath.Dir(manifestPath), manifest.File)
+	if err := checkArchive(archivePath, manifest.SHA256); err != nil {
+		return "", nil, err
+	}
+
+	name := manifest.Partition + logs.RestoredPartitionSuffix
+	if err := r.repo.CreateDetachedPartition(name); err != nil {
+		return "", nil, err
+	}
+
+	rows, err := r.load(name, archivePath)
+	if err != nil {
+		return name, nil, fmt.Errorf("loading %s, drop it before trying again: %w", name, err)
+	}
+	if rows != manifest.Rows {
+		return name, nil, fmt.Errorf("loaded %d rows into %s instead of %d, drop it before trying again", rows, name, manifest.Rows)
+	}
+
+	if err := r.repo.AttachPartition(name, manifest.From, manifest.To); err != nil {
+		return name, nil, err
+	}
+
+	return name, &manifest, nil
+}
+
+func (r *LogRetention) load(name, archivePath string) (int64, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	batchSize := r.config.LogRetentionRules.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	var rows int64
+	batch := make([]*models.AdministratorLogs, 0, batchSize)
+	decoder := json.NewDecoder(reader)
+	for {
+		var entry models.AdministratorLogs
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return rows, err
+		}
+
+		batch = append(batch, &entry)
+		if len(batch) == batchSize {
+			if err := r.repo.InsertIntoPartition(name, batch); err != nil {
+				return rows, err
+			}
+			rows += int64(len(batch))
+			batch = batch[:0]
+		}
+	}
+
+	if err := r.repo.InsertIntoPartition(name, batch); err != nil {
+		return rows, err
+	}
+
+	return rows + int64(len(batch)), nil
+}
+
+func checkArchive(path, expected string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != expected {
+		return fmt.Errorf("archive %s does not match its manifest, sha256 is %s instead of %s", path, sum, expected)
+	}
+
+	return nil
+}
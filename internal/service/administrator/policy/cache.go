@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	rulesCacheKeyPrefix  = "policy_rules"
+	rulesCacheVersionKey = "policy_rules_version"
+)
+
+// RulesCache keeps the resolved rules of each role in redis. Entries are
+// keyed by a generation number, so a single bump invalidates every role at
+// once without scanning for keys. Readers take the generation before loading
+// the rules and store them under it, so rules loaded while an invalidation
+// runs land in the generation that is already stale.
+type RulesCache interface {
+	Generation(ctx context.Context) (int64, error)
+	Get(ctx context.Context, generation int64, roleID string) (*types.RoleRules, bool)
+	Set(ctx context.Context, generation int64, rules *types.RoleRules) error
+	Invalidate(ctx context.Context) error
+}
+
+type rulesCache struct {
+	redis *transport.RedisManager
+	ttl   time.Duration
+}
+
+func NewRulesCache(redis *transport.RedisManager, config *config.Config) RulesCache {
+	return &rulesCache{
+		redis: redis,
+		ttl:   time.Duration(config.PolicyRules.RulesCacheTTL) * time.Second,
+	}
+}
+
+func (r *rulesCache) Generation(ctx context.Context) (int64, error) {
+	generation, err := r.redis.Client.Get(ctx, rulesCacheVersionKey).Int64()
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+
+	return generation, nil
+}
+
+func (r *rulesCache) Get(ctx context.Context, generation int64, roleID string) (*types.RoleRules, bool) {
+	var rules types.RoleRules
+	if err := r.redis.GetKeyValue(ctx, rulesCacheKey(generation, roleID), &rules); err != nil {
+		return nil, false
+	}
+
+	return &rules, true
+}
+
+func (r *rulesCache) Set(ctx context.Context, generation int64, rules *types.RoleRules) error {
+	return r.redis.SetKeyValue(ctx, rulesCacheKey(generation, rules.RoleID), rules, r.ttl)
+}
+
+func (r *rulesCache) Invalidate(ctx context.Context) error {
+	return r.redis.Client.Incr(ctx, rulesCacheVersionKey).Err()
+}
+
+func rulesCacheKey(generation int64, roleID string) string {
+	return fmt.Sprintf("%s:%d:%s", rulesCacheKeyPrefix, generation, roleID)
+}
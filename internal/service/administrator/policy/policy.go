@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 
 	"github.com/denizumutdereli/stream-admin/internal/caesar"
@@ -9,8 +10,10 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	rolePolicyModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/peggy"
 
 	policyRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"go.uber.org/zap"
 )
@@ -20,7 +23,13 @@ type AdminPolicyService interface {
 	CreateAdminRolePolicy(ctx context.Context, admin_role *rolePolicyModels.AdministratorRolePolicy) (*rolePolicyModels.AdministratorRolePolicy, appErrors.Error)
 	UpdateAdminRolePolicy(ctx context.Context, adminRolePolicy *rolePolicyModels.AdministratorRolePolicy) (*rolePolicyModels.AdministratorRolePolicyResponse, appErrors.Error)
 	DeleteAdminRolePolicy(ctx context.Context, policyID string) appErrors.Error
+	GetAdminRolePolicyByID(policyID string) (*rolePolicyModels.AdministratorRolePolicyResponse, appErrors.Error)
 	GetAdminRolePolicies(paginationParams *types.PaginationParams, queryParams *rolePolicyModels.AdministratorRolePolicySearch) (*database.PaginatedResult, appErrors.Error)
+	CompileSubPolicies(text string) ([]types.SubRolePolicies, appErrors.Error)
+
+	// enforcement
+	ResolvePolicy(ctx context.Context, roleID string, source types.PolicySource, action types.PolicyAction) (types.PolicyDecision, appErrors.Error)
+	InvalidateRules(ctx context.Context)
 }
 
 type adminPolicyService struct {
@@ -30,14 +39,16 @@ type adminPolicyService struct {
 	config *config.Config
 	logger *zap.Logger
 	caesar caesar.CaesarManager
+	rules  RulesCache
 }
 
-func NewAdminPolicyService(repo *policyRepo.AdminRolePolicyRepository, caesar caesar.CaesarManager, config *config.Config) AdminPolicyService {
+func NewAdminPolicyService(repo *policyRepo.AdminRolePolicyRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) AdminPolicyService {
 	service := &adminPolicyService{
 		repo:   *repo,
 		config: config,
 		logger: config.Logger,
 		caesar: caesar,
+		rules:  NewRulesCache(redis, config),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -51,6 +62,10 @@ func NewAdminPolicyService(repo *policyRepo.AdminRolePolicyRepository, caesar ca
 
 func (s *adminPolicyService) CreateAdminRolePolicy(ctx context.Context, adminRolePolicy *rolePolicyModels.AdministratorRolePolicy) (*rolePolicyModels.AdministratorRolePolicy, appErrors.Error) {
 
+	if err := s.compilePolicyText(adminRolePolicy); err != nil {
+		return nil, err
+	}
+
 	if err := s.validateRolesSubPolicies(&adminRolePolicy.SubPolicies); err != nil {
 		return nil, appErrors.AppError(http.StatusBadRequest, "", "role policy validation has failed", err)
 	}
@@ -70,6 +85,10 @@ func (s *adminPolicyService) CreateAdminRolePolicy(ctx context.Context, adminRol
 
 func (s *adminPolicyService) UpdateAdminRolePolicy(ctx context.Context, adminRolePolicy *rolePolicyModels.AdministratorRolePolicy) (*rolePolicyModels.AdministratorRolePolicyResponse, appErrors.Error) {
 
+	if err := s.compilePolicyText(adminRolePolicy); err != nil {
+		return nil, err
+	}
+
 	if err := s.validateRolesSubPolicies(&adminRolePolicy.SubPolicies); err != nil {
 		return nil, appErrors.AppError(http.StatusBadRequest, "", "role policy validation has failed", err)
 	}
@@ -80,6 +99,8 @@ func (s *adminPolicyService) UpdateAdminRolePolicy(ctx context.Context, adminRol
 		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error updating role policy", err)
 	}
 
+	s.InvalidateRules(ctx)
+
 	return response, nil
 }
 
@@ -90,14 +111,67 @@ func (s *adminPolicyService) DeleteAdminRolePolicy(ctx context.Context, policyID
 		return appErrors.AppError(http.StatusInternalServerError, "", "error deleting role policy", err)
 	}
 
+	s.InvalidateRules(ctx)
+
 	return nil
 }
 
+func (s *adminPolicyService) GetAdminRolePolicyByID(policyID string) (*rolePolicyModels.AdministratorRolePolicyResponse, appErrors.Error) {
+	data, err := s.repo.GetAdminRolePolicyByID(policyID)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusNotFound, "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
 func (s *adminPolicyService) GetAdminRolePolicies(paginationParams *types.PaginationParams, queryParams *rolePolicyModels.AdministratorRolePolicySearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetAdminRolePolicies(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
 }
+
+func (s *adminPolicyService) CompileSubPolicies(text string) ([]types.SubRolePolicies, appErrors.Error) {
+	subPolicies, err := peggy.Parse(text)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusBadRequest), "", err.Error(), err)
+	}
+
+	for _, sp := range subPolicies {
+		if err := validateSubPolicy(sp); err != nil {
+			return nil, appErrors.AppError(http.StatusBadRequest, "", "role policy validation has failed", err)
+		}
+	}
+
+	return subPolicies, nil
+}
+
+// compilePolicyText turns the text form of a policy into its stored JSON
+// form, so both go through the same validation afterwards.
+func (s *adminPolicyService) compilePolicyText(adminRolePolicy *rolePolicyModels.AdministratorRolePolicy) appErrors.Error {
+	if adminRolePolicy.SubPoliciesText == "" {
+		return nil
+	}
+
+	if adminRolePolicy.SubPolicies != "" {
+		return appErrors.AppError(http.StatusBadRequest, "", "provide either sub_policies or sub_policies_text, not both", nil)
+	}
+
+	subPolicies, appErr := s.CompileSubPolicies(adminRolePolicy.SubPoliciesText)
+	if appErr != nil {
+		return appErr
+	}
+
+	subPoliciesJson, err := json.Marshal(subPolicies)
+	if err != nil {
+		return appErrors.AppError(http.StatusInternalServerError, "", "error encoding sub-policies", err)
+	}
+
+	adminRolePolicy.SubPolicies = string(subPoliciesJson)
+	adminRolePolicy.SubPoliciesText = ""
+
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
 )
 
 func (s *adminPolicyService) validateRolesSubPolicies(subPoliciesJson *string) error {
@@ -16,11 +18,7 @@ func (s *adminPolicyService) validateRolesSubPolicies(subPoliciesJson *string) e
 		return errors.New("at least one sub-policy is required")
 	}
 
-	var subPolicies []struct {
-		Source    string `json:"source"`
-		Action    string `json:"action"`
-		Allowance string `json:"allowance"`
-	}
+	var subPolicies []types.SubRolePolicies
 
 	if err := json.Unmarshal([]byte(*subPoliciesJson), &subPolicies); err != nil {
 		return err
@@ -30,30 +28,21 @@ func (s *adminPolicyService) validateRolesSubPolicies(subPoliciesJson *string) e
 		return errors.New("at least one sub-policy is required")
 	}
 
-	unique := make(map[string]struct{})
 	for _, sp := range subPolicies {
-		key := fmt.Sprintf("%s-%s-%s", sp.Source, sp.Action, sp.Allowance)
-		unique[key] = struct{}{}
+		if err := validateSubPolicy(sp); err != nil {
+			return err
+		}
 	}
 
-	var uniqueSubPolicies []struct {
-		Source    string `json:"source"`
-		Action    string `json:"action"`
-		Allowance string `json:"allowance"`
-	}
-	for k := range unique {
-		parts := strings.Split(k, "-")
-		if len(parts) == 3 {
-			uniqueSubPolicies = append(uniqueSubPolicies, struct {
-				Source    string `json:"source"`
-				Action    string `json:"action"`
-				Allowance string `json:"allowance"`
-			}{
-				Source:    parts[0],
-				Action:    parts[1],
-				Allowance: parts[2],
-			})
+	unique := make(map[string]struct{})
+	var uniqueSubPolicies []types.SubRolePolicies
+	for _, sp := range subPolicies {
+		key := fmt.Sprintf("%s-%s-%s-%s", sp.Source, sp.Action, sp.Allowance, strings.Join(sp.Fields, ","))
+		if _, exists := unique[key]; exists {
+			continue
 		}
+		unique[key] = struct{}{}
+		uniqueSubPolicies = append(uniqueSubPolicies, sp)
 	}
 
 	if len(uniqueSubPolicies) > maxSubPolicies {
@@ -69,3 +58,39 @@ func (s *adminPolicyService) validateRolesSubPolicies(subPoliciesJson *string) e
 
 	return nil
 }
+
+func validateSubPolicy(sp types.SubRolePolicies) error {
+	if !isOneOf(sp.Source, types.PolicySource("").EnumValues()) {
+		return fmt.Errorf("unknown sub-policy source '%s', allowed: %s", sp.Source, strings.Join(types.PolicySource("").EnumValues(), ", "))
+	}
+	if !isOneOf(sp.Action, types.PolicyAction("").EnumValues()) {
+		return fmt.Errorf("unknown sub-policy action '%s', allowed: %s", sp.Action, strings.Join(types.PolicyAction("").EnumValues(), ", "))
+	}
+	if !isOneOf(sp.Allowance, types.PolicyAllowance("").EnumValues()) {
+		return fmt.Errorf("unknown sub-policy allowance '%s', allowed: %s", sp.Allowance, strings.Join(types.PolicyAllowance("").EnumValues(), ", "))
+	}
+
+	partial := types.PolicyAllowance(sp.Allowance) == types.AllowancePartialAllowed
+	if partial && len(sp.Fields) == 0 {
+		return fmt.Errorf("sub-policy %s/%s is partially allowed but lists no fields to mask", sp.Source, sp.Action)
+	}
+	if !partial && len(sp.Fields) > 0 {
+		return fmt.Errorf("sub-policy %s/%s lists fields but only a partial allowance masks them", sp.Source, sp.Action)
+	}
+	for _, field := range sp.Fields {
+		if strings.TrimSpace(field) == "" {
+			return fmt.Errorf("sub-policy %s/%s has an empty field name", sp.Source, sp.Action)
+		}
+	}
+
+	return nil
+}
+
+func isOneOf(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
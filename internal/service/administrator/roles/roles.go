@@ -10,6 +10,8 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
 	roles "github.com/denizumutdereli/stream-admin/internal/repository/administrator/roles"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"go.uber.org/zap"
 )
@@ -19,6 +21,7 @@ type AdminUserRolesService interface {
 	GetAdminRoles(paginationParams *types.PaginationParams, queryParams *models.AdministratorRoleSearch) (*database.PaginatedResult, appErrors.Error)
 	AttachPoliciesToRole(ctx context.Context, roleID string, policyIDs []string) (*models.RoleWithPolicyTitles, appErrors.Error)
 	GetAdminRoleByID(roleID string) (models.AdministratorRole, appErrors.Error)
+	GetRoleWithPolicyTitles(roleID string) (*models.RoleWithPolicyTitles, appErrors.Error)
 }
 
 type adminUserRolesService struct {
@@ -28,14 +31,16 @@ type adminUserRolesService struct {
 	config    *config.Config
 	logger    *zap.Logger
 	caesar    caesar.CaesarManager
+	rules     policy.RulesCache
 }
 
-func NewAdminUserRolesService(rolesRepo *roles.AdminUserRolesRepository, caesar caesar.CaesarManager, config *config.Config) AdminUserRolesService {
+func NewAdminUserRolesService(rolesRepo *roles.AdminUserRolesRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) AdminUserRolesService {
 	service := &adminUserRolesService{
 		rolesRepo: *rolesRepo,
 		config:    config,
 		logger:    config.Logger,
 		caesar:    caesar,
+		rules:     policy.NewRulesCache(redis, config),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -63,7 +68,7 @@ func (s *adminUserRolesService) CreateAdminRole(ctx context.Context, adminRole *
 func (s *adminUserRolesService) GetAdminRoles(paginationParams *types.PaginationParams, queryParams *models.AdministratorRoleSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.rolesRepo.GetAdminRoles(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -75,6 +80,11 @@ func (s *adminUserRolesService) AttachPoliciesToRole(ctx context.Context, roleID
 		s.logger.Error("error attaching policies to role", zap.Error(err))
 		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error attaching policies to role", err)
 	}
+
+	if err := s.rules.Invalidate(ctx); err != nil {
+		s.logger.Error("error invalidating policy rules cache", zap.Error(err))
+	}
+
 	return data, nil
 }
 
@@ -86,3 +96,12 @@ func (s *adminUserRolesService) GetAdminRoleByID(roleID string) (models.Administ
 
 	return data, nil
 }
+
+func (s *adminUserRolesService) GetRoleWithPolicyTitles(roleID string) (*models.RoleWithPolicyTitles, appErrors.Error) {
+	data, err := s.rolesRepo.GetRoleWithPolicyTitles(roleID)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusNotFound, "", err.Error(), err)
+	}
+
+	return data, nil
+}
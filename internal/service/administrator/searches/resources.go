@@ -0,0 +1,44 @@
+package searches
+
+import (
+	reportModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	assetModels "github.com/denizumutdereli/stream-admin/internal/models/assets"
+	orderModels "github.com/denizumutdereli/stream-admin/internal/models/orders"
+	transactionModels "github.com/denizumutdereli/stream-admin/internal/models/transactions"
+	userModels "github.com/denizumutdereli/stream-admin/internal/models/users"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+)
+
+var searchTargets = map[reportModels.SearchResource]func() interface{}{
+	reportModels.SearchResourceOrders:   func() interface{} { return &orderModels.OrderSearch{} },
+	reportModels.SearchResourceUsers:    func() interface{} { return &userModels.UserSearch{} },
+	reportModels.SearchResourceKYC:      func() interface{} { return &userModels.UserKYCSearch{} },
+	reportModels.SearchResourceFiat:     func() interface{} { return &transactionModels.FiatTransactionsSearch{} },
+	reportModels.SearchResourceCrypto:   func() interface{} { return &transactionModels.CryptoTransactionsSearch{} },
+	reportModels.SearchResourceWallets:  func() interface{} { return &transactionModels.CryptoWalletsSearch{} },
+	reportModels.SearchResourceCoins:    func() interface{} { return &assetModels.AssetsCoinsSearch{} },
+	reportModels.SearchResourceAssets:   func() interface{} { return &assetModels.AssetsSearch{} },
+	reportModels.SearchResourceNetworks: func() interface{} { return &assetModels.AssetsNetworksSearch{} },
+}
+
+// searchSources are the policy sources of the resources, their decisions tell
+// which fields a saved search may not filter or sort on.
+var searchSources = map[reportModels.SearchResource]types.PolicySource{
+	reportModels.SearchResourceOrders:   types.SourceOrders,
+	reportModels.SearchResourceUsers:    types.SourceUsers,
+	reportModels.SearchResourceKYC:      types.SourceKYC,
+	reportModels.SearchResourceFiat:     types.SourceTransactions,
+	reportModels.SearchResourceCrypto:   types.SourceTransactions,
+	reportModels.SearchResourceWallets:  types.SourceTransactions,
+	reportModels.SearchResourceCoins:    types.SourceAssets,
+	reportModels.SearchResourceAssets:   types.SourceAssets,
+	reportModels.SearchResourceNetworks: types.SourceAssets,
+}
+
+func searchTarget(resource reportModels.SearchResource) (interface{}, bool) {
+	newTarget, ok := searchTargets[resource]
+	if !ok {
+		return nil, false
+	}
+	return newTarget(), true
+}
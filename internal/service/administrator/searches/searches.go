@@ -0,0 +1,240 @@
+package searches
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/denizumutdereli/stream-admin/internal/builders"
+	"github.com/denizumutdereli/stream-admin/internal/caesar"
+	appErrors "github.com/denizumutdereli/stream-admin/internal/common"
+	"github.com/denizumutdereli/stream-admin/internal/config"
+	"github.com/denizumutdereli/stream-admin/internal/database"
+	"github.com/denizumutdereli/stream-admin/internal/masking"
+	reportModels "github.com/denizumutdereli/stream-admin/internal/models/administrator/reports"
+	queryRepo "github.com/denizumutdereli/stream-admin/internal/repository/administrator/reports/query"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/policy"
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AdminSavedSearchService interface {
+	CreateSavedSearch(ctx context.Context, request *reportModels.AdministratorSavedSearchRequest, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error)
+	UpdateSavedSearch(ctx context.Context, request *reportModels.AdministratorSavedSearchRequest, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error)
+	DeleteSavedSearch(ctx context.Context, id uint, userID string) appErrors.Error
+	GetSavedSearch(id uint, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error)
+	GetSavedSearches(paginationParams *types.PaginationParams, queryParams *reportModels.AdministratorDashboardQuerySearch, userID, roleID string) (*database.PaginatedResult, appErrors.Error)
+	RunSavedSearch(ctx context.Context, id uint, resource reportModels.SearchResource, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error)
+}
+
+type adminSavedSearchService struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	repo          queryRepo.QueryRepository
+	config        *config.Config
+	logger        *zap.Logger
+	caesar        caesar.CaesarManager
+	policyService policy.AdminPolicyService
+}
+
+func NewAdminSavedSearchService(repo *queryRepo.QueryRepository, policyService policy.AdminPolicyService, caesar caesar.CaesarManager, config *config.Config) AdminSavedSearchService {
+	service := &adminSavedSearchService{
+		repo:          *repo,
+		config:        config,
+		logger:        config.Logger,
+		caesar:        caesar,
+		policyService: policyService,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service.ctx = ctx
+	service.cancel = cancel
+
+	return service
+}
+
+func (s *adminSavedSearchService) CreateSavedSearch(ctx context.Context, request *reportModels.AdministratorSavedSearchRequest, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error) {
+	compiled, appErr := s.compileSavedSearch(ctx, request, roleID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	savedSearch := &reportModels.AdministratorDashboardQuery{
+		Name:          request.Name,
+		Resource:      request.Resource,
+		Filters:       request.DSL,
+		DSLFilters:    compiled,
+		SortBy:        request.SortBy,
+		SortOrder:     request.SortOrder,
+		SharedRoleIDs: request.SharedRoles,
+		CreatedBy:     userID,
+	}
+
+	if err := s.repo.Create(savedSearch); err != nil {
+		s.logger.Error("error creating saved search", zap.Error(err))
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error creating saved search", err)
+	}
+
+	return savedSearch, nil
+}
+
+// UpdateSavedSearch lets the creator change the search; roles it is shared
+// with may only run it.
+func (s *adminSavedSearchService) UpdateSavedSearch(ctx context.Context, request *reportModels.AdministratorSavedSearchRequest, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error) {
+	savedSearch, appErr := s.getSavedSearch(request.ID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if savedSearch.IsPredefined {
+		return nil, appErrors.AppError(http.StatusForbidden, "", "predefined searches cannot be modified", nil)
+	}
+
+	if savedSearch.CreatedBy != userID {
+		return nil, appErrors.AppError(http.StatusForbidden, "", "only the creator of a saved search can modify it", nil)
+	}
+
+	compiled, appErr := s.compileSavedSearch(ctx, request, roleID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	savedSearch.Name = request.Name
+	savedSearch.Resource = request.Resource
+	savedSearch.Filters = request.DSL
+	savedSearch.DSLFilters = compiled
+	savedSearch.SortBy = request.SortBy
+	savedSearch.SortOrder = request.SortOrder
+	savedSearch.SharedRoleIDs = request.SharedRoles
+
+	if err := s.repo.Update(savedSearch); err != nil {
+		s.logger.Error("error updating saved search", zap.Error(err))
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error updating saved search", err)
+	}
+
+	return savedSearch, nil
+}
+
+func (s *adminSavedSearchService) DeleteSavedSearch(ctx context.Context, id uint, userID string) appErrors.Error {
+	savedSearch, appErr := s.getSavedSearch(id)
+	if appErr != nil {
+		return appErr
+	}
+
+	if savedSearch.IsPredefined {
+		return appErrors.AppError(http.StatusForbidden, "", "predefined searches cannot be deleted", nil)
+	}
+
+	if savedSearch.CreatedBy != userID {
+		return appErrors.AppError(http.StatusForbidden, "", "only the creator of a saved search can delete it", nil)
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		s.logger.Error("error deleting saved search", zap.Error(err))
+		return appErrors.AppError(http.StatusInternalServerError, "", "error deleting saved search", err)
+	}
+
+	return nil
+}
+
+func (s *adminSavedSearchService) GetSavedSearch(id uint, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error) {
+	savedSearch, appErr := s.getSavedSearch(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !savedSearch.IsVisibleTo(userID, roleID) {
+		return nil, appErrors.AppError(http.StatusForbidden, "", "saved search is not shared with your role", nil)
+	}
+
+	return savedSearch, nil
+}
+
+func (s *adminSavedSearchService) GetSavedSearches(paginationParams *types.PaginationParams, queryParams *reportModels.AdministratorDashboardQuerySearch, userID, roleID string) (*database.PaginatedResult, appErrors.Error) {
+	data, err := s.repo.GetAll(paginationParams, queryParams, userID, roleID)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
+func (s *adminSavedSearchService) getSavedSearch(id uint) (*reportModels.AdministratorDashboardQuery, appErrors.Error) {
+	savedSearch, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.AppError(http.StatusNotFound, "", "saved search not found", err)
+	} else if err != nil {
+		return nil, appErrors.AppError(http.StatusInternalServerError, "", "error fetching saved search", err)
+	}
+
+	return savedSearch, nil
+}
+
+// RunSavedSearch resolves a saved search for execution on the given resource
+// endpoint and records the admin who ran it.
+func (s *adminSavedSearchService) RunSavedSearch(ctx context.Context, id uint, resource reportModels.SearchResource, userID, roleID string) (*reportModels.AdministratorDashboardQuery, appErrors.Error) {
+	savedSearch, appErr := s.GetSavedSearch(id, userID, roleID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if savedSearch.Resource != resource {
+		return nil, appErrors.AppError(http.StatusBadRequest, "", "saved search belongs to resource '"+string(savedSearch.Resource)+"'", nil)
+	}
+
+	if err := s.repo.MarkRun(savedSearch.ID, userID, time.Now()); err != nil {
+		s.logger.Error("error recording saved search run", zap.Uint("id", savedSearch.ID), zap.Error(err))
+	}
+
+	return savedSearch, nil
+}
+
+// compileSavedSearch validates the DSL against the resource search struct and
+// returns the parsed conditions as JSON for the dsl_filters column. Fields the
+// role only sees masked on the resource can be neither filtered nor sorted on.
+func (s *adminSavedSearchService) compileSavedSearch(ctx context.Context, request *reportModels.AdministratorSavedSearchRequest, roleID string) (string, appErrors.Error) {
+	target, ok := searchTarget(request.Resource)
+	if !ok {
+		return "", appErrors.AppError(http.StatusBadRequest, "", "unknown search resource '"+string(request.Resource)+"'", nil)
+	}
+
+	sort, err := builders.ParseSort(request.SortBy, request.SortOrder)
+	if err != nil {
+		return "", appErrors.AppError(http.StatusBadRequest, "", err.Error(), err)
+	}
+
+	conditions, err := builders.ParseDSLSearch(request.DSL, target)
+	if err != nil {
+		return "", appErrors.AppError(http.StatusBadRequest, "", "saved search validation has failed", err)
+	}
+
+	decision, appErr := s.policyService.ResolvePolicy(ctx, roleID, searchSources[request.Resource], types.ActionRead)
+	if appErr != nil {
+		return "", appErr
+	}
+
+	if decision.Allowance == types.AllowancePartialAllowed {
+		fields := builders.ConditionFields(conditions)
+		for _, field := range sort {
+			fields = append(fields, field.Column)
+		}
+
+		if details := masking.New(decision.MaskedFields, nil).HiddenDetails(fields...); details != nil {
+			err := &builders.DSLValidationError{Details: make(map[string][]string, len(details))}
+			for field, message := range details {
+				err.Details[field] = []string{message}
+			}
+			return "", appErrors.AppError(http.StatusForbidden, "", "saved search uses masked fields", err)
+		}
+	}
+
+	compiled, err := json.Marshal(conditions)
+	if err != nil {
+		return "", appErrors.AppError(http.StatusInternalServerError, "", "error encoding saved search", err)
+	}
+
+	return string(compiled), nil
+}
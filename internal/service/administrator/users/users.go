@@ -11,7 +11,9 @@ import (
 	"github.com/denizumutdereli/stream-admin/internal/config"
 	"github.com/denizumutdereli/stream-admin/internal/database"
 	models "github.com/denizumutdereli/stream-admin/internal/models/administrator"
+	"github.com/denizumutdereli/stream-admin/internal/notifier"
 	users "github.com/denizumutdereli/stream-admin/internal/repository/administrator/users"
+	"github.com/denizumutdereli/stream-admin/internal/service/administrator/auth"
 	"github.com/denizumutdereli/stream-admin/internal/transport"
 	"github.com/denizumutdereli/stream-admin/internal/types"
 	"go.uber.org/zap"
@@ -24,11 +26,12 @@ type AdminUserService interface {
 	CreateAdminUser(ctx context.Context, admin_user *models.AdministratorUser) (*models.AdministratorUser, appErrors.Error)
 	UpdateAdminUser(ctx context.Context, adminRolePolicy *models.AdministratorUser) (*models.AdministratorUser, appErrors.Error)
 	DeleteAdminUser(ctx context.Context, userID string) appErrors.Error
+	GetAdminUserByID(userID string) (*models.AdministratorUser, appErrors.Error)
 
 	GetAdminUsers(paginationParams *types.PaginationParams, queryParams *models.AdministratorUserSearch) (*database.PaginatedResult, appErrors.Error)
 	VerifyAdminUser(userOTP, username string) (bool, appErrors.Error)
 	GetAdminActiveUsersVPNAddresses() ([]string, appErrors.Error)
-	InitiateOTP(phone, username string, debug bool) (string, appErrors.Error)
+	InitiateOTP(phone, username string) (string, appErrors.Error)
 	VerifyOTP(phone_key, userOTP string) (bool, appErrors.Error)
 
 	SetUserLock(username string) (bool, appErrors.Error)
@@ -41,31 +44,37 @@ type adminUsersService struct {
 	config      *config.Config
 	logger      *zap.Logger
 	caesar      caesar.CaesarManager
-	restClient  *transport.Client
+	otpProvider auth.OTPProvider
 	redisClient *transport.RedisManager
-	debug       bool
+	notifier    notifier.Notifier
 }
 
-func NewAdminUsersService(userRepo *users.AdminUsersRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) AdminUserService {
+func NewAdminUsersService(userRepo *users.AdminUsersRepository, caesar caesar.CaesarManager, redis *transport.RedisManager, config *config.Config) (AdminUserService, error) {
+	notifier, err := notifier.NewNotifier(config)
+	if err != nil {
+		return nil, err
+	}
+
+	otpProvider, err := auth.NewOTPProvider(config, caesar)
+	if err != nil {
+		return nil, err
+	}
+
 	service := &adminUsersService{
 		userRepo:    *userRepo,
 		config:      config,
 		logger:      config.Logger,
 		caesar:      caesar,
-		restClient:  transport.NewRestClient(config.OTPServiceApi, config.Logger),
+		otpProvider: otpProvider,
 		redisClient: redis,
-		debug:       false,
-	}
-
-	if service.config.Test == "true" {
-		service.debug = true
+		notifier:    notifier,
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	service.ctx = ctx
 	service.cancel = cancel
 
-	return service
+	return service, nil
 }
 
 /* Admin setup ------------------------------------------------------------------------------------------------------ */
@@ -81,7 +90,17 @@ func (s *adminUsersService) CreateSuperAdmin() appErrors.Error {
 
 /* Admin users ------------------------------------------------------------------------------------------------------ */
 
+// CreateAdminUser adds the user with a temporary password that is mailed to
+// them and has to be changed on the first sign in.
 func (s *adminUsersService) CreateAdminUser(ctx context.Context, admin_user *models.AdministratorUser) (*models.AdministratorUser, appErrors.Error) {
+	temporaryPassword, err := caesar.GeneratePassword(s.temporaryPasswordLength())
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "error generating the temporary password", err)
+	}
+
+	admin_user.Password = temporaryPassword
+	admin_user.MustChangePassword = true
+
 	isNew, err := s.userRepo.CreateAdminUser(admin_user)
 	if err != nil {
 		s.logger.Error("error creating admin user", zap.Error(err))
@@ -92,7 +111,12 @@ func (s *adminUsersService) CreateAdminUser(ctx context.Context, admin_user *mod
 		return nil, appErrors.AppError(http.StatusConflict, "", "user already exists", nil)
 	}
 
-	_, err = s.InitiateOTP(admin_user.PhoneNumber, admin_user.Username, s.debug)
+	if err := s.sendTemporaryPassword(ctx, admin_user.Username, temporaryPassword); err != nil {
+		s.logger.Error("error sending the temporary password", zap.String("user_id", admin_user.UserID), zap.Error(err))
+		return nil, appErrors.AppError(http.StatusServiceUnavailable, "", "user created but the temporary password could not be sent, use password reset", err)
+	}
+
+	_, err = s.InitiateOTP(admin_user.PhoneNumber, admin_user.Username)
 	if err != nil {
 		return &models.AdministratorUser{}, appErrors.AppError(http.StatusServiceUnavailable, "", "otp code could not send", nil)
 	}
@@ -100,6 +124,25 @@ func (s *adminUsersService) CreateAdminUser(ctx context.Context, admin_user *mod
 	return admin_user, nil
 }
 
+func (s *adminUsersService) sendTemporaryPassword(ctx context.Context, email, temporaryPassword string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.config.DefaultFuncsTimeOutInSeconds)*time.Second)
+	defer cancel()
+
+	return s.notifier.Notify(ctx, &notifier.Message{
+		To:      email,
+		Subject: fmt.Sprintf("Your %s account", s.config.AppName),
+		Body: fmt.Sprintf("An admin account was created for you on %s.\n\nYour temporary password is: %s\n\nYou will be asked to change it when you first sign in.",
+			s.config.AppName, temporaryPassword),
+	})
+}
+
+func (s *adminUsersService) temporaryPasswordLength() int {
+	if s.config.PasswordRules.MinLength > 16 {
+		return s.config.PasswordRules.MinLength
+	}
+	return 16
+}
+
 func (s *adminUsersService) UpdateAdminUser(ctx context.Context, adminUser *models.AdministratorUser) (*models.AdministratorUser, appErrors.Error) {
 
 	response, err := s.userRepo.UpdateAdminUser(adminUser)
@@ -121,10 +164,19 @@ func (s *adminUsersService) DeleteAdminUser(ctx context.Context, policyID string
 	return nil
 }
 
+func (s *adminUsersService) GetAdminUserByID(userID string) (*models.AdministratorUser, appErrors.Error) {
+	adminUser, err := s.userRepo.FindAdminUserByID(userID)
+	if err != nil {
+		return nil, appErrors.AppError(http.StatusNotFound, "", "admin user not found", err)
+	}
+
+	return &adminUser, nil
+}
+
 func (s *adminUsersService) GetAdminUsers(paginationParams *types.PaginationParams, queryParams *models.AdministratorUserSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.userRepo.GetAdminUsers(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -168,44 +220,18 @@ func (s *adminUsersService) GetAdminActiveUsersVPNAddresses() ([]string, appErro
 	return data, nil
 }
 
-func (s *adminUsersService) InitiateOTP(phone, username string, debug bool) (string, appErrors.Error) {
-	var otp string
-	var err error
-
-	if !debug {
-		otp, err = s.caesar.GenerateOTP()
-		if err != nil {
-			s.logger.Error("Error generating OTP", zap.Error(err))
-			return "", appErrors.AppError(http.StatusServiceUnavailable, "", "error generating otp code", err)
-		}
-
-		type SMSServiceMessage struct {
-			Recipient string `json:"recipient"`
-			Message   string `json:"message"`
-		}
-
-		requestBody := &SMSServiceMessage{
-			Recipient: phone,
-			Message:   fmt.Sprintf("Your %s code is: %s", s.config.AppName, otp),
-		}
-
-		headers := make(map[string]string)
-		headers["Content-Type"] = "application/json"
-		headers["X-Secret"] = s.config.OTPServiceKey
-		response, err := s.restClient.DoRequest("POST", "", requestBody, headers)
-		if err != nil {
-			return "", appErrors.AppError(http.StatusServiceUnavailable, "", "OTP service call problem", err)
-		}
+func (s *adminUsersService) InitiateOTP(phone, username string) (string, appErrors.Error) {
+	phone_key := fmt.Sprintf("%s%s%s", phone, username, "_admin_verify")
 
-		if response.StatusCode != http.StatusOK {
-			s.logger.Error("SMS service failed to send the message", zap.Int("status", response.StatusCode), zap.String("response:", string(response.Body)))
-			return "", appErrors.AppError(http.StatusServiceUnavailable, "", "failed to send the OTP code", nil)
-		}
-	} else {
-		otp = "999900" // ref config Test bool
+	otp, err := s.otpProvider.Generate(phone_key)
+	if err != nil {
+		s.logger.Error("Error generating OTP", zap.Error(err))
+		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "error generating otp code", err)
 	}
 
-	phone_key := fmt.Sprintf("%s%s%s", phone, username, "_admin_verify")
+	if err := s.otpProvider.Deliver(phone, otp); err != nil {
+		return "", appErrors.AppError(http.StatusServiceUnavailable, "", "failed to send the OTP code", err)
+	}
 
 	err = s.caesar.StoreOTP(phone_key, otp, time.Now().Add(time.Duration(s.config.OTPCodesInMinutes+1)*time.Minute))
 	if err != nil {
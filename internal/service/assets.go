@@ -18,8 +18,11 @@ import (
 type AssetsService interface {
 	GetSearchParameters() ([]types.SearchParameters, appErrors.Error)
 	GetCoins(paginationParams *types.PaginationParams, queryParams *models.AssetsCoinsSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportCoins(exportParams *types.ExportParams, queryParams *models.AssetsCoinsSearch, handle database.RowHandler) (int, appErrors.Error)
 	GetAssets(paginationParams *types.PaginationParams, queryParams *models.AssetsSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportAssets(exportParams *types.ExportParams, queryParams *models.AssetsSearch, handle database.RowHandler) (int, appErrors.Error)
 	GetNetworks(paginationParams *types.PaginationParams, queryParams *models.AssetsNetworksSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportNetworks(exportParams *types.ExportParams, queryParams *models.AssetsNetworksSearch, handle database.RowHandler) (int, appErrors.Error)
 }
 
 type assetsService struct {
@@ -60,7 +63,7 @@ func (s *assetsService) GetSearchParameters() ([]types.SearchParameters, appErro
 func (s *assetsService) GetCoins(paginationParams *types.PaginationParams, queryParams *models.AssetsCoinsSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetCoins(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -69,7 +72,7 @@ func (s *assetsService) GetCoins(paginationParams *types.PaginationParams, query
 func (s *assetsService) GetAssets(paginationParams *types.PaginationParams, queryParams *models.AssetsSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetAssets(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -78,8 +81,35 @@ func (s *assetsService) GetAssets(paginationParams *types.PaginationParams, quer
 func (s *assetsService) GetNetworks(paginationParams *types.PaginationParams, queryParams *models.AssetsNetworksSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetNetworks(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
 }
+
+func (s *assetsService) ExportCoins(exportParams *types.ExportParams, queryParams *models.AssetsCoinsSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportCoins(exportParams, queryParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *assetsService) ExportAssets(exportParams *types.ExportParams, queryParams *models.AssetsSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportAssets(exportParams, queryParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *assetsService) ExportNetworks(exportParams *types.ExportParams, queryParams *models.AssetsNetworksSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportNetworks(exportParams, queryParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
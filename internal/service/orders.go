@@ -16,6 +16,8 @@ import (
 
 type OrdersService interface {
 	GetAll(paginationParams *types.PaginationParams, queryParams *models.OrderSearch) (*database.PaginatedResult, appErrors.Error)
+	Export(exportParams *types.ExportParams, queryParams *models.OrderSearch, handle database.RowHandler) (int, appErrors.Error)
+	Aggregate(aggregateParams *types.AggregateParams, queryParams *models.OrderSearch) (*database.AggregatedResult, appErrors.Error)
 }
 
 type ordersService struct {
@@ -47,7 +49,25 @@ func NewOrdersService(appContext *types.ExchangeConfig, repo *repos.OrdersReposi
 func (s *ordersService) GetAll(paginationParams *types.PaginationParams, queryParams *models.OrderSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetAll(paginationParams, queryParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
+func (s *ordersService) Export(exportParams *types.ExportParams, queryParams *models.OrderSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.Export(exportParams, queryParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *ordersService) Aggregate(aggregateParams *types.AggregateParams, queryParams *models.OrderSearch) (*database.AggregatedResult, appErrors.Error) {
+	data, err := s.repo.Aggregate(aggregateParams, queryParams)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -17,8 +17,13 @@ import (
 type TransactionService interface {
 	GetSearchParameters() ([]types.SearchParameters, appErrors.Error)
 	GetFiatTransactions(paginationParams *types.PaginationParams, searchParams *models.FiatTransactionsSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportFiatTransactions(exportParams *types.ExportParams, searchParams *models.FiatTransactionsSearch, handle database.RowHandler) (int, appErrors.Error)
 	GetCryptoTransactions(paginationParams *types.PaginationParams, searchParams *models.CryptoTransactionsSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportCryptoTransactions(exportParams *types.ExportParams, searchParams *models.CryptoTransactionsSearch, handle database.RowHandler) (int, appErrors.Error)
 	GetCryptoWallets(paginationParams *types.PaginationParams, searchParams *models.CryptoWalletsSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportCryptoWallets(exportParams *types.ExportParams, searchParams *models.CryptoWalletsSearch, handle database.RowHandler) (int, appErrors.Error)
+	AggregateFiatTransactions(aggregateParams *types.AggregateParams, searchParams *models.FiatTransactionsSearch) (*database.AggregatedResult, appErrors.Error)
+	AggregateCryptoTransactions(aggregateParams *types.AggregateParams, searchParams *models.CryptoTransactionsSearch) (*database.AggregatedResult, appErrors.Error)
 }
 
 type transactionService struct {
@@ -59,7 +64,7 @@ func (s *transactionService) GetSearchParameters() ([]types.SearchParameters, ap
 func (s *transactionService) GetFiatTransactions(paginationParams *types.PaginationParams, searchParams *models.FiatTransactionsSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetFiatTransactions(paginationParams, searchParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -68,7 +73,7 @@ func (s *transactionService) GetFiatTransactions(paginationParams *types.Paginat
 func (s *transactionService) GetCryptoTransactions(paginationParams *types.PaginationParams, searchParams *models.CryptoTransactionsSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetCryptoTransactions(paginationParams, searchParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -77,7 +82,52 @@ func (s *transactionService) GetCryptoTransactions(paginationParams *types.Pagin
 func (s *transactionService) GetCryptoWallets(paginationParams *types.PaginationParams, searchParams *models.CryptoWalletsSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetCryptoWallets(paginationParams, searchParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
+func (s *transactionService) ExportFiatTransactions(exportParams *types.ExportParams, searchParams *models.FiatTransactionsSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportFiatTransactions(exportParams, searchParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *transactionService) ExportCryptoTransactions(exportParams *types.ExportParams, searchParams *models.CryptoTransactionsSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportCryptoTransactions(exportParams, searchParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *transactionService) ExportCryptoWallets(exportParams *types.ExportParams, searchParams *models.CryptoWalletsSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportCryptoWallets(exportParams, searchParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *transactionService) AggregateFiatTransactions(aggregateParams *types.AggregateParams, searchParams *models.FiatTransactionsSearch) (*database.AggregatedResult, appErrors.Error) {
+	data, err := s.repo.AggregateFiatTransactions(aggregateParams, searchParams)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
+	}
+
+	return data, nil
+}
+
+func (s *transactionService) AggregateCryptoTransactions(aggregateParams *types.AggregateParams, searchParams *models.CryptoTransactionsSearch) (*database.AggregatedResult, appErrors.Error) {
+	data, err := s.repo.AggregateCryptoTransactions(aggregateParams, searchParams)
+	if err != nil {
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -18,8 +18,10 @@ type UsersService interface {
 	GetSearchUserParameters() ([]types.SearchParameters, appErrors.Error)
 	GetSearchKYCParameters() ([]types.SearchParameters, appErrors.Error)
 	GetUsers(paginationParams *types.PaginationParams, searchParams *models.UserSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportUsers(exportParams *types.ExportParams, searchParams *models.UserSearch, handle database.RowHandler) (int, appErrors.Error)
 	// GetUserDetailsBuilder(userId int, includeDetails *userTypes.UserDetailsIncluding, paginationParams *types.PaginationParams) (*database.DataResult, error)
 	GetKYC(paginationParams *types.PaginationParams, searchParams *models.UserKYCSearch) (*database.PaginatedResult, appErrors.Error)
+	ExportKYC(exportParams *types.ExportParams, searchParams *models.UserKYCSearch, handle database.RowHandler) (int, appErrors.Error)
 }
 
 type usersService struct {
@@ -94,7 +96,7 @@ func (s *usersService) GetSearchKYCParameters() ([]types.SearchParameters, appEr
 func (s *usersService) GetUsers(paginationParams *types.PaginationParams, searchParams *models.UserSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetUsers(paginationParams, searchParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
@@ -114,8 +116,26 @@ func (s *usersService) GetUsers(paginationParams *types.PaginationParams, search
 func (s *usersService) GetKYC(paginationParams *types.PaginationParams, searchParams *models.UserKYCSearch) (*database.PaginatedResult, appErrors.Error) {
 	data, err := s.repo.GetKYC(paginationParams, searchParams)
 	if err != nil {
-		return nil, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+		return nil, appErrors.AppError(appErrors.StatusOf(err, http.StatusInternalServerError), "", err.Error(), err)
 	}
 
 	return data, nil
 }
+
+func (s *usersService) ExportUsers(exportParams *types.ExportParams, searchParams *models.UserSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportUsers(exportParams, searchParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
+
+func (s *usersService) ExportKYC(exportParams *types.ExportParams, searchParams *models.UserKYCSearch, handle database.RowHandler) (int, appErrors.Error) {
+	rows, err := s.repo.ExportKYC(exportParams, searchParams, handle)
+	if err != nil {
+		return rows, appErrors.AppError(http.StatusInternalServerError, "", err.Error(), err)
+	}
+
+	return rows, nil
+}
@@ -53,6 +53,18 @@ func SetupApp(cfg *config.Config, logger *zap.Logger) (factory.ServiceFactory, e
 			_, err := serviceFactory.NewAdminPolicyService(ctx)
 			return err
 		},
+		func(ctx context.Context) error {
+			_, err := serviceFactory.NewAdminSavedSearchService(ctx)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := serviceFactory.NewAdminApprovalsService(ctx)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := serviceFactory.NewAdminAllowlistService(ctx)
+			return err
+		},
 		func(ctx context.Context) error {
 			_, err := serviceFactory.NewOrdersService(ctx)
 			return err
@@ -0,0 +1,65 @@
+package types
+
+// AggregateParams is a parsed ?group_by and ?metrics pair. MaxGroups caps the
+// number of groups returned, 0 means no cap.
+type AggregateParams struct {
+	GroupBy   []AggregateGroup
+	Metrics   []AggregateMetric
+	MaxGroups int
+}
+
+// AggregateGroup groups by Column, or by Bucket (hour, day) of a time column.
+type AggregateGroup struct {
+	Column string
+	Bucket string
+}
+
+// AggregateMetric applies Func (count, sum, avg, min, max) to Column. A count
+// without a column counts rows.
+type AggregateMetric struct {
+	Func   string
+	Column string
+}
+
+func (g AggregateGroup) Alias() string {
+	if g.Bucket == "" {
+		return g.Column
+	}
+	return g.Column + "_" + g.Bucket
+}
+
+func (m AggregateMetric) Alias() string {
+	if m.Column == "" {
+		return m.Func
+	}
+	return m.Func + "_" + m.Column
+}
+
+// Aliases returns the result column names of the groups and metrics.
+func (p *AggregateParams) Aliases() (groupBy []string, metrics []string) {
+	groupBy = make([]string, 0, len(p.GroupBy))
+	metrics = make([]string, 0, len(p.Metrics))
+
+	for _, group := range p.GroupBy {
+		groupBy = append(groupBy, group.Alias())
+	}
+	for _, metric := range p.Metrics {
+		metrics = append(metrics, metric.Alias())
+	}
+	return groupBy, metrics
+}
+
+// Columns returns the source columns the groups and metrics read.
+func (p *AggregateParams) Columns() []string {
+	columns := make([]string, 0, len(p.GroupBy)+len(p.Metrics))
+
+	for _, group := range p.GroupBy {
+		columns = append(columns, group.Column)
+	}
+	for _, metric := range p.Metrics {
+		if metric.Column != "" {
+			columns = append(columns, metric.Column)
+		}
+	}
+	return columns
+}
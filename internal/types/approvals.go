@@ -0,0 +1,28 @@
+package types
+
+import "context"
+
+// ApprovalReplay identifies a request that is replayed on behalf of its
+// requester once another administrator approved it. It only travels in the
+// request context, so it cannot be forged by a client. StepUpVerified tells
+// the approver passed step-up on the approve call.
+type ApprovalReplay struct {
+	ApprovalID     string
+	UserID         string
+	RoleID         string
+	UserAgent      string
+	Source         PolicySource
+	Action         PolicyAction
+	StepUpVerified bool
+}
+
+type approvalReplayKey struct{}
+
+func WithApprovalReplay(ctx context.Context, replay *ApprovalReplay) context.Context {
+	return context.WithValue(ctx, approvalReplayKey{}, replay)
+}
+
+func ApprovalReplayFrom(ctx context.Context) (*ApprovalReplay, bool) {
+	replay, ok := ctx.Value(approvalReplayKey{}).(*ApprovalReplay)
+	return replay, ok && replay != nil
+}
@@ -0,0 +1,20 @@
+package types
+
+import "time"
+
+// AuditRecord gathers what the audit middleware and the handler learn about
+// a request, until the request is logged once it is over.
+type AuditRecord struct {
+	StartedAt    time.Time
+	ResourceType string
+	ResourceID   string
+	RequestBody  string
+	Before       interface{}
+	After        interface{}
+	ErrorMessage string
+}
+
+type AuditFieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
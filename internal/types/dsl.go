@@ -1,9 +1,19 @@
 package types
 
+const (
+	LogicAnd = "and"
+	LogicOr  = "or"
+)
+
 type QueryCondition struct {
-	Field    string
-	Operator string
-	Value    string
+	Field      string           `json:"field,omitempty"`
+	Operator   string           `json:"operator,omitempty"`
+	Value      string           `json:"value,omitempty"`
+	Values     []string         `json:"values,omitempty"`
+	TypedValue interface{}      `json:"-"`
+	Logic      string           `json:"logic,omitempty"`
+	Negate     bool             `json:"negate,omitempty"`
+	Group      []QueryCondition `json:"group,omitempty"`
 }
 
 type SearchParameters struct {
@@ -1,6 +1,50 @@
 package types
 
+import "net/http"
+
 type ErrorResponse struct {
 	Error   string      `json:"error"`
 	Details interface{} `json:"details"`
 }
+
+// FieldError is a request error that points at the offending fields, which
+// are returned as the response details.
+type FieldError interface {
+	error
+	FieldDetails() map[string]string
+}
+
+// SortFieldError lists the sortBy columns a resource cannot be sorted on.
+type SortFieldError struct {
+	Details map[string]string
+}
+
+func (e *SortFieldError) Error() string {
+	return "sortBy contains unknown or unsortable fields"
+}
+
+func (e *SortFieldError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *SortFieldError) FieldDetails() map[string]string {
+	return e.Details
+}
+
+// AggregateFieldError lists the group_by and metrics fields a resource cannot
+// be aggregated on.
+type AggregateFieldError struct {
+	Details map[string]string
+}
+
+func (e *AggregateFieldError) Error() string {
+	return "group_by or metrics contain unknown or unsupported fields"
+}
+
+func (e *AggregateFieldError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *AggregateFieldError) FieldDetails() map[string]string {
+	return e.Details
+}
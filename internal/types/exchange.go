@@ -24,6 +24,7 @@ type ExchangeConfig struct {
 
 type ContextualMessage struct {
 	UserId                string
+	SessionId             string
 	MessageType           string
 	Message               string
 	RedisDelivery         bool
@@ -0,0 +1,8 @@
+package types
+
+type ExportParams struct {
+	Format    string
+	Fields    []string
+	MaxRows   int
+	BatchSize int
+}
@@ -1,5 +1,7 @@
 package types
 
+import "github.com/denizumutdereli/stream-admin/internal/database"
+
 type QueryParams struct {
 	Pagination PaginationParams
 	DSLQuery   []QueryCondition
@@ -10,4 +12,12 @@ type PaginationParams struct {
 	Limit     int
 	SortBy    string
 	SortOrder string
+	Sort      []SortField
+	Cursor    *database.Cursor
+	CountMode database.CountMode
+}
+
+type SortField struct {
+	Column string
+	Order  string
 }
@@ -1,5 +1,7 @@
 package types
 
+import "net/http"
+
 type DefaultUserRoles string
 
 const (
@@ -7,8 +9,92 @@ const (
 	RegularUser DefaultUserRoles = "regular"
 )
 
+type PolicyAllowance string
+
+const (
+	AllowanceAllowed        PolicyAllowance = "allowed"
+	AllowanceNotAllowed     PolicyAllowance = "not allowed"
+	AllowanceRequireOTP     PolicyAllowance = "require otp"
+	AllowanceAskPermission  PolicyAllowance = "ask permission"
+	AllowancePartialAllowed PolicyAllowance = "partial allowed"
+)
+
+type PolicyAction string
+
+const (
+	ActionRead   PolicyAction = "read"
+	ActionCreate PolicyAction = "create"
+	ActionUpdate PolicyAction = "update"
+	ActionDelete PolicyAction = "delete"
+)
+
+type PolicySource string
+
+const (
+	SourceOrders       PolicySource = "orders"
+	SourceUsers        PolicySource = "users"
+	SourceKYC          PolicySource = "kyc"
+	SourceTransactions PolicySource = "transactions"
+	SourceAssets       PolicySource = "assets"
+	SourceAdminUsers   PolicySource = "admin_users"
+	SourceRoles        PolicySource = "roles"
+	SourcePolicies     PolicySource = "policies"
+	SourceSearches     PolicySource = "searches"
+	SourceLogs         PolicySource = "logs"
+	SourceSystem       PolicySource = "system"
+	SourceApprovals    PolicySource = "approvals"
+	SourceAllowlist    PolicySource = "allowlist"
+)
+
 type SubRolePolicies struct {
-	Source    string `json:"source"`
-	Action    string `json:"action"`
-	Allowance string `json:"allowance"`
+	Source    string   `json:"source"`
+	Action    string   `json:"action"`
+	Allowance string   `json:"allowance"`
+	Fields    []string `json:"fields,omitempty"` // masked fields of a partial allowance
+}
+
+// RoleRules is the flattened set of sub-policies of a role's active
+// policies, as it is cached for enforcement.
+type RoleRules struct {
+	RoleID   string            `json:"role_id"`
+	RoleName string            `json:"role_name"`
+	Rules    []SubRolePolicies `json:"rules"`
+}
+
+type PolicyDecision struct {
+	Source       PolicySource    `json:"source"`
+	Action       PolicyAction    `json:"action"`
+	Allowance    PolicyAllowance `json:"allowance"`
+	MaskedFields []string        `json:"masked_fields,omitempty"`
+	SuperAdmin   bool            `json:"super_admin"`
+}
+
+func (PolicyAllowance) EnumValues() []string {
+	return []string{string(AllowanceAllowed), string(AllowanceNotAllowed), string(AllowanceRequireOTP), string(AllowanceAskPermission), string(AllowancePartialAllowed)}
+}
+
+func (PolicyAction) EnumValues() []string {
+	return []string{string(ActionRead), string(ActionCreate), string(ActionUpdate), string(ActionDelete)}
+}
+
+func (PolicySource) EnumValues() []string {
+	return []string{
+		string(SourceOrders), string(SourceUsers), string(SourceKYC), string(SourceTransactions), string(SourceAssets),
+		string(SourceAdminUsers), string(SourceRoles), string(SourcePolicies), string(SourceSearches), string(SourceLogs), string(SourceSystem),
+		string(SourceApprovals), string(SourceAllowlist),
+	}
+}
+
+// PolicyActionForMethod maps an http method to the policy action it performs.
+func PolicyActionForMethod(method string) PolicyAction {
+	switch method {
+	case http.MethodPost:
+		return ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionRead
+	}
 }
@@ -1,14 +1,17 @@
 package types
 
 import (
-	"github.com/dgrijalva/jwt-go"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
 )
 
 type TokenResponse struct {
-	AccessToken           string `json:"access_token"`
-	RefreshToken          string `json:"refresh_token"`
-	AccessTokenExpiresIn  int64  `json:"access_token_expires_in"`
-	RefreshTokenExpiresIn int64  `json:"refresh_token_expires_in"`
+	AccessToken            string `json:"access_token"`
+	RefreshToken           string `json:"refresh_token"`
+	AccessTokenExpiresIn   int64  `json:"access_token_expires_in"`
+	RefreshTokenExpiresIn  int64  `json:"refresh_token_expires_in"`
+	PasswordChangeRequired bool   `json:"password_change_required,omitempty"`
 }
 
 type AccessTokenClaims struct {
@@ -16,25 +19,81 @@ type AccessTokenClaims struct {
 	UserAgent string `json:"user_agent"`
 	UserRole  string `json:"user_role"`
 	RoleID    string `json:"role_id"`
+	SessionID string `json:"session_id"`
 	TokenType string `json:"token_type"`
 	Token     string `json:"token"`
+	// PasswordChangeRequired limits the token to changing the password
+	PasswordChangeRequired bool `json:"password_change_required,omitempty"`
 	jwt.StandardClaims
 }
 
 type RefreshTokenMetadata struct {
 	UserID    string `json:"user_id"`
 	UserAgent string `json:"user_agent"`
+	SessionID string `json:"session_id"`
 	TokenType string `json:"token_type"`
 	Token     string `json:"token"`
 	jwt.StandardClaims
 }
 
+// AdminSession is a device an admin user is signed in from. Every login opens
+// a session and refreshing its tokens keeps it.
+type AdminSession struct {
+	SessionID  string    `json:"session_id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	Ip         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// StepUpChallenge is a pending second factor for a sensitive action. It is
+// bound to the user and to the method and path of the request it guards.
+type StepUpChallenge struct {
+	ChallengeID string    `json:"challenge_id"`
+	UserID      string    `json:"user_id"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Factor      string    `json:"factor"`
+	Attempts    int       `json:"attempts"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// StepUpToken lets a single retry of the challenged request through.
+type StepUpToken struct {
+	Token     string `json:"step_up_token"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// TOTPEnrollment is what an authenticator app is set up from. The secret is
+// only kept for the user once a first code from the app is confirmed.
+type TOTPEnrollment struct {
+	Secret    string    `json:"secret"`
+	URI       string    `json:"otpauth_uri"`
+	QRCode    []byte    `json:"qr_code_png"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type ContextKey string
 
 const (
 	ContextUserIDKey ContextKey = "user_id"
 	ContextRoleKey   ContextKey = "user_role"
 	ContextUserAgent ContextKey = "user_agent"
+	ContextSessionID ContextKey = "session_id"
+
+	ContextClientIPKey ContextKey = "client_ip"
+
+	ContextPasswordChangeRequiredKey ContextKey = "password_change_required"
+
+	ContextSavedSearchKey    ContextKey = "saved_search"
+	ContextPolicyDecisionKey ContextKey = "policy_decision"
+	ContextStepUpVerifiedKey ContextKey = "step_up_verified"
+
+	ContextAuditRecordKey ContextKey = "audit_record"
 )
 
 type TokenMetadata struct {
@@ -43,3 +102,21 @@ type TokenMetadata struct {
 	TokenType string `json:"token_type"`
 	IssuedAt  int64  `json:"issued_at"`
 }
+
+// JWK is the public part of an access token signing key, as published in the
+// JWKS document for other services to verify admin tokens with.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
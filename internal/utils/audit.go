@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/denizumutdereli/stream-admin/internal/types"
+	"github.com/gin-gonic/gin"
+)
+
+const redactedValue = "[REDACTED]"
+
+// AuditRecordOf returns the audit record of the request, nil when the route
+// is not audited.
+func AuditRecordOf(c *gin.Context) *types.AuditRecord {
+	value, _ := c.Get(string(types.ContextAuditRecordKey))
+	record, _ := value.(*types.AuditRecord)
+	return record
+}
+
+// SetAuditResource names the resource the request acts on. An empty ID keeps
+// the one taken from the route.
+func SetAuditResource(c *gin.Context, resourceType, resourceID string) {
+	record := AuditRecordOf(c)
+	if record == nil {
+		return
+	}
+
+	if resourceType != "" {
+		record.ResourceType = resourceType
+	}
+	if resourceID != "" {
+		record.ResourceID = resourceID
+	}
+}
+
+// SetAuditChange keeps the resource as it was before and after the request,
+// they are logged as the diff of their JSON fields.
+func SetAuditChange(c *gin.Context, resourceID string, before, after interface{}) {
+	record := AuditRecordOf(c)
+	if record == nil {
+		return
+	}
+
+	SetAuditResource(c, "", resourceID)
+	record.Before, record.After = before, after
+}
+
+// RedactJSON masks the values of the keys containing one of the fields, at
+// any depth. It reports false when data is not JSON.
+func RedactJSON(data []byte, fields []string) (string, bool) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", false
+	}
+
+	redacted, err := json.Marshal(redactValue(value, fields))
+	if err != nil {
+		return "", false
+	}
+
+	return string(redacted), true
+}
+
+// AuditDiff compares the JSON fields of before and after and returns the
+// changed ones, redacted values masked on both sides.
+func AuditDiff(before, after interface{}, fields []string) map[string]types.AuditFieldChange {
+	beforeFields, afterFields := jsonFields(before), jsonFields(after)
+	changes := make(map[string]types.AuditFieldChange)
+
+	for key, afterValue := range afterFields {
+		beforeValue, ok := beforeFields[key]
+		if ok && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		changes[key] = types.AuditFieldChange{Before: beforeValue, After: afterValue}
+	}
+
+	for key, beforeValue := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			changes[key] = types.AuditFieldChange{Before: beforeValue}
+		}
+	}
+
+	for key, change := range changes {
+		if isRedactedField(key, fields) {
+			if change.Before != nil {
+				change.Before = redactedValue
+			}
+			if change.After != nil {
+				change.After = redactedValue
+			}
+		} else {
+			change.Before, change.After = redactValue(change.Before, fields), redactValue(change.After, fields)
+		}
+		changes[key] = change
+	}
+
+	return changes
+}
+
+func jsonFields(value interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var whole interface{}
+		if json.Unmarshal(data, &whole) == nil {
+			fields["value"] = whole
+		}
+	}
+
+	return fields
+}
+
+func redactValue(value interface{}, fields []string) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range typed {
+			if isRedactedField(key, fields) {
+				typed[key] = redactedValue
+			} else {
+				typed[key] = redactValue(inner, fields)
+			}
+		}
+	case []interface{}:
+		for i, inner := range typed {
+			typed[i] = redactValue(inner, fields)
+		}
+	}
+
+	return value
+}
+
+func isRedactedField(key string, fields []string) bool {
+	key = strings.ToLower(key)
+	for _, field := range fields {
+		if field != "" && strings.Contains(key, strings.ToLower(field)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ForwardedHeaderForwarded reads the RFC 7239 Forwarded header
+	ForwardedHeaderForwarded = "forwarded"
+	// ForwardedHeaderXFF reads X-Forwarded-For
+	ForwardedHeaderXFF = "xff"
+)
+
+// ClientIPResolver finds the address of the client behind the trusted
+// proxies. Only the header the trusted proxies write is read, a client can
+// send the other one as it likes. It is read right to left and only as far
+// as the hops are trusted, so addresses a client puts in it itself are never
+// taken for its own.
+type ClientIPResolver struct {
+	trustedProxies  []*net.IPNet
+	forwardedHeader string
+}
+
+func NewClientIPResolver(trustedProxies []string, forwardedHeader string) (*ClientIPResolver, error) {
+	if forwardedHeader != ForwardedHeaderForwarded && forwardedHeader != ForwardedHeaderXFF {
+		return nil, fmt.Errorf("unknown forwarded header %q, use %s or %s", forwardedHeader, ForwardedHeaderForwarded, ForwardedHeaderXFF)
+	}
+
+	resolver := &ClientIPResolver{forwardedHeader: forwardedHeader}
+
+	for _, proxy := range trustedProxies {
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			resolver.trustedProxies = append(resolver.trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, network)
+	}
+
+	return resolver, nil
+}
+
+// Resolve returns the client address of the request. The peer is the client
+// unless it is a trusted proxy, in which case the hops it forwarded are
+// walked back until one that is not trusted.
+func (r *ClientIPResolver) Resolve(request *http.Request) string {
+	remoteIP := parseHopIP(request.RemoteAddr)
+	if remoteIP == nil {
+		return NormalizeIP(request.RemoteAddr)
+	}
+
+	clientIP := remoteIP
+	if r.isTrusted(remoteIP) {
+		var hops []string
+		if r.forwardedHeader == ForwardedHeaderForwarded {
+			hops = forwardedFor(request.Header)
+		} else {
+			hops = xForwardedFor(request.Header)
+		}
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hopIP := parseHopIP(hops[i])
+			if hopIP == nil {
+				// obfuscated or malformed, nothing before it can be trusted
+				break
+			}
+
+			clientIP = hopIP
+			if !r.isTrusted(hopIP) {
+				break
+			}
+		}
+	}
+
+	return NormalizeIP(clientIP.String())
+}
+
+func (r *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the "for" addresses of the RFC 7239 Forwarded headers,
+// nearest hop last.
+func forwardedFor(header http.Header) []string {
+	var hops []string
+
+	for _, value := range header.Values("Forwarded") {
+		for _, element := range splitQuoted(value, ',') {
+			for _, pair := range splitQuoted(element, ';') {
+				key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					hops = append(hops, strings.Trim(value, `"`))
+				}
+			}
+		}
+	}
+
+	return hops
+}
+
+func xForwardedFor(header http.Header) []string {
+	var hops []string
+
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	return hops
+}
+
+// splitQuoted splits on sep outside of double quotes.
+func splitQuoted(value string, sep rune) []string {
+	var parts []string
+	var quoted bool
+	start := 0
+
+	for i, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == sep && !quoted:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
+
+// parseHopIP reads an address with or without a port, IPv6 ones possibly in
+// brackets.
+func parseHopIP(hop string) net.IP {
+	hop = strings.TrimSpace(hop)
+
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+
+	return net.ParseIP(strings.Trim(hop, "[]"))
+}
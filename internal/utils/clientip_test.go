@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "2001:db8::/32"}
+
+	tests := []struct {
+		name      string
+		header    string
+		remote    string
+		forwarded []string
+		xff       []string
+		want      string
+	}{
+		{
+			name:   "untrusted peer ignores xff",
+			header: ForwardedHeaderXFF,
+			remote: "203.0.113.7:5000",
+			xff:    []string{"198.51.100.1"},
+			want:   "203.0.113.7",
+		},
+		{
+			name:   "trusted peer takes the last untrusted hop",
+			header: ForwardedHeaderXFF,
+			remote: "10.0.0.1:5000",
+			xff:    []string{"198.51.100.9, 203.0.113.7, 10.0.0.2"},
+			want:   "203.0.113.7",
+		},
+		{
+			name:   "spoofed leftmost xff hop is skipped",
+			header: ForwardedHeaderXFF,
+			remote: "10.0.0.1:5000",
+			xff:    []string{"10.1.1.1", "203.0.113.7"},
+			want:   "203.0.113.7",
+		},
+		{
+			name:      "xff proxies ignore a spoofed forwarded header",
+			header:    ForwardedHeaderXFF,
+			remote:    "10.0.0.1:5000",
+			forwarded: []string{"for=198.51.100.1"},
+			xff:       []string{"203.0.113.7"},
+			want:      "203.0.113.7",
+		},
+		{
+			name:      "xff proxies ignore a forwarded header alone",
+			header:    ForwardedHeaderXFF,
+			remote:    "10.0.0.1:5000",
+			forwarded: []string{"for=198.51.100.1"},
+			want:      "10.0.0.1",
+		},
+		{
+			name:      "forwarded proxies ignore a spoofed xff header",
+			header:    ForwardedHeaderForwarded,
+			remote:    "10.0.0.1:5000",
+			forwarded: []string{"for=203.0.113.7"},
+			xff:       []string{"198.51.100.1"},
+			want:      "203.0.113.7",
+		},
+		{
+			name:      "forwarded with ports, quotes and ipv6",
+			header:    ForwardedHeaderForwarded,
+			remote:    "[2001:db8::1]:443",
+			forwarded: []string{`for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.3`},
+			want:      "2001:db8:cafe::17",
+		},
+		{
+			name:      "obfuscated hop stops the walk",
+			header:    ForwardedHeaderForwarded,
+			remote:    "10.0.0.1:5000",
+			forwarded: []string{"for=203.0.113.7, for=_hidden"},
+			want:      "10.0.0.1",
+		},
+		{
+			name:   "all hops trusted ends at the first one",
+			header: ForwardedHeaderXFF,
+			remote: "10.0.0.1:5000",
+			xff:    []string{"10.0.0.3, 10.0.0.2"},
+			want:   "10.0.0.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewClientIPResolver(trusted, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request := &http.Request{RemoteAddr: tt.remote, Header: http.Header{}}
+			for _, value := range tt.forwarded {
+				request.Header.Add("Forwarded", value)
+			}
+			for _, value := range tt.xff {
+				request.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := resolver.Resolve(request); got != tt.want {
+				t.Errorf("Resolve() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientIPResolver(t *testing.T) {
+	if _, err := NewClientIPResolver(nil, "x-real-ip"); err == nil {
+		t.Error("an unknown forwarded header was accepted")
+	}
+
+	if _, err := NewClientIPResolver([]string{"10.0.0.0/33"}, ForwardedHeaderXFF); err == nil {
+		t.Error("an invalid trusted proxy was accepted")
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -41,22 +42,23 @@ func CheckEnv(value string, envParam string) string {
 	return value
 }
 
+// GetClientIP returns the address the ClientIPResolver of the router found
+// for the request, or the peer address when the request did not pass it.
 func GetClientIP(c *gin.Context) string {
-	forwarded := c.GetHeader("X-Forwarded-For")
-	var ip string
-	if forwarded != "" {
-		ips := strings.Split(forwarded, ",")
-		ip = strings.TrimSpace(ips[0])
-	} else {
-		ip = c.ClientIP()
+	if ip := c.GetString(string(types.ContextClientIPKey)); ip != "" {
+		return ip
+	}
+
+	if ip := parseHopIP(c.Request.RemoteAddr); ip != nil {
+		return NormalizeIP(ip.String())
 	}
-	return NormalizeIP(ip)
+	return NormalizeIP(c.Request.RemoteAddr)
 }
 
 func NormalizeIP(ip string) string {
 
 	// localhost
-	if strings.Contains(ip, "::1") {
+	if ip == "::1" || ip == "[::1]" {
 		return "127.0.0.1"
 	}
 
@@ -193,6 +195,12 @@ func PhoneValidator(phone string) bool {
 func IfErrorExistReturnWithError(c *gin.Context, err common.Error) {
 	if err != nil {
 		errorResponse := types.ErrorResponse{Error: err.ErrorMessage(), Details: err.Error()}
+
+		var fieldErr types.FieldError
+		if errors.As(err, &fieldErr) {
+			errorResponse = types.ErrorResponse{Error: fieldErr.Error(), Details: fieldErr.FieldDetails()}
+		}
+
 		c.JSON(err.StatusCode(), errorResponse)
 		return
 	}
@@ -217,7 +225,7 @@ func IfErrorExistReturnWithErrorExplanation(c *gin.Context, err error, explanati
 	return
 }
 
-func IfErrorExistReturnWithErrorDetails(c *gin.Context, err error, explanation string, details map[string]string, statusCode ...int) {
+func IfErrorExistReturnWithErrorDetails(c *gin.Context, err error, explanation string, details interface{}, statusCode ...int) {
 	statusCodeInternal := http.StatusBadRequest
 	if len(statusCode) > 0 {
 		statusCodeInternal = statusCode[0]
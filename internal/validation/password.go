@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/denizumutdereli/stream-admin/internal/config"
+)
+
+// ValidatePassword checks a password against the strength policy and names
+// every rule it misses.
+func ValidatePassword(password string, rules *config.PasswordRules) error {
+	var upper, lower, digit, symbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			upper = true
+		case unicode.IsLower(char):
+			lower = true
+		case unicode.IsDigit(char):
+			digit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if len([]rune(password)) < rules.MinLength {
+		missing = append(missing, fmt.Sprintf("be at least %d characters long", rules.MinLength))
+	}
+	if rules.RequireUpper && !upper {
+		missing = append(missing, "contain an uppercase letter")
+	}
+	if rules.RequireLower && !lower {
+		missing = append(missing, "contain a lowercase letter")
+	}
+	if rules.RequireDigit && !digit {
+		missing = append(missing, "contain a digit")
+	}
+	if rules.RequireSymbol && !symbol {
+		missing = append(missing, "contain a symbol")
+	}
+
+	if len(missing) > 0 {
+		return errors.New("password must " + strings.Join(missing, ", "))
+	}
+
+	return nil
+}